@@ -0,0 +1,784 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAbiDepth limits how deeply types may nest, a recursive ABI would otherwise never finish
+const maxAbiDepth = 32
+
+// Abi is a contract ABI used to convert action data between JSON and the packed binary format without asking nodeos
+// to do it with abi_json_to_bin. 128 bit integers and float128 are not supported.
+type Abi struct {
+	Version  string       `json:"version"`
+	Types    []AbiType    `json:"types"`
+	Structs  []AbiStruct  `json:"structs"`
+	Actions  []AbiAction  `json:"actions"`
+	Variants []AbiVariant `json:"variants,omitempty"`
+
+	aliases  map[string]string
+	structs  map[string]*AbiStruct
+	actions  map[string]string
+	variants map[string][]string
+}
+
+// AbiType is a type alias
+type AbiType struct {
+	NewTypeName string `json:"new_type_name"`
+	Type        string `json:"type"`
+}
+
+// AbiStruct is a struct, the fields of Base come first
+type AbiStruct struct {
+	Name   string     `json:"name"`
+	Base   string     `json:"base"`
+	Fields []AbiField `json:"fields"`
+}
+
+// AbiField is a field of a struct
+type AbiField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AbiAction maps an action to the struct holding its data
+type AbiAction struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AbiVariant is a type that holds one of Types, in JSON it is a [type, value] pair
+type AbiVariant struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+}
+
+// NewAbi parses a JSON ABI, as found in the abi field of get_abi
+func NewAbi(b []byte) (*Abi, error) {
+	a := &Abi{}
+	if err := json.Unmarshal(b, a); err != nil {
+		return nil, err
+	}
+	a.aliases = make(map[string]string)
+	a.structs = make(map[string]*AbiStruct)
+	a.actions = make(map[string]string)
+	a.variants = make(map[string][]string)
+	for _, t := range a.Types {
+		a.aliases[t.NewTypeName] = t.Type
+	}
+	for i := range a.Structs {
+		a.structs[a.Structs[i].Name] = &a.Structs[i]
+	}
+	for _, action := range a.Actions {
+		a.actions[action.Name] = action.Type
+	}
+	for _, v := range a.Variants {
+		a.variants[v.Name] = v.Types
+	}
+	return a, nil
+}
+
+// GetAbi fetches the ABI of a contract
+func GetAbi(ctx context.Context, api *fio.API, account string) (*Abi, error) {
+	resp := struct {
+		Abi json.RawMessage `json:"abi"`
+	}{}
+	found, err := chainPost(ctx, api, "/v1/chain/get_abi", map[string]string{"account_name": account}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if !found || len(resp.Abi) == 0 || string(resp.Abi) == "null" {
+		return nil, fmt.Errorf("%s has no abi", account)
+	}
+	return NewAbi(resp.Abi)
+}
+
+// PackAction serializes the JSON data of an action, the local equivalent of abi_json_to_bin
+func (a *Abi) PackAction(action string, data []byte) ([]byte, error) {
+	typ, ok := a.actions[action]
+	if !ok {
+		return nil, fmt.Errorf("action %s is not in the abi", action)
+	}
+	return a.Pack(typ, data)
+}
+
+// UnpackAction deserializes the packed data of an action to JSON, the local equivalent of abi_bin_to_json
+func (a *Abi) UnpackAction(action string, packed []byte) ([]byte, error) {
+	typ, ok := a.actions[action]
+	if !ok {
+		return nil, fmt.Errorf("action %s is not in the abi", action)
+	}
+	return a.Unpack(typ, packed)
+}
+
+// Pack serializes JSON as the named type
+func (a *Abi) Pack(typ string, data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := a.pack(buf, typ, v, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unpack deserializes packed as the named type, all of packed must be used
+func (a *Abi) Unpack(typ string, packed []byte) ([]byte, error) {
+	r := bytes.NewReader(packed)
+	out := &bytes.Buffer{}
+	if err := a.unpack(r, out, typ, 0); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d bytes left after unpacking %s", r.Len(), typ)
+	}
+	return out.Bytes(), nil
+}
+
+// resolve follows aliases to the underlying type
+func (a *Abi) resolve(typ string) string {
+	for i := 0; i < maxAbiDepth; i++ {
+		t, ok := a.aliases[typ]
+		if !ok {
+			break
+		}
+		typ = t
+	}
+	return typ
+}
+
+func (a *Abi) pack(buf *bytes.Buffer, typ string, v interface{}, depth int) error {
+	if depth > maxAbiDepth {
+		return errors.New("types are nested too deeply")
+	}
+	typ = a.resolve(typ)
+	switch {
+	case strings.HasSuffix(typ, "$"):
+		if v == nil {
+			return nil
+		}
+		return a.pack(buf, strings.TrimSuffix(typ, "$"), v, depth+1)
+	case strings.HasSuffix(typ, "?"):
+		if v == nil {
+			buf.WriteByte(0)
+			return nil
+		}
+		buf.WriteByte(1)
+		return a.pack(buf, strings.TrimSuffix(typ, "?"), v, depth+1)
+	case strings.HasSuffix(typ, "[]"):
+		values, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array for %s", typ)
+		}
+		writeVarUint(buf, uint64(len(values)))
+		for i := range values {
+			if err := a.pack(buf, strings.TrimSuffix(typ, "[]"), values[i], depth+1); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+	}
+	if types, ok := a.variants[typ]; ok {
+		pair, ok := v.([]interface{})
+		if !ok || len(pair) != 2 {
+			return fmt.Errorf("expected a [type, value] pair for %s", typ)
+		}
+		for i := range types {
+			if types[i] == pair[0] {
+				writeVarUint(buf, uint64(i))
+				return a.pack(buf, types[i], pair[1], depth+1)
+			}
+		}
+		return fmt.Errorf("%v is not a type of %s", pair[0], typ)
+	}
+	if s, ok := a.structs[typ]; ok {
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object for %s", typ)
+		}
+		return a.packStruct(buf, s, fields, depth)
+	}
+	return packBuiltin(buf, typ, v)
+}
+
+func (a *Abi) packStruct(buf *bytes.Buffer, s *AbiStruct, fields map[string]interface{}, depth int) error {
+	if s.Base != "" {
+		base, ok := a.structs[a.resolve(s.Base)]
+		if !ok {
+			return fmt.Errorf("base %s of %s is not a struct", s.Base, s.Name)
+		}
+		if err := a.packStruct(buf, base, fields, depth+1); err != nil {
+			return err
+		}
+	}
+	for _, f := range s.Fields {
+		v, ok := fields[f.Name]
+		if !ok {
+			// binary extensions may be left off the end
+			if strings.HasSuffix(f.Type, "$") {
+				return nil
+			}
+			return fmt.Errorf("%s is missing field %s", s.Name, f.Name)
+		}
+		if err := a.pack(buf, f.Type, v, depth+1); err != nil {
+			return fmt.Errorf("%s.%s: %w", s.Name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Abi) unpack(r *bytes.Reader, out *bytes.Buffer, typ string, depth int) error {
+	if depth > maxAbiDepth {
+		return errors.New("types are nested too deeply")
+	}
+	typ = a.resolve(typ)
+	switch {
+	case strings.HasSuffix(typ, "$"):
+		return a.unpack(r, out, strings.TrimSuffix(typ, "$"), depth+1)
+	case strings.HasSuffix(typ, "?"):
+		present, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if present == 0 {
+			out.WriteString("null")
+			return nil
+		}
+		return a.unpack(r, out, strings.TrimSuffix(typ, "?"), depth+1)
+	case strings.HasSuffix(typ, "[]"):
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		if count > uint64(r.Len()) {
+			return fmt.Errorf("invalid length for %s", typ)
+		}
+		out.WriteByte('[')
+		for i := uint64(0); i < count; i++ {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err = a.unpack(r, out, strings.TrimSuffix(typ, "[]"), depth+1); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		out.WriteByte(']')
+		return nil
+	}
+	if types, ok := a.variants[typ]; ok {
+		i, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		if i >= uint64(len(types)) {
+			return fmt.Errorf("invalid variant index %d for %s", i, typ)
+		}
+		name, _ := json.Marshal(types[i])
+		out.WriteByte('[')
+		out.Write(name)
+		out.WriteByte(',')
+		if err = a.unpack(r, out, types[i], depth+1); err != nil {
+			return err
+		}
+		out.WriteByte(']')
+		return nil
+	}
+	if s, ok := a.structs[typ]; ok {
+		out.WriteByte('{')
+		if _, err := a.unpackStruct(r, out, s, true, depth); err != nil {
+			return err
+		}
+		out.WriteByte('}')
+		return nil
+	}
+	return unpackBuiltin(r, out, typ)
+}
+
+// unpackStruct writes the fields of s without the braces, so the fields of the base are part of the same object
+func (a *Abi) unpackStruct(r *bytes.Reader, out *bytes.Buffer, s *AbiStruct, first bool, depth int) (bool, error) {
+	if s.Base != "" {
+		base, ok := a.structs[a.resolve(s.Base)]
+		if !ok {
+			return first, fmt.Errorf("base %s of %s is not a struct", s.Base, s.Name)
+		}
+		var err error
+		if first, err = a.unpackStruct(r, out, base, first, depth+1); err != nil {
+			return first, err
+		}
+	}
+	for _, f := range s.Fields {
+		if strings.HasSuffix(f.Type, "$") && r.Len() == 0 {
+			break
+		}
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		name, _ := json.Marshal(f.Name)
+		out.Write(name)
+		out.WriteByte(':')
+		if err := a.unpack(r, out, f.Type, depth+1); err != nil {
+			return first, fmt.Errorf("%s.%s: %w", s.Name, f.Name, err)
+		}
+	}
+	return first, nil
+}
+
+// blockTimestampEpoch is the start of block_timestamp_type, which counts half seconds
+const blockTimestampEpoch = 946_684_800_000
+
+func packBuiltin(buf *bytes.Buffer, typ string, v interface{}) error {
+	s, isString := v.(string)
+	switch typ {
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return errors.New("expected a bool")
+		}
+		if b {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case "int8", "int16", "int32", "int64", "varint32":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "var"), "int"))
+		i, err := strconv.ParseInt(abiNumber(v), 10, bits)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case "int8":
+			buf.WriteByte(byte(int8(i)))
+		case "int16":
+			_ = binary.Write(buf, binary.LittleEndian, int16(i))
+		case "int32":
+			_ = binary.Write(buf, binary.LittleEndian, int32(i))
+		case "int64":
+			_ = binary.Write(buf, binary.LittleEndian, i)
+		default:
+			zigzag := int32(i)
+			writeVarUint(buf, uint64(uint32((zigzag<<1)^(zigzag>>31))))
+		}
+	case "uint8", "uint16", "uint32", "uint64", "varuint32":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "var"), "uint"))
+		u, err := strconv.ParseUint(abiNumber(v), 10, bits)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case "uint8":
+			buf.WriteByte(uint8(u))
+		case "uint16":
+			_ = binary.Write(buf, binary.LittleEndian, uint16(u))
+		case "uint32":
+			_ = binary.Write(buf, binary.LittleEndian, uint32(u))
+		case "uint64":
+			_ = binary.Write(buf, binary.LittleEndian, u)
+		default:
+			writeVarUint(buf, u)
+		}
+	case "float32", "float64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "float"))
+		f, err := strconv.ParseFloat(abiNumber(v), bits)
+		if err != nil {
+			return err
+		}
+		if bits == 32 {
+			_ = binary.Write(buf, binary.LittleEndian, math.Float32bits(float32(f)))
+		} else {
+			_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(f))
+		}
+	case "name":
+		if !isString {
+			return errors.New("expected a name")
+		}
+		return writeAbiName(buf, s)
+	case "string":
+		if !isString {
+			return errors.New("expected a string")
+		}
+		writeAbiStrings(buf, s)
+	case "bytes":
+		b, err := hex.DecodeString(s)
+		if err != nil || !isString {
+			return errors.New("expected hex bytes")
+		}
+		writeVarUint(buf, uint64(len(b)))
+		buf.Write(b)
+	case "checksum160", "checksum256", "checksum512":
+		size, _ := strconv.Atoi(strings.TrimPrefix(typ, "checksum"))
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != size/8 {
+			return fmt.Errorf("expected %d hex bytes", size/8)
+		}
+		buf.Write(b)
+	case "public_key":
+		key, err := ecc.NewPublicKey(s)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(byte(key.Curve))
+		buf.Write(key.Content)
+	case "signature":
+		sig, err := ecc.NewSignature(s)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(byte(sig.Curve))
+		buf.Write(sig.Content)
+	case "time_point_sec", "time_point", "block_timestamp_type":
+		t, err := parseExpiration(s)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case "time_point_sec":
+			_ = binary.Write(buf, binary.LittleEndian, uint32(t.Unix()))
+		case "time_point":
+			_ = binary.Write(buf, binary.LittleEndian, t.UnixNano()/1000)
+		default:
+			_ = binary.Write(buf, binary.LittleEndian, uint32((t.UnixNano()/1_000_000-blockTimestampEpoch)/500))
+		}
+	case "symbol_code":
+		code, err := symbolCode(s)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, code)
+	case "symbol":
+		parts := strings.Split(s, ",")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid symbol %q", s)
+		}
+		precision, err := strconv.ParseUint(parts[0], 10, 8)
+		if err != nil {
+			return err
+		}
+		code, err := symbolCode(parts[1])
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, code<<8|precision)
+	case "asset":
+		amount, symbol, err := parseAsset(s)
+		if err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, amount)
+		_ = binary.Write(buf, binary.LittleEndian, symbol)
+	default:
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+	return nil
+}
+
+func unpackBuiltin(r *bytes.Reader, out *bytes.Buffer, typ string) error {
+	read := func(v interface{}) error {
+		return binary.Read(r, binary.LittleEndian, v)
+	}
+	var err error
+	switch typ {
+	case "bool":
+		var b uint8
+		if err = read(&b); err == nil {
+			out.WriteString(strconv.FormatBool(b != 0))
+		}
+	case "int8", "int16", "int32", "int64":
+		var i int64
+		switch typ {
+		case "int8":
+			var v int8
+			err, i = read(&v), int64(v)
+		case "int16":
+			var v int16
+			err, i = read(&v), int64(v)
+		case "int32":
+			var v int32
+			err, i = read(&v), int64(v)
+		default:
+			err = read(&i)
+		}
+		out.WriteString(strconv.FormatInt(i, 10))
+	case "uint8", "uint16", "uint32", "uint64", "varuint32", "varint32":
+		var u uint64
+		switch typ {
+		case "uint8":
+			var v uint8
+			err, u = read(&v), uint64(v)
+		case "uint16":
+			var v uint16
+			err, u = read(&v), uint64(v)
+		case "uint32":
+			var v uint32
+			err, u = read(&v), uint64(v)
+		case "uint64":
+			err = read(&u)
+		default:
+			if u, err = binary.ReadUvarint(r); err == nil && u > math.MaxUint32 {
+				err = errors.New("varint is out of range")
+			}
+		}
+		if typ == "varint32" {
+			out.WriteString(strconv.FormatInt(int64(int32(uint32(u)>>1)^-int32(u&1)), 10))
+		} else {
+			out.WriteString(strconv.FormatUint(u, 10))
+		}
+	case "float32":
+		var bits uint32
+		if err = read(&bits); err == nil {
+			out.WriteString(strconv.FormatFloat(float64(math.Float32frombits(bits)), 'g', -1, 32))
+		}
+	case "float64":
+		var bits uint64
+		if err = read(&bits); err == nil {
+			out.WriteString(strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64))
+		}
+	case "name":
+		var n uint64
+		if err = read(&n); err == nil {
+			writeJSONString(out, eos.NameToString(n))
+		}
+	case "string":
+		var s []string
+		if s, err = readAbiStrings(r, 1); err == nil {
+			writeJSONString(out, s[0])
+		}
+	case "bytes", "checksum160", "checksum256", "checksum512", "public_key", "signature":
+		var b []byte
+		if b, err = readAbiBytes(r, typ); err != nil {
+			break
+		}
+		switch typ {
+		case "public_key":
+			key, err := ecc.NewPublicKeyFromData(b)
+			if err != nil {
+				return err
+			}
+			writeJSONString(out, key.String())
+		case "signature":
+			sig, err := ecc.NewSignatureFromData(b)
+			if err != nil {
+				return err
+			}
+			writeJSONString(out, sig.String())
+		default:
+			writeJSONString(out, hex.EncodeToString(b))
+		}
+	case "time_point_sec", "block_timestamp_type":
+		var v uint32
+		if err = read(&v); err != nil {
+			break
+		}
+		if typ == "time_point_sec" {
+			writeJSONString(out, time.Unix(int64(v), 0).UTC().Format("2006-01-02T15:04:05"))
+		} else {
+			writeJSONString(out, time.Unix(0, (blockTimestampEpoch+int64(v)*500)*1_000_000).UTC().Format("2006-01-02T15:04:05.000"))
+		}
+	case "time_point":
+		var v int64
+		if err = read(&v); err == nil {
+			writeJSONString(out, time.Unix(0, v*1000).UTC().Format("2006-01-02T15:04:05.000"))
+		}
+	case "symbol_code":
+		var v uint64
+		if err = read(&v); err == nil {
+			writeJSONString(out, symbolCodeString(v))
+		}
+	case "symbol":
+		var v uint64
+		if err = read(&v); err == nil {
+			writeJSONString(out, fmt.Sprintf("%d,%s", v&0xff, symbolCodeString(v>>8)))
+		}
+	case "asset":
+		values := make([]uint64, 2)
+		if err = read(values); err == nil {
+			writeJSONString(out, formatAsset(int64(values[0]), values[1]))
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+	return err
+}
+
+// readAbiBytes reads the variable length bytes type or the fixed size types that are written as raw bytes
+func readAbiBytes(r *bytes.Reader, typ string) ([]byte, error) {
+	var size uint64
+	switch typ {
+	case "bytes":
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		size = l
+	case "public_key":
+		size = 34
+	case "signature":
+		size = 66
+	default:
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "checksum"))
+		size = uint64(bits / 8)
+	}
+	if size > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, size)
+	_, _ = r.Read(b)
+	return b, nil
+}
+
+// abiNumber accepts numbers either as JSON numbers or strings, nodeos uses strings for large 64 bit values
+func abiNumber(v interface{}) string {
+	switch n := v.(type) {
+	case json.Number:
+		return n.String()
+	case string:
+		return n
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func writeJSONString(out *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	out.Write(b)
+}
+
+func symbolCode(s string) (uint64, error) {
+	if len(s) == 0 || len(s) > 7 {
+		return 0, fmt.Errorf("invalid symbol code %q", s)
+	}
+	var code uint64
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return 0, fmt.Errorf("invalid symbol code %q", s)
+		}
+		code = code<<8 | uint64(s[i])
+	}
+	return code, nil
+}
+
+func symbolCodeString(code uint64) string {
+	s := make([]byte, 0, 7)
+	for ; code > 0; code >>= 8 {
+		s = append(s, byte(code))
+	}
+	return string(s)
+}
+
+// parseAsset parses an asset such as "1.000000000 FIO", the precision is the number of decimals given
+func parseAsset(s string) (amount int64, symbol uint64, err error) {
+	parts := strings.Split(strings.TrimSpace(s), " ")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid asset %q", s)
+	}
+	precision := 0
+	if dot := strings.Index(parts[0], "."); dot >= 0 {
+		precision = len(parts[0]) - dot - 1
+	}
+	if amount, err = strconv.ParseInt(strings.Replace(parts[0], ".", "", 1), 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid asset %q", s)
+	}
+	code, err := symbolCode(parts[1])
+	if err != nil || precision > 18 {
+		return 0, 0, fmt.Errorf("invalid asset %q", s)
+	}
+	return amount, code<<8 | uint64(precision), nil
+}
+
+func formatAsset(amount int64, symbol uint64) string {
+	precision := int(symbol & 0xff)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(uint64(amount), 10)
+	if amount < 0 {
+		digits = strconv.FormatUint(uint64(-amount), 10)
+	}
+	if precision > 0 {
+		if len(digits) <= precision {
+			digits = strings.Repeat("0", precision-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-precision] + "." + digits[len(digits)-precision:]
+	}
+	return sign + digits + " " + symbolCodeString(symbol>>8)
+}
+
+// DecodedAction is an action with its data decoded by the contract's ABI, Data is empty when there was no ABI for
+// the contract
+type DecodedAction struct {
+	Account       string            `json:"account"`
+	Name          string            `json:"name"`
+	Authorization []PermissionLevel `json:"authorization"`
+	Data          json.RawMessage   `json:"data,omitempty"`
+	HexData       string            `json:"hex_data"`
+}
+
+// DecodedTransaction is a packed transaction decoded by DecodeTransaction
+type DecodedTransaction struct {
+	Expiration         string          `json:"expiration"`
+	RefBlockNum        uint16          `json:"ref_block_num"`
+	RefBlockPrefix     uint32          `json:"ref_block_prefix"`
+	MaxNetUsageWords   uint32          `json:"max_net_usage_words"`
+	MaxCpuUsageMs      uint8           `json:"max_cpu_usage_ms"`
+	DelaySec           uint32          `json:"delay_sec"`
+	ContextFreeActions []DecodedAction `json:"context_free_actions"`
+	Actions            []DecodedAction `json:"actions"`
+}
+
+// DecodeTransaction decodes a hex packed transaction, abis is keyed by contract account. Use GetAbi to fetch them,
+// the ABIs of the system contracts rarely change so they can be kept.
+func DecodeTransaction(packedHex string, abis map[string]*Abi) (*DecodedTransaction, error) {
+	packed, err := hex.DecodeString(packedHex)
+	if err != nil {
+		return nil, err
+	}
+	trx, err := unpackTransaction(packed)
+	if err != nil {
+		return nil, err
+	}
+	decoded := &DecodedTransaction{
+		Expiration:       trx.Expiration,
+		RefBlockNum:      trx.RefBlockNum,
+		RefBlockPrefix:   trx.RefBlockPrefix,
+		MaxNetUsageWords: trx.MaxNetUsageWords,
+		MaxCpuUsageMs:    trx.MaxCpuUsageMs,
+		DelaySec:         trx.DelaySec,
+	}
+	for _, actions := range []struct {
+		from []jsonAction
+		to   *[]DecodedAction
+	}{{trx.ContextFreeActions, &decoded.ContextFreeActions}, {trx.Actions, &decoded.Actions}} {
+		*actions.to = make([]DecodedAction, len(actions.from))
+		for i, a := range actions.from {
+			d := DecodedAction{Account: a.Account, Name: a.Name, Authorization: a.Authorization, HexData: a.HexData}
+			if abi := abis[a.Account]; abi != nil {
+				data, _ := hex.DecodeString(a.HexData)
+				if d.Data, err = abi.UnpackAction(a.Name, data); err != nil {
+					return nil, fmt.Errorf("%s::%s: %w", a.Account, a.Name, err)
+				}
+			}
+			(*actions.to)[i] = d
+		}
+	}
+	return decoded, nil
+}
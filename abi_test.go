@@ -0,0 +1,134 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+const testTokenAbi = `{
+	"version": "eosio::abi/1.1",
+	"types": [{"new_type_name": "fio_address", "type": "string"}],
+	"structs": [
+		{"name": "trnsfiopubky", "base": "", "fields": [
+			{"name": "payee_public_key", "type": "string"},
+			{"name": "amount", "type": "int64"},
+			{"name": "max_fee", "type": "int64"},
+			{"name": "actor", "type": "name"},
+			{"name": "tpid", "type": "fio_address"}
+		]},
+		{"name": "header", "base": "", "fields": [
+			{"name": "when", "type": "time_point_sec"},
+			{"name": "flag", "type": "bool"}
+		]},
+		{"name": "kitchen", "base": "header", "fields": [
+			{"name": "key", "type": "public_key"},
+			{"name": "hash", "type": "checksum256"},
+			{"name": "quantity", "type": "asset"},
+			{"name": "symbol", "type": "symbol"},
+			{"name": "memo", "type": "string?"},
+			{"name": "counts", "type": "varuint32[]"},
+			{"name": "delta", "type": "varint32"},
+			{"name": "ratio", "type": "float64"},
+			{"name": "blob", "type": "bytes"},
+			{"name": "choice", "type": "choice"},
+			{"name": "stamp", "type": "time_point"},
+			{"name": "extra", "type": "uint16$"}
+		]}
+	],
+	"actions": [{"name": "trnsfiopubky", "type": "trnsfiopubky"}, {"name": "kitchen", "type": "kitchen"}],
+	"variants": [{"name": "choice", "types": ["uint8", "string"]}]
+}`
+
+func TestAbi(t *testing.T) {
+	abi, err := NewAbi([]byte(testTokenAbi))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// the same encoding as the hand written transfer used by the policy tests
+	transfer := transferTx("FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy", 1_000_000_000)
+	expect, _ := hex.DecodeString(transfer["actions"].([]map[string]interface{})[0]["hex_data"].(string))
+	packed, err := abi.PackAction("trnsfiopubky", []byte(`{"payee_public_key":"FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy",
+		"amount":1000000000,"max_fee":"2000000000","actor":"aftyershcu22","tpid":""}`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(packed, expect) {
+		t.Errorf("unexpected packed transfer %x", packed)
+	}
+	unpacked, err := abi.UnpackAction("trnsfiopubky", packed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(unpacked) != `{"payee_public_key":"FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy","amount":1000000000,"max_fee":2000000000,"actor":"aftyershcu22","tpid":""}` {
+		t.Error("unexpected json", string(unpacked))
+	}
+
+	kitchen := `{"when":"2020-07-01T00:00:00","flag":true,"key":"FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy",` +
+		`"hash":"` + hex.EncodeToString(make([]byte, 32)) + `","quantity":"-12.345000000 FIO","symbol":"9,FIO","memo":null,` +
+		`"counts":[1,300,70000],"delta":-5,"ratio":0.25,"blob":"beef","choice":["string","hi"],"stamp":"2020-07-01T00:00:00.500"}`
+	packed, err = abi.PackAction("kitchen", []byte(kitchen))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if unpacked, err = abi.UnpackAction("kitchen", packed); err != nil || string(unpacked) != kitchen {
+		t.Errorf("round trip failed %v\n%s\n%s", err, kitchen, unpacked)
+	}
+	withExtra := kitchen[:len(kitchen)-1] + `,"extra":7}`
+	if packed, err = abi.PackAction("kitchen", []byte(withExtra)); err != nil {
+		t.Error(err)
+		return
+	}
+	if unpacked, err = abi.UnpackAction("kitchen", packed); err != nil || string(unpacked) != withExtra {
+		t.Errorf("binary extension round trip failed %v\n%s", err, unpacked)
+	}
+
+	if _, err = abi.UnpackAction("kitchen", packed[:len(packed)-3]); err == nil {
+		t.Error("expected truncated data to fail")
+	}
+	if _, err = abi.PackAction("trnsfiopubky", []byte(`{"amount":1}`)); err == nil {
+		t.Error("expected missing fields to fail")
+	}
+	if _, err = abi.PackAction("nope", []byte(`{}`)); err == nil {
+		t.Error("expected an unknown action to fail")
+	}
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	abi, err := NewAbi([]byte(testTokenAbi))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	b, _ := json.Marshal(transferTx("FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy", 1_000_000_000))
+	packed, err := PackTransactionJSON(b)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	decoded, err := DecodeTransaction(hex.EncodeToString(packed), map[string]*Abi{"fio.token": abi})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if decoded.Expiration != "2020-07-01T00:00:00" || len(decoded.Actions) != 1 || decoded.Actions[0].Authorization[0].String() != "aftyershcu22@active" {
+		t.Error("unexpected transaction", decoded)
+	}
+	transfer := struct {
+		Amount int64  `json:"amount"`
+		Actor  string `json:"actor"`
+	}{}
+	if err = json.Unmarshal(decoded.Actions[0].Data, &transfer); err != nil || transfer.Amount != 1_000_000_000 || transfer.Actor != "aftyershcu22" {
+		t.Error("unexpected data", string(decoded.Actions[0].Data), err)
+	}
+
+	// without an abi the data stays hex
+	if decoded, err = DecodeTransaction(hex.EncodeToString(packed), nil); err != nil || decoded.Actions[0].Data != nil || decoded.Actions[0].HexData == "" {
+		t.Error("expected undecoded data", decoded, err)
+	}
+}
@@ -0,0 +1,66 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// AccountDetails is the part of get_account used to show how a wallet key is used by its account
+type AccountDetails struct {
+	AccountName string              `json:"account_name"`
+	RamQuota    int64               `json:"ram_quota"`
+	RamUsage    int64               `json:"ram_usage"`
+	NetLimit    AccountResource     `json:"net_limit"`
+	CpuLimit    AccountResource     `json:"cpu_limit"`
+	Permissions []AccountPermission `json:"permissions"`
+}
+
+// AccountResource is the usage of a staked resource
+type AccountResource struct {
+	Used      int64 `json:"used"`
+	Available int64 `json:"available"`
+	Max       int64 `json:"max"`
+}
+
+// AccountPermission is a permission on an account, LinkedActions is only provided by newer nodeos versions
+type AccountPermission struct {
+	PermName      string    `json:"perm_name"`
+	Parent        string    `json:"parent"`
+	RequiredAuth  Authority `json:"required_auth"`
+	LinkedActions []struct {
+		Account string `json:"account"`
+		Action  string `json:"action"`
+	} `json:"linked_actions,omitempty"`
+}
+
+// PermissionsForKey lists the permissions that include pub, a key that can satisfy the threshold alone is the usual
+// case for FIO accounts
+func (a AccountDetails) PermissionsForKey(pub string) []string {
+	target, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return nil
+	}
+	perms := make([]string, 0)
+	for _, p := range a.Permissions {
+		for _, k := range p.RequiredAuth.Keys {
+			key, err := ecc.NewPublicKey(k.Key)
+			if err == nil && bytes.Equal(key.Content, target.Content) {
+				perms = append(perms, p.PermName)
+				break
+			}
+		}
+	}
+	return perms
+}
+
+// GetAccountDetails queries get_account for an actor, nil is returned if the account does not exist
+func GetAccountDetails(ctx context.Context, api *fio.API, actor string) (*AccountDetails, error) {
+	details := &AccountDetails{}
+	found, err := chainPost(ctx, api, "/v1/chain/get_account", map[string]string{"account_name": actor}, details)
+	if err != nil || !found {
+		return nil, err
+	}
+	return details, nil
+}
@@ -0,0 +1,127 @@
+package fiox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SUFsPerFio is the number of SUFs, the smallest unit, in one FIO
+const SUFsPerFio = 1_000_000_000
+
+// ErrAmountOverflow is returned when arithmetic on a FioAmount does not fit in 64 bits
+var ErrAmountOverflow = errors.New("amount overflows")
+
+// FioAmount is an exact amount of FIO held as SUFs, so FioAmount(1) is 0.000000001 FIO. It marshals to JSON as a
+// string such as "12.345678901 FIO" and unmarshals from either that or a number of SUFs, which is how nodeos
+// provides amounts.
+type FioAmount int64
+
+// ParseFioAmount parses a decimal amount such as "12.345678901 FIO", the FIO suffix is optional and at most nine
+// decimals are allowed
+func ParseFioAmount(s string) (FioAmount, error) {
+	value := strings.TrimSpace(s)
+	if fields := strings.Fields(value); len(fields) == 2 && strings.EqualFold(fields[1], "FIO") {
+		value = fields[0]
+	}
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	whole, fraction := value, ""
+	if dot := strings.Index(value, "."); dot >= 0 {
+		whole, fraction = value[:dot], value[dot+1:]
+	}
+	if whole == "" && fraction == "" || len(fraction) > 9 || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	fraction += strings.Repeat("0", 9-len(fraction))
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	f, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if w > (math.MaxInt64-f)/SUFsPerFio {
+		return 0, fmt.Errorf("%w: %q", ErrAmountOverflow, s)
+	}
+	amount := FioAmount(w*SUFsPerFio + f)
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// SUFs is the amount in SUFs
+func (a FioAmount) SUFs() int64 {
+	return int64(a)
+}
+
+// String formats the amount with all nine decimals and the FIO symbol
+func (a FioAmount) String() string {
+	sign, sufs := "", uint64(a)
+	if a < 0 {
+		sign, sufs = "-", uint64(-a)
+	}
+	return fmt.Sprintf("%s%d.%09d FIO", sign, sufs/SUFsPerFio, sufs%SUFsPerFio)
+}
+
+// Add adds b, ErrAmountOverflow is returned if the sum does not fit
+func (a FioAmount) Add(b FioAmount) (FioAmount, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, ErrAmountOverflow
+	}
+	return sum, nil
+}
+
+// Sub subtracts b, ErrAmountOverflow is returned if the difference does not fit
+func (a FioAmount) Sub(b FioAmount) (FioAmount, error) {
+	difference := a - b
+	if (b > 0 && difference > a) || (b < 0 && difference < a) {
+		return 0, ErrAmountOverflow
+	}
+	return difference, nil
+}
+
+// Mul multiplies by n, ErrAmountOverflow is returned if the product does not fit
+func (a FioAmount) Mul(n int64) (FioAmount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	product := a * FioAmount(n)
+	if product/FioAmount(n) != a || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return 0, ErrAmountOverflow
+	}
+	return product, nil
+}
+
+// MarshalJSON uses the same format as String
+func (a FioAmount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts a string in any format ParseFioAmount does, or a number of SUFs
+func (a *FioAmount) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		amount, err := ParseFioAmount(s)
+		if err != nil {
+			return err
+		}
+		*a = amount
+		return nil
+	}
+	sufs, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s, numbers must be a whole number of SUFs", b)
+	}
+	*a = FioAmount(sufs)
+	return nil
+}
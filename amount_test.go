@@ -0,0 +1,92 @@
+package fiox
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseFioAmount(t *testing.T) {
+	for _, test := range []struct {
+		s      string
+		expect FioAmount
+	}{
+		{"12.345678901 FIO", 12_345_678_901},
+		{"12.345678901", 12_345_678_901},
+		{"1 fio", 1_000_000_000},
+		{"0.000000001", 1},
+		{".5", 500_000_000},
+		{"3.", 3_000_000_000},
+		{"-1.25 FIO", -1_250_000_000},
+		{" 40 ", 40_000_000_000},
+	} {
+		amount, err := ParseFioAmount(test.s)
+		if err != nil || amount != test.expect {
+			t.Error("unexpected amount", test.s, amount, err)
+		}
+	}
+	for _, s := range []string{"", ".", "1.0000000001", "1 BTC", "+1", "1.-5", "1e9", "abc FIO"} {
+		if _, err := ParseFioAmount(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+	if _, err := ParseFioAmount("9223372037 FIO"); !errors.Is(err, ErrAmountOverflow) {
+		t.Error("expected an overflow", err)
+	}
+}
+
+func TestFioAmount(t *testing.T) {
+	for _, test := range []struct {
+		amount FioAmount
+		expect string
+	}{
+		{12_345_678_901, "12.345678901 FIO"},
+		{1, "0.000000001 FIO"},
+		{0, "0.000000000 FIO"},
+		{-1_500_000_000, "-1.500000000 FIO"},
+		{math.MinInt64, "-9223372036.854775808 FIO"},
+	} {
+		if s := test.amount.String(); s != test.expect {
+			t.Error("unexpected string", s, test.expect)
+		}
+	}
+
+	a := FioAmount(SUFsPerFio)
+	if sum, err := a.Add(2); err != nil || sum != 1_000_000_002 {
+		t.Error("unexpected sum", sum, err)
+	}
+	if difference, err := a.Sub(3 * SUFsPerFio); err != nil || difference != -2*SUFsPerFio {
+		t.Error("unexpected difference", difference, err)
+	}
+	if product, err := a.Mul(3); err != nil || product != 3*SUFsPerFio || product.SUFs() != 3_000_000_000 {
+		t.Error("unexpected product", product, err)
+	}
+	if _, err := FioAmount(math.MaxInt64).Add(1); !errors.Is(err, ErrAmountOverflow) {
+		t.Error("expected the sum to overflow")
+	}
+	if _, err := FioAmount(math.MinInt64).Sub(1); !errors.Is(err, ErrAmountOverflow) {
+		t.Error("expected the difference to overflow")
+	}
+	if _, err := a.Mul(math.MaxInt64 / 2); !errors.Is(err, ErrAmountOverflow) {
+		t.Error("expected the product to overflow")
+	}
+
+	b, err := json.Marshal(struct {
+		Amount FioAmount `json:"amount"`
+	}{12_345_678_901})
+	if err != nil || string(b) != `{"amount":"12.345678901 FIO"}` {
+		t.Error("unexpected json", string(b), err)
+	}
+	amounts := make([]FioAmount, 0)
+	if err = json.Unmarshal([]byte(`["12.345678901 FIO", "2", 40000000000]`), &amounts); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(amounts) != 3 || amounts[0] != 12_345_678_901 || amounts[1] != 2*SUFsPerFio || amounts[2] != 40*SUFsPerFio {
+		t.Error("unexpected amounts", amounts)
+	}
+	if err = json.Unmarshal([]byte(`[1.5]`), &amounts); err == nil {
+		t.Error("expected a fractional number of SUFs to be rejected")
+	}
+}
@@ -0,0 +1,205 @@
+package fiox
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrAuditTampered is returned when an audit log entry does not match its hash or the entry before it
+var ErrAuditTampered = errors.New("audit log has been modified")
+
+// AuditEntry is one signature in an AuditLog. Hash covers every other field, including Prev which is the hash of
+// the entry before, so changing or removing an entry breaks the chain from that point on.
+type AuditEntry struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	PublicKey string    `json:"public_key"`
+	Digest    string    `json:"digest"`
+	Signature string    `json:"signature"`
+	Actions   []string  `json:"actions,omitempty"`
+	Prev      string    `json:"prev"`
+	Hash      string    `json:"hash"`
+}
+
+// AuditLog is an append-only log of signatures, one JSON entry per line
+type AuditLog struct {
+	mux  sync.Mutex
+	w    io.Writer
+	file *os.File
+	seq  uint64
+	last string
+}
+
+// NewAuditLog starts a new audit log written to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// OpenAuditLog appends to the audit log at path, creating it if needed. An existing log is verified first so new
+// entries continue its chain.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	last, err := verifyAuditLog(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &AuditLog{w: f, file: f, seq: last.Seq, last: last.Hash}, nil
+}
+
+// Append adds an entry, filling in Seq, Time when it is zero, Prev, and Hash. The file is synced before returning.
+func (l *AuditLog) Append(e AuditEntry) (AuditEntry, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.Seq, e.Prev = l.seq+1, l.last
+	e.Hash = e.hash()
+	b, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+	if _, err = l.w.Write(append(b, '\n')); err != nil {
+		return e, err
+	}
+	if l.file != nil {
+		if err = l.file.Sync(); err != nil {
+			return e, err
+		}
+	}
+	l.seq, l.last = e.Seq, e.Hash
+	return e, nil
+}
+
+// Close closes the file opened by OpenAuditLog
+func (l *AuditLog) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// VerifyAuditLog checks every entry of a log against its hash and the entry before it, providing the number of
+// entries. Errors wrap ErrAuditTampered and name the first bad entry.
+func VerifyAuditLog(r io.Reader) (int, error) {
+	last, err := verifyAuditLog(r)
+	return int(last.Seq), err
+}
+
+func verifyAuditLog(r io.Reader) (last AuditEntry, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		e := AuditEntry{}
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return last, fmt.Errorf("%w: line %d is not an entry", ErrAuditTampered, line)
+		}
+		if e.Seq != last.Seq+1 || e.Prev != last.Hash || e.Hash != e.hash() {
+			return last, fmt.Errorf("%w: entry %d on line %d does not verify", ErrAuditTampered, e.Seq, line)
+		}
+		last = e
+	}
+	return last, scanner.Err()
+}
+
+func (e AuditEntry) hash() string {
+	e.Hash = ""
+	b, _ := json.Marshal(e)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// AuditSigner wraps a Signer, recording each signature it produces in an AuditLog. A signature that cannot be
+// recorded is not returned.
+type AuditSigner struct {
+	signer Signer
+	log    *AuditLog
+}
+
+// NewAuditSigner records the signatures made by signer in log
+func NewAuditSigner(signer Signer, log *AuditLog) (*AuditSigner, error) {
+	if signer == nil || log == nil {
+		return nil, errors.New("signer and log are required")
+	}
+	return &AuditSigner{signer: signer, log: log}, nil
+}
+
+// PublicKeys lists the public keys of the wrapped signer
+func (as *AuditSigner) PublicKeys(ctx context.Context) ([]string, error) {
+	return as.signer.PublicKeys(ctx)
+}
+
+// SignDigest signs a digest and records it
+func (as *AuditSigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	sig, err := as.signer.SignDigest(ctx, digest, pub)
+	if err != nil {
+		return sig, err
+	}
+	e := AuditEntry{PublicKey: pub, Digest: hex.EncodeToString(digest), Signature: sig.String()}
+	if _, err = as.log.Append(e); err != nil {
+		return ecc.Signature{}, fmt.Errorf("could not record the signature: %w", err)
+	}
+	return sig, nil
+}
+
+// SignTransaction signs a transaction and records each signature with a summary of the actions
+func (as *AuditSigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	sigs, err := as.signer.SignTransaction(ctx, tx, pubkeys, chainID)
+	if err != nil {
+		return nil, err
+	}
+	digest, summary := auditSummary(tx, chainID)
+	d, _ := hex.DecodeString(digest)
+	for _, sig := range sigs {
+		// the signer may skip keys or return them in any order, so record the key that actually signed
+		e := AuditEntry{Digest: digest, Signature: sig.String(), Actions: summary}
+		if len(d) == 32 {
+			if pub, err := sig.PublicKey(d); err == nil {
+				e.PublicKey = pub.String()
+			}
+		}
+		if _, err = as.log.Append(e); err != nil {
+			return nil, fmt.Errorf("could not record the signature: %w", err)
+		}
+	}
+	return sigs, nil
+}
+
+// auditSummary provides the digest and a line for each action of a transaction, as much as can be decoded
+func auditSummary(tx interface{}, chainID string) (digest string, summary []string) {
+	actions, err := policyActions(tx)
+	if err != nil {
+		return "", []string{"could not decode the transaction: " + err.Error()}
+	}
+	for _, a := range actions {
+		line := fmt.Sprintf("%s::%s by %v", a.Contract, a.Action, a.Actors)
+		if a.Payee != "" {
+			line += fmt.Sprintf(" to %s spending %d", a.Payee, a.Amount)
+		}
+		summary = append(summary, line)
+	}
+	id, err := hex.DecodeString(chainID)
+	if err != nil {
+		return "", summary
+	}
+	trx, _ := json.Marshal(tx)
+	if packed, err := PackTransactionJSON(trx); err == nil {
+		digest = hex.EncodeToString(TransactionDigest(id, packed, nil))
+	}
+	return digest, summary
+}
@@ -0,0 +1,78 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	bag := eos.NewKeyBag()
+	if err = bag.Add("5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"); err != nil {
+		t.Error(err)
+		return
+	}
+	signer, _ := NewKeyBagSigner(bag)
+	ctx := context.Background()
+	pubs, _ := signer.PublicKeys(ctx)
+	payee := "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"
+
+	for i := 0; i < 2; i++ {
+		// reopening continues the chain
+		log, err := OpenAuditLog(path)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		as, _ := NewAuditSigner(signer, log)
+		if _, err = as.SignTransaction(ctx, transferTx(payee, 1_000_000_000), pubs, FioMainnetChainID); err != nil {
+			t.Error(err)
+		}
+		if _, err = as.SignDigest(ctx, make([]byte, 32), pubs[0]); err != nil {
+			t.Error(err)
+		}
+		_ = log.Close()
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if n, err := VerifyAuditLog(bytes.NewReader(b)); err != nil || n != 4 {
+		t.Error("expected four entries that verify", n, err)
+	}
+	if !strings.Contains(string(b), "fio.token::trnsfiopubky by [aftyershcu22] to "+payee+" spending 3000000000") {
+		t.Error("missing action summary", string(b))
+	}
+	// the transaction signature is attributed to the key recovered from it
+	if first := strings.SplitN(string(b), "\n", 2)[0]; !strings.Contains(first, `"public_key":"`+pubs[0]+`"`) {
+		t.Error("expected the signing key to be recorded", string(b))
+	}
+
+	// change the second entry
+	lines := strings.Split(string(b), "\n")
+	lines[1] = strings.Replace(lines[1], pubs[0], payee, 1)
+	if _, err = VerifyAuditLog(strings.NewReader(strings.Join(lines, "\n"))); !errors.Is(err, ErrAuditTampered) {
+		t.Error("expected a modified entry to be found", err)
+	}
+	// or remove it
+	lines = strings.Split(string(b), "\n")
+	if _, err = VerifyAuditLog(strings.NewReader(strings.Join(append(lines[:1], lines[2:]...), "\n"))); !errors.Is(err, ErrAuditTampered) {
+		t.Error("expected a removed entry to be found", err)
+	}
+}
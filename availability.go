@@ -0,0 +1,87 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"strings"
+)
+
+// MaxSuggestionChecks limits how many avail_check requests SuggestAddresses makes
+var MaxSuggestionChecks = 25
+
+// AvailCheck reports whether a FIO address or domain can be registered. Names that fail validation return
+// ErrInvalidFioName without querying nodeos.
+func AvailCheck(ctx context.Context, api *fio.API, fioName string) (bool, error) {
+	var err error
+	if strings.Contains(fioName, "@") {
+		_, _, err = SplitAddress(fioName)
+	} else {
+		err = validFioLabel(fioName, MaxFioDomainLength)
+	}
+	if err != nil {
+		return false, err
+	}
+	resp := struct {
+		IsRegistered int `json:"is_registered"`
+	}{}
+	found, err := chainPost(ctx, api, "/v1/chain/avail_check", map[string]string{"fio_name": fioName}, &resp)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, fmt.Errorf("avail_check did not answer for %s", fioName)
+	}
+	return resp.IsRegistered == 0, nil
+}
+
+// SuggestAddresses offers up to max available FIO addresses similar to fioAddress, for registration forms to show
+// when it is taken. The same name on each of the public domains is tried first, then variants on the requested
+// domain: "alice-pay" style names, since FIO names cannot hold dots, and then the name with a number.
+func SuggestAddresses(ctx context.Context, api *fio.API, fioAddress string, publicDomains []string, max int) ([]string, error) {
+	if max < 1 {
+		return nil, errors.New("max must be at least one")
+	}
+	name, domain, err := SplitAddress(fioAddress)
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]string, 0, max)
+	for i, candidate := range addressCandidates(name, domain, publicDomains) {
+		if len(suggestions) == max || i == MaxSuggestionChecks {
+			break
+		}
+		available, err := AvailCheck(ctx, api, candidate)
+		if err != nil {
+			return suggestions, err
+		}
+		if available {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions, nil
+}
+
+// addressCandidates lists the valid alternatives to name@domain in the order they are offered
+func addressCandidates(name string, domain string, publicDomains []string) []string {
+	candidates := make([]string, 0)
+	seen := map[string]bool{name + "@" + domain: true}
+	add := func(c string) {
+		c = strings.ToLower(c)
+		if !seen[c] && IsValidFioAddress(c) {
+			seen[c] = true
+			candidates = append(candidates, c)
+		}
+	}
+	for _, d := range publicDomains {
+		add(name + "@" + d)
+	}
+	for _, suffix := range []string{"-pay", "-fio", "-wallet"} {
+		add(name + suffix + "@" + domain)
+	}
+	for i := 1; i < 10; i++ {
+		add(fmt.Sprintf("%s%d@%s", name, i, domain))
+	}
+	return candidates
+}
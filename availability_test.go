@@ -0,0 +1,57 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSuggestAddresses(t *testing.T) {
+	taken := map[string]bool{"alice@fiotestnet": true, "alice@edge": true, "alice-pay@fiotestnet": true}
+	checks := 0
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		checks++
+		registered := 0
+		if taken[req["fio_name"]] {
+			registered = 1
+		}
+		_ = json.NewEncoder(w).Encode(map[string]int{"is_registered": registered})
+	}))
+	defer nodeos.Close()
+	api := &fio.API{API: eos.API{BaseURL: nodeos.URL}}
+	ctx := context.Background()
+
+	available, err := AvailCheck(ctx, api, "alice@fiotestnet")
+	if err != nil || available {
+		t.Error("expected alice@fiotestnet to be taken", err)
+	}
+	if available, err = AvailCheck(ctx, api, "alice"); err != nil || !available {
+		t.Error("expected the domain alice to be available", err)
+	}
+	checks = 0
+	if _, err = AvailCheck(ctx, api, "al--ice@fiotestnet"); !errors.Is(err, ErrInvalidFioName) || checks != 0 {
+		t.Error("expected an invalid name to be rejected without a query", err)
+	}
+
+	suggestions, err := SuggestAddresses(ctx, api, "Alice@fiotestnet", []string{"edge", "fiomembers", "bad_domain"}, 3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expect := []string{"alice@fiomembers", "alice-fio@fiotestnet", "alice-wallet@fiotestnet"}
+	if len(suggestions) != len(expect) {
+		t.Fatal("unexpected suggestions", suggestions)
+	}
+	for i := range expect {
+		if suggestions[i] != expect[i] {
+			t.Error("unexpected suggestions", suggestions)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+)
+
+// DefaultBalanceWorkers is how many balances BalanceReport queries at once when the Hd has no WithWorkers setting
+const DefaultBalanceWorkers = 8
+
+// FioBalance is the result of get_fio_balance in SUFs. Available is the balance that is neither staked nor locked,
+// SRPs are the staking reward points held for the staked tokens, and ROE is their rate of exchange to SUFs.
+type FioBalance struct {
+	Balance   uint64 `json:"balance"`
+	Available uint64 `json:"available"`
+	Staked    uint64 `json:"staked"`
+	SRPs      uint64 `json:"srps"`
+	ROE       string `json:"roe,omitempty"`
+}
+
+// KeyBalance is the balance of one derived key
+type KeyBalance struct {
+	Index     int             `json:"index"`
+	PublicKey string          `json:"public_key"`
+	Actor     eos.AccountName `json:"actor"`
+	FioBalance
+}
+
+// BalanceReport is the balance of a range of derived keys, the totals are the sums over Keys
+type BalanceReport struct {
+	Keys []KeyBalance `json:"keys"`
+	FioBalance
+}
+
+// GetFioBalance queries get_fio_balance, a key that has never been used is not found and has a zero balance
+func GetFioBalance(ctx context.Context, api *fio.API, pub string) (*FioBalance, error) {
+	balance := &FioBalance{}
+	if _, err := chainPost(ctx, api, "/v1/chain/get_fio_balance", map[string]string{"fio_public_key": pub}, balance); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// BalanceReport queries the balances of count keys starting at index start, concurrently, which shows how much
+// value the mnemonic controls. It works for watch-only Hds.
+func (hd Hd) BalanceReport(ctx context.Context, api *fio.API, start int, count int) (*BalanceReport, error) {
+	if api == nil {
+		return nil, errors.New("api cannot be nil")
+	}
+	pubs, err := hd.PubKeysRange(start, count)
+	if err != nil {
+		return nil, err
+	}
+	report := &BalanceReport{Keys: make([]KeyBalance, count)}
+	if hd.workers < 2 {
+		hd.workers = DefaultBalanceWorkers
+	}
+	err = forRange(hd.workers, count, func(i int) error {
+		kb := &report.Keys[i]
+		kb.Index, kb.PublicKey = start+i, pubs[i].String()
+		actor, err := fio.ActorFromPub(kb.PublicKey)
+		if err != nil {
+			return err
+		}
+		kb.Actor = actor
+		balance, err := GetFioBalance(ctx, api, kb.PublicKey)
+		if err != nil {
+			return err
+		}
+		kb.FioBalance = *balance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, kb := range report.Keys {
+		report.Balance += kb.Balance
+		report.Available += kb.Available
+		report.Staked += kb.Staked
+		report.SRPs += kb.SRPs
+	}
+	return report, nil
+}
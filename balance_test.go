@@ -0,0 +1,50 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHd_BalanceReport(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := hd.PubKeysRange(0, 5)
+	balances := map[string]FioBalance{
+		pubs[1].String(): {Balance: 5_000_000_000, Available: 2_000_000_000, Staked: 3_000_000_000, SRPs: 3_000_000_000, ROE: "1.000000000000000"},
+		pubs[3].String(): {Balance: 1_000_000_000, Available: 1_000_000_000},
+	}
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		balance, ok := balances[req["fio_public_key"]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(balance)
+	}))
+	defer nodeos.Close()
+
+	report, err := hd.BalanceReport(context.Background(), &fio.API{API: eos.API{BaseURL: nodeos.URL}}, 0, 5)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(report.Keys) != 5 || report.Keys[1].Index != 1 || report.Keys[1].PublicKey != pubs[1].String() || report.Keys[1].ROE == "" {
+		t.Error("unexpected keys", report.Keys)
+	}
+	if report.Keys[0].Balance != 0 || report.Keys[3].Available != 1_000_000_000 {
+		t.Error("unexpected balances", report.Keys)
+	}
+	if report.Balance != 6_000_000_000 || report.Available != 3_000_000_000 || report.Staked != 3_000_000_000 || report.SRPs != 3_000_000_000 {
+		t.Error("unexpected totals", report.FioBalance)
+	}
+}
@@ -0,0 +1,80 @@
+package fiox
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+)
+
+// bip85Languages maps wordlists to the language codes used in BIP85 derivation paths
+var bip85Languages = map[Language]int{
+	LangEnglish:            0,
+	LangJapanese:           1,
+	LangKorean:             2,
+	LangSpanish:            3,
+	LangChineseSimplified:  4,
+	LangChineseTraditional: 5,
+	LangFrench:             6,
+	LangItalian:            7,
+	LangCzech:              8,
+}
+
+// ChildMnemonic uses BIP85 to deterministically derive an independent mnemonic with 12, 18, or 24 words. The
+// child uses the same language as the Hd, or English if the Hd was not created from a mnemonic. The same index
+// always results in the same mnemonic, but a child can't be used to recover the parent.
+func (hd Hd) ChildMnemonic(index int, words int) (string, error) {
+	if err := hd.canDerive(); err != nil {
+		return "", err
+	}
+	if err := checkIndex(index); err != nil {
+		return "", err
+	}
+	var length int
+	switch words {
+	case 12:
+		length = 16
+	case 18:
+		length = 24
+	case 24:
+		length = 32
+	default:
+		return "", errors.New("BIP85 word count must be 12, 18, or 24")
+	}
+	language := hd.language
+	if language == "" {
+		language = LangEnglish
+	}
+	code, ok := bip85Languages[language]
+	if !ok {
+		return "", fmt.Errorf("BIP85 does not define a code for language %q", language)
+	}
+	entropy, err := hd.bip85Entropy(fmt.Sprintf("m/83696968'/39'/%d'/%d'/%d'", code, words, index))
+	if err != nil {
+		return "", err
+	}
+	wl, err := getWordList(language)
+	if err != nil {
+		return "", err
+	}
+	phrase, err := entropyToMnemonic(entropy[:length], wl)
+	if err != nil {
+		return "", err
+	}
+	return joinWords(phrase, language), nil
+}
+
+// bip85Entropy derives the 64 bytes of entropy for a BIP85 application path
+func (hd Hd) bip85Entropy(path string) ([]byte, error) {
+	key, err := deriveExtended(hd.master, path)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, []byte("bip-entropy-from-k"))
+	_, _ = mac.Write(priv.Serialize())
+	return mac.Sum(nil), nil
+}
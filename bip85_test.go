@@ -0,0 +1,55 @@
+package fiox
+
+import "testing"
+
+func TestHd_ChildMnemonic(t *testing.T) {
+	hd, err := NewHdFromString("struggle dream fetch aunt marriage adult merry machine vessel help slogan bright balcony extend stomach sun father essay surface call song bitter economy approve")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	seen := make(map[string]bool)
+	for _, words := range []int{12, 18, 24} {
+		for i := 0; i < 3; i++ {
+			child, err := hd.ChildMnemonic(i, words)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			if seen[child] {
+				t.Error("child mnemonic was repeated")
+			}
+			seen[child] = true
+			again, _ := hd.ChildMnemonic(i, words)
+			if again != child {
+				t.Error("child mnemonic is not deterministic")
+			}
+			c, err := NewHdFromString(child)
+			if err != nil {
+				t.Error("child mnemonic was not valid", err)
+				continue
+			}
+			if c.Len() != words {
+				t.Error("child had wrong word count, expected", words, "got", c.Len())
+			}
+		}
+	}
+	// the master key must give the same result as the mnemonic
+	xpriv, _ := hd.Xpriv()
+	restored, err := NewHdFromXpriv(xpriv)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	a, _ := hd.ChildMnemonic(7, 12)
+	b, _ := restored.ChildMnemonic(7, 12)
+	if a != b {
+		t.Error("child mnemonic differs when derived from xpriv")
+	}
+	if _, err = hd.ChildMnemonic(0, 15); err == nil {
+		t.Error("allowed an unsupported word count")
+	}
+	if _, err = hd.ChildMnemonic(-1, 12); err == nil {
+		t.Error("allowed a negative index")
+	}
+}
@@ -0,0 +1,115 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"strings"
+)
+
+// ErrFioAddressNotFound is returned when a FIO address is not registered
+var ErrFioAddressNotFound = errors.New("fio address not found")
+
+// BundledEndpoints lists the endpoints that can be paid for with bundled transactions and how many each uses, as
+// of FIO 3.x. Requests and OBT records use two because they store more data.
+var BundledEndpoints = map[string]uint64{
+	"add_pub_address":          1,
+	"remove_pub_address":       1,
+	"remove_all_pub_addresses": 1,
+	"new_funds_request":        2,
+	"cancel_funds_request":     1,
+	"reject_funds_request":     1,
+	"record_obt_data":          2,
+	"add_nft":                  2,
+	"remove_nft":               1,
+	"remove_all_nfts":          1,
+	"stake_fio_tokens":         1,
+	"unstake_fio_tokens":       1,
+}
+
+// BundleDecision is how an action will be paid for
+type BundleDecision struct {
+	FioAddress string `json:"fio_address"`
+	Endpoint   string `json:"end_point"`
+	// Remaining is how many bundled transactions the address has left, and Cost how many the action uses
+	Remaining uint64 `json:"remaining_bundled_tx"`
+	Cost      uint64 `json:"cost"`
+	// Bundled is true when the action is free, otherwise MaxFee is the fee in SUFs to use as max_fee
+	Bundled bool   `json:"bundled"`
+	MaxFee  uint64 `json:"max_fee"`
+}
+
+func (bd BundleDecision) String() string {
+	if bd.Bundled {
+		return fmt.Sprintf("%s by %s uses %d of %d bundled transactions", bd.Endpoint, bd.FioAddress, bd.Cost, bd.Remaining)
+	}
+	return fmt.Sprintf("%s by %s costs %s", bd.Endpoint, bd.FioAddress, FioAmount(bd.MaxFee))
+}
+
+// RemainingBundles queries how many bundled transactions a FIO address has left, ErrFioAddressNotFound is returned
+// if it is not registered
+func RemainingBundles(ctx context.Context, api *fio.API, fioAddress string) (uint64, error) {
+	pub := struct {
+		PublicAddress string `json:"public_address"`
+	}{}
+	found, err := chainPost(ctx, api, "/v1/chain/get_pub_address", map[string]string{
+		"fio_address": fioAddress,
+		"chain_code":  "FIO",
+		"token_code":  "FIO",
+	}, &pub)
+	if err != nil {
+		return 0, err
+	}
+	if !found || pub.PublicAddress == "" {
+		return 0, ErrFioAddressNotFound
+	}
+	names := struct {
+		FioAddresses []struct {
+			FioAddress         string `json:"fio_address"`
+			RemainingBundledTx uint64 `json:"remaining_bundled_tx"`
+		} `json:"fio_addresses"`
+	}{}
+	if _, err = chainPost(ctx, api, "/v1/chain/get_fio_names", map[string]string{"fio_public_key": pub.PublicAddress}, &names); err != nil {
+		return 0, err
+	}
+	for _, a := range names.FioAddresses {
+		if strings.EqualFold(a.FioAddress, fioAddress) {
+			return a.RemainingBundledTx, nil
+		}
+	}
+	return 0, ErrFioAddressNotFound
+}
+
+// BundledFee decides whether an action by fioAddress will use bundled transactions or pay a fee, and provides the
+// max_fee to use. An action is only free when enough bundled transactions remain for its cost.
+func (fc *FeeCache) BundledFee(ctx context.Context, endpoint string, fioAddress string) (*BundleDecision, error) {
+	if fioAddress == "" {
+		return nil, errors.New("fio address is required")
+	}
+	bd := &BundleDecision{FioAddress: fioAddress, Endpoint: endpoint, Cost: BundledEndpoints[endpoint]}
+	if bd.Cost > 0 {
+		remaining, err := RemainingBundles(ctx, fc.api, fioAddress)
+		if err != nil {
+			return nil, err
+		}
+		bd.Remaining = remaining
+		if remaining >= bd.Cost {
+			bd.Bundled = true
+			return bd, nil
+		}
+	}
+	fee, err := fc.FeeFor(ctx, endpoint, fioAddress)
+	if err != nil {
+		return nil, err
+	}
+	if fee == 0 && bd.Cost > 0 {
+		// cached while the address still had bundles
+		fc.Invalidate(fioAddress)
+		if fee, err = fc.FeeFor(ctx, endpoint, fioAddress); err != nil {
+			return nil, err
+		}
+	}
+	bd.MaxFee = fee
+	return bd, nil
+}
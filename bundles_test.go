@@ -0,0 +1,77 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBundledFee(t *testing.T) {
+	remaining := 1
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/v1/chain/get_pub_address":
+			if req["fio_address"] != "alice@fiotestnet" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"public_address":"FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"}`))
+		case "/v1/chain/get_fio_names":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"fio_addresses": []map[string]interface{}{
+				{"fio_address": "alice@fiotestnet", "remaining_bundled_tx": remaining},
+			}})
+		case "/v1/chain/get_fee":
+			// nodeos reports zero while bundles remain
+			if remaining > 0 {
+				_, _ = w.Write([]byte(`{"fee":0}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"fee":800000000}`))
+		}
+	}))
+	defer nodeos.Close()
+
+	fc, err := NewFeeCache(&fio.API{API: eos.API{BaseURL: nodeos.URL}}, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	bd, err := fc.BundledFee(ctx, "add_pub_address", "alice@fiotestnet")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bd.Bundled || bd.MaxFee != 0 || bd.Remaining != 1 || bd.Cost != 1 {
+		t.Error("expected add_pub_address to be bundled", bd)
+	}
+
+	// a request uses two bundles, so one is not enough, and the cached zero fee must not be used
+	if _, err = fc.FeeFor(ctx, "new_funds_request", "alice@fiotestnet"); err != nil {
+		t.Error(err)
+	}
+	remaining = 0
+	bd, err = fc.BundledFee(ctx, "new_funds_request", "alice@fiotestnet")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if bd.Bundled || bd.MaxFee != 800_000_000 {
+		t.Error("expected new_funds_request to need a fee", bd)
+	}
+
+	bd, err = fc.BundledFee(ctx, "transfer_tokens_pub_key", "alice@fiotestnet")
+	if err != nil || bd.Bundled || bd.Cost != 0 {
+		t.Error("transfers are never bundled", bd, err)
+	}
+	if _, err = fc.BundledFee(ctx, "add_pub_address", "bob@fiotestnet"); !errors.Is(err, ErrFioAddressNotFound) {
+		t.Error("expected ErrFioAddressNotFound, got", err)
+	}
+}
@@ -0,0 +1,66 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"net/http"
+	"path"
+)
+
+// MaxChainResponseSize limits the size of responses read from nodeos, larger responses return ErrResponseTooLarge
+var MaxChainResponseSize int64 = DefaultMaxResponseSize
+
+// chainPost posts params to a nodeos endpoint and decodes the response into result, found is false when nodeos
+// returns 404 which it uses for "nothing found" on many FIO endpoints
+func chainPost(ctx context.Context, api *fio.API, endpoint string, params interface{}, result interface{}) (found bool, err error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.BaseURL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := api.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	b, err := readLimited(resp.Body, MaxChainResponseSize)
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s returned %d: %s", path.Base(endpoint), resp.StatusCode, string(b))
+	}
+	if result == nil {
+		return true, nil
+	}
+	return true, json.Unmarshal(b, result)
+}
+
+// fioFirstAddress provides the first FIO address registered to a public key, or an empty string if there are none
+func fioFirstAddress(ctx context.Context, api *fio.API, pub string) (string, error) {
+	names := struct {
+		FioAddresses []struct {
+			FioAddress string `json:"fio_address"`
+		} `json:"fio_addresses"`
+	}{}
+	found, err := chainPost(ctx, api, "/v1/chain/get_fio_names", map[string]string{"fio_public_key": pub}, &names)
+	if err != nil || !found || len(names.FioAddresses) == 0 {
+		return "", err
+	}
+	return names.FioAddresses[0].FioAddress, nil
+}
@@ -0,0 +1,146 @@
+package fiox
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"strings"
+)
+
+// SLIP-0044 coin types used in the derivation path m/purpose'/coin'/account'/change/index
+const (
+	Slip44Bitcoin  uint32 = 0
+	Slip44Ethereum uint32 = 60
+	Slip44Eos      uint32 = 194
+	Slip44Fio      uint32 = 235
+)
+
+// ChainAddress is an address on another chain derived from the same seed, the fields match what is needed for a
+// FIO addaddress public_addresses entry.
+type ChainAddress struct {
+	ChainCode     string `json:"chain_code"`
+	TokenCode     string `json:"token_code"`
+	PublicAddress string `json:"public_address"`
+	Path          string `json:"-"`
+}
+
+// CoinPath provides the BIP44 path for a SLIP-0044 coin type, using the account and change of the Hd
+func (hd Hd) CoinPath(coinType uint32, index int) string {
+	return coinPath(44, coinType, hd.account, hd.change, index)
+}
+
+// CoinKeyAt derives the secp256k1 private key at m/44'/coinType'/account'/change/index
+func (hd Hd) CoinKeyAt(coinType uint32, index int) (*btcec.PrivateKey, error) {
+	key, err := hd.coinExtended(44, coinType, index)
+	if err != nil {
+		return nil, err
+	}
+	return key.ECPrivKey()
+}
+
+// BtcAddressAt provides a bitcoin address, if segwit is true a native segwit (P2WPKH, bc1...) address using the
+// BIP84 path m/84'/0'/account'/change/index, otherwise a legacy P2PKH address on the BIP44 path.
+func (hd Hd) BtcAddressAt(index int, segwit bool) (*ChainAddress, error) {
+	purpose := uint32(44)
+	if segwit {
+		purpose = 84
+	}
+	key, err := hd.coinExtended(purpose, Slip44Bitcoin, index)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	hash := btcutil.Hash160(pub.SerializeCompressed())
+	var addr btcutil.Address
+	if segwit {
+		addr, err = btcutil.NewAddressWitnessPubKeyHash(hash, &chaincfg.MainNetParams)
+	} else {
+		addr, err = btcutil.NewAddressPubKeyHash(hash, &chaincfg.MainNetParams)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAddress{
+		ChainCode:     "BTC",
+		TokenCode:     "BTC",
+		PublicAddress: addr.EncodeAddress(),
+		Path:          coinPath(purpose, Slip44Bitcoin, hd.account, hd.change, index),
+	}, nil
+}
+
+// EthAddressAt provides an EIP-55 checksummed Ethereum address at m/44'/60'/account'/change/index
+func (hd Hd) EthAddressAt(index int) (*ChainAddress, error) {
+	key, err := hd.coinExtended(44, Slip44Ethereum, index)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := key.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAddress{
+		ChainCode:     "ETH",
+		TokenCode:     "ETH",
+		PublicAddress: eip55(keccak256(pub.SerializeUncompressed()[1:])[12:]),
+		Path:          hd.CoinPath(Slip44Ethereum, index),
+	}, nil
+}
+
+// EosKeyAt derives an EOS private key at m/44'/194'/account'/change/index
+func (hd Hd) EosKeyAt(index int) (*ecc.PrivateKey, error) {
+	key, err := hd.coinExtended(44, Slip44Eos, index)
+	if err != nil {
+		return nil, err
+	}
+	return eccPrivateKey(key)
+}
+
+// EosAddressAt provides the EOS public key at m/44'/194'/account'/change/index. EOS addresses are account names,
+// so the key must be linked to an account before it can receive tokens.
+func (hd Hd) EosAddressAt(index int) (*ChainAddress, error) {
+	key, err := hd.EosKeyAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAddress{
+		ChainCode:     "EOS",
+		TokenCode:     "EOS",
+		PublicAddress: "EOS" + key.PublicKey().String()[3:],
+		Path:          hd.CoinPath(Slip44Eos, index),
+	}, nil
+}
+
+func (hd Hd) coinExtended(purpose uint32, coinType uint32, index int) (*hdkeychain.ExtendedKey, error) {
+	if err := checkIndex(index); err != nil {
+		return nil, err
+	}
+	if err := hd.canDerive(); err != nil {
+		return nil, err
+	}
+	return deriveExtended(hd.master, coinPath(purpose, coinType, hd.account, hd.change, index))
+}
+
+func coinPath(purpose uint32, coinType uint32, account int, change int, index int) string {
+	return fmt.Sprintf("m/%d'/%d'/%d'/%d/%d", purpose, coinType, account, change, index)
+}
+
+// eip55 encodes an address with the mixed-case checksum from EIP-55
+func eip55(address []byte) string {
+	lower := hex.EncodeToString(address)
+	hash := hex.EncodeToString(keccak256([]byte(lower)))
+	result := make([]byte, len(lower))
+	for i := range lower {
+		result[i] = lower[i]
+		if lower[i] > '9' && hash[i] >= '8' {
+			result[i] = strings.ToUpper(string(lower[i]))[0]
+		}
+	}
+	return "0x" + string(result)
+}
@@ -0,0 +1,46 @@
+package fiox
+
+import (
+	"testing"
+)
+
+func TestHd_ChainAddresses(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	legacy, err := hd.BtcAddressAt(0, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if legacy.PublicAddress != "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA" {
+		t.Error("BIP44 bitcoin address mismatch", legacy.PublicAddress)
+	}
+	segwit, err := hd.BtcAddressAt(0, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if segwit.PublicAddress != "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu" || segwit.Path != "m/84'/0'/0'/0/0" {
+		t.Error("BIP84 bitcoin address mismatch", segwit.PublicAddress)
+	}
+	eth, err := hd.EthAddressAt(0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if eth.PublicAddress != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Error("ethereum address mismatch", eth.PublicAddress)
+	}
+	eos, err := hd.EosAddressAt(0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := hd.Derive("m/44'/194'/0'/0/0")
+	if eos.PublicAddress[:3] != "EOS" || eos.PublicAddress[3:] != key.PublicKey().String()[3:] {
+		t.Error("EOS key mismatch", eos.PublicAddress)
+	}
+}
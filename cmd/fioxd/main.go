@@ -0,0 +1,92 @@
+// fioxd holds the keys of an HD wallet and signs for other services over gRPC with mutual TLS, so each service does
+// not need to load the mnemonic itself. The wallet is the encrypted JSON from Hd.EncryptedJSON, its passphrase is read
+// from FIOXD_PASSPHRASE or prompted for.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/blockpane/fio-extras"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	var (
+		listen   = flag.String("listen", "127.0.0.1:8765", "address to listen on")
+		wallet   = flag.String("wallet", "", "encrypted HD wallet JSON file")
+		keys     = flag.Int("keys", 1, "number of derived keys to sign with")
+		cert     = flag.String("cert", "", "server TLS certificate")
+		key      = flag.String("key", "", "server TLS key")
+		clientCA = flag.String("client-ca", "", "CA that signs client certificates, required")
+		audit    = flag.String("audit", "", "append every signature to this audit log")
+	)
+	flag.Parse()
+	if *wallet == "" || *clientCA == "" {
+		fmt.Fprintln(os.Stderr, "fioxd: -wallet and -client-ca are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tlsConfig, err := fiox.TLSConfig{CertFile: *cert, KeyFile: *key, CAFile: *clientCA}.ServerConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	hd, err := loadWallet(*wallet)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var signer fiox.Signer
+	if signer, err = hd.Signer(*keys); err != nil {
+		log.Fatal(err)
+	}
+	if *audit != "" {
+		auditLog, err := fiox.OpenAuditLog(*audit)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer auditLog.Close()
+		if signer, err = fiox.NewAuditSigner(signer, auditLog); err != nil {
+			log.Fatal(err)
+		}
+	}
+	handler, err := fiox.NewSignerServer(signer, hd)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{Addr: *listen, Handler: handler, TLSConfig: tlsConfig, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+	pubs, _ := signer.PublicKeys(context.Background())
+	log.Printf("fioxd listening on %s with %d keys", *listen, len(pubs))
+	if err = server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		log.Println(err)
+	}
+}
+
+func loadWallet(file string) (*fiox.Hd, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	passphrase := os.Getenv("FIOXD_PASSPHRASE")
+	if passphrase == "" {
+		if passphrase, err = fiox.PromptPassword(os.Stderr, os.Stdin).WalletPassword(context.Background(), file); err != nil {
+			return nil, err
+		}
+	}
+	return fiox.NewHdFromEncryptedJSON(b, passphrase)
+}
@@ -0,0 +1,394 @@
+package fiox
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings needed to reach keosd and nodeos, see LoadConfig for how it is read
+type Config struct {
+	Keosd  KeosdConfig  `config:"keosd" json:"keosd"`
+	Nodeos NodeosConfig `config:"nodeos" json:"nodeos"`
+}
+
+// KeosdConfig configures a KeosClient. WalletURL uses the clio --wallet-url syntax, when both it and Socket are
+// empty the socket is discovered. Keyring is a service name for reading passwords from the OS keyring.
+type KeosdConfig struct {
+	WalletURL  string        `config:"wallet_url" json:"wallet_url"`
+	Socket     string        `config:"socket" json:"socket"`
+	Wallet     string        `config:"wallet" json:"wallet"`
+	Timeout    time.Duration `config:"timeout" json:"timeout"`
+	AutoUnlock bool          `config:"auto_unlock" json:"auto_unlock"`
+	Keyring    string        `config:"keyring" json:"keyring"`
+	TLS        TLSConfig     `config:"tls" json:"tls"`
+}
+
+// NodeosConfig configures the nodeos API
+type NodeosConfig struct {
+	URL     string        `config:"url" json:"url"`
+	Timeout time.Duration `config:"timeout" json:"timeout"`
+	TLS     TLSConfig     `config:"tls" json:"tls"`
+}
+
+// TLSConfig holds the TLS options for an https endpoint, CertFile and KeyFile are for client certificates
+type TLSConfig struct {
+	CAFile             string `config:"ca_file" json:"ca_file"`
+	CertFile           string `config:"cert_file" json:"cert_file"`
+	KeyFile            string `config:"key_file" json:"key_file"`
+	ServerName         string `config:"server_name" json:"server_name"`
+	InsecureSkipVerify bool   `config:"insecure_skip_verify" json:"insecure_skip_verify"`
+}
+
+// ConfigEnvPrefix starts the name of every environment variable read by LoadConfig, a setting's variable is the
+// prefix followed by its path in upper case, for example FIOX_KEOSD_WALLET_URL or FIOX_NODEOS_TLS_CA_FILE.
+// FIOX_CONFIG names the config file.
+const ConfigEnvPrefix = "FIOX_"
+
+// LoadConfig reads a config file and then applies FIOX_ environment variables over it. The file is TOML, YAML, or
+// JSON depending on its extension, only tables (sections) of simple values are supported, durations are strings
+// such as "30s". If path is empty FIOX_CONFIG is used, and if that is empty only the environment is read.
+func LoadConfig(path string) (*Config, error) {
+	c := &Config{}
+	if path == "" {
+		path = os.Getenv(ConfigEnvPrefix + "CONFIG")
+	}
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range values {
+			if err = setConfigValue(c, key, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+	for _, key := range configKeys(reflect.TypeOf(c).Elem(), "") {
+		env := ConfigEnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if value, ok := os.LookupEnv(env); ok {
+			if err := setConfigValue(c, key, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// KeosClient provides a client for the configured keosd, opts are applied after the configuration
+func (c *Config) KeosClient(opts ...KeosOption) (*KeosClient, error) {
+	configured := make([]KeosOption, 0)
+	switch {
+	case c.Keosd.WalletURL != "":
+		configured = append(configured, WithWalletURL(c.Keosd.WalletURL))
+	case c.Keosd.Socket != "":
+		configured = append(configured, WithSocket(c.Keosd.Socket))
+	}
+	if c.Keosd.Timeout > 0 {
+		configured = append(configured, WithTimeout(c.Keosd.Timeout))
+	}
+	if c.Keosd.Keyring != "" {
+		configured = append(configured, WithCredentials(NewKeyring(c.Keosd.Keyring)))
+	}
+	if strings.HasPrefix(c.Keosd.WalletURL, "https://") {
+		client, err := c.Keosd.TLS.httpClient()
+		if err != nil {
+			return nil, err
+		}
+		configured = append(configured, WithHTTPClient(client))
+	}
+	k, err := NewKeosClientOpts(append(configured, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	k.Wallet = c.Keosd.Wallet
+	k.AutoUnlock = c.Keosd.AutoUnlock
+	return k, nil
+}
+
+// NodeosAPI provides a fio.API for the configured nodeos, suitable for the queries made by this package. To push
+// transactions use fio.NewConnection with Nodeos.URL and replace its HttpClient with NodeosHTTPClient.
+func (c *Config) NodeosAPI() (*fio.API, error) {
+	if c.Nodeos.URL == "" {
+		return nil, errors.New("a nodeos url is required")
+	}
+	client, err := c.NodeosHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	api := &fio.API{}
+	api.BaseURL = strings.TrimSuffix(c.Nodeos.URL, "/")
+	api.HttpClient = client
+	return api, nil
+}
+
+// NodeosHTTPClient provides an http client with the nodeos timeout and TLS options applied
+func (c *Config) NodeosHTTPClient() (*http.Client, error) {
+	client, err := c.Nodeos.TLS.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	client.Timeout = c.Nodeos.Timeout
+	return client, nil
+}
+
+// ClientConfig builds a tls.Config, it is nil when no options are set
+func (t TLSConfig) ClientConfig() (*tls.Config, error) {
+	if t == (TLSConfig{}) {
+		return nil, nil
+	}
+	conf := &tls.Config{ServerName: t.ServerName, InsecureSkipVerify: t.InsecureSkipVerify} // #nosec
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// ServerConfig builds a tls.Config for a server from CertFile and KeyFile, when CAFile is set clients must present a
+// certificate it signed
+func (t TLSConfig) ServerConfig() (*tls.Config, error) {
+	if t.CertFile == "" || t.KeyFile == "" {
+		return nil, errors.New("a certificate and key are required")
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	conf := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.ClientCAs = x509.NewCertPool()
+		if !conf.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return conf, nil
+}
+
+func (t TLSConfig) httpClient() (*http.Client, error) {
+	conf, err := t.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = conf
+	return &http.Client{Transport: transport}, nil
+}
+
+// configKeys lists the dotted paths of every setting in t
+func configKeys(t reflect.Type, prefix string) []string {
+	keys := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := prefix + f.Tag.Get("config")
+		if f.Type.Kind() == reflect.Struct {
+			keys = append(keys, configKeys(f.Type, key+".")...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// setConfigValue sets the setting at a dotted path from its string form
+func setConfigValue(c *Config, key string, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown setting %s", key)
+		}
+		found := false
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).Tag.Get("config") == part {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown setting %s", key)
+		}
+	}
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("%s is a section, not a setting", key)
+	}
+	return nil
+}
+
+// readConfigFile reads a config file into dotted paths and string values
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return parseTOML(string(data))
+	case ".yaml", ".yml":
+		return parseYAML(string(data))
+	case ".json":
+		values := make(map[string]string)
+		raw := make(map[string]interface{})
+		if err = json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		flattenJSON(raw, "", values)
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported config file type %s, expected .toml, .yaml, or .json", filepath.Ext(path))
+}
+
+func flattenJSON(raw map[string]interface{}, prefix string, values map[string]string) {
+	for key, value := range raw {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenJSON(v, prefix+key+".", values)
+		case string:
+			values[prefix+key] = v
+		default:
+			values[prefix+key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// parseTOML handles the subset of TOML used for config: [tables] and key = value pairs
+func parseTOML(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	table := ""
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			table = strings.TrimSpace(line[1:len(line)-1]) + "."
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		value, err := unquoteConfig(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		values[table+strings.TrimSpace(line[:eq])] = value
+	}
+	return values, scanner.Err()
+}
+
+// parseYAML handles the subset of YAML used for config: nested mappings of scalars
+func parseYAML(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	type level struct {
+		indent int
+		key    string
+	}
+	parents := make([]level, 0)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		text := stripComment(scanner.Text())
+		line := strings.TrimSpace(text)
+		if line == "" || line == "---" {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("line %d: expected key: value", n)
+		}
+		key := strings.TrimSpace(line[:colon])
+		prefix := ""
+		for _, p := range parents {
+			prefix += p.key + "."
+		}
+		rest := strings.TrimSpace(line[colon+1:])
+		if rest == "" {
+			parents = append(parents, level{indent: indent, key: key})
+			continue
+		}
+		value, err := unquoteConfig(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		values[prefix+key] = value
+	}
+	return values, scanner.Err()
+}
+
+// stripComment removes a # comment that is not inside quotes
+func stripComment(line string) string {
+	quote := rune(0)
+	for i, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquoteConfig(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", errors.New("unterminated string")
+		}
+		return value[1 : len(value)-1], nil
+	}
+	return value, nil
+}
@@ -0,0 +1,86 @@
+package fiox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"fiox.toml": `# keosd settings
+[keosd]
+wallet_url = "unix:///tmp/keosd.sock"
+wallet = 'default' # trailing comment
+timeout = "30s"
+auto_unlock = true
+
+[nodeos.tls]
+server_name = "api.fio.example"
+`,
+		"fiox.yaml": `keosd:
+  wallet_url: "unix:///tmp/keosd.sock"
+  wallet: default
+  timeout: 30s
+  auto_unlock: true
+nodeos:
+  tls:
+    server_name: api.fio.example # comment
+`,
+		"fiox.json": `{"keosd":{"wallet_url":"unix:///tmp/keosd.sock","wallet":"default","timeout":"30s","auto_unlock":true},"nodeos":{"tls":{"server_name":"api.fio.example"}}}`,
+	}
+	_ = os.Setenv("FIOX_NODEOS_URL", "https://api.fio.example/")
+	defer os.Unsetenv("FIOX_NODEOS_URL")
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Error(err)
+			return
+		}
+		c, err := LoadConfig(path)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		if c.Keosd.WalletURL != "unix:///tmp/keosd.sock" || c.Keosd.Wallet != "default" || c.Keosd.Timeout != 30*time.Second ||
+			!c.Keosd.AutoUnlock || c.Nodeos.TLS.ServerName != "api.fio.example" || c.Nodeos.URL != "https://api.fio.example/" {
+			t.Errorf("%s: unexpected config %+v", name, c)
+			continue
+		}
+		k, err := c.KeosClient()
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		if k.Socket != "/tmp/keosd.sock" || k.Wallet != "default" || !k.AutoUnlock {
+			t.Errorf("%s: client was not configured", name)
+		}
+		api, err := c.NodeosAPI()
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		if api.BaseURL != "https://api.fio.example" {
+			t.Error(name, "unexpected nodeos url", api.BaseURL)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.toml")
+	_ = ioutil.WriteFile(bad, []byte("[keosd]\nunknown = 1\n"), 0600)
+	if _, err = LoadConfig(bad); err == nil {
+		t.Error("expected an error for an unknown setting")
+	}
+	_ = os.Setenv("FIOX_KEOSD_TIMEOUT", "soon")
+	defer os.Unsetenv("FIOX_KEOSD_TIMEOUT")
+	if _, err = LoadConfig(""); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
@@ -0,0 +1,258 @@
+package fiox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+)
+
+// ErrContentMac is returned when encrypted content fails authentication, either the wrong keys were used or the
+// content was modified
+var ErrContentMac = errors.New("encrypted content failed authentication")
+
+// FundsContent is the content of a FIO Request (new_funds_request), it is encrypted so only the payer and payee can
+// read it. Memo, Hash, and OfflineUrl are optional and are omitted when empty.
+type FundsContent struct {
+	PayeePublicAddress string `json:"payee_public_address"`
+	Amount             string `json:"amount"`
+	ChainCode          string `json:"chain_code"`
+	TokenCode          string `json:"token_code"`
+	Memo               string `json:"memo,omitempty"`
+	Hash               string `json:"hash,omitempty"`
+	OfflineUrl         string `json:"offline_url,omitempty"`
+}
+
+// EncryptNewFundsContent encrypts the content of a FIO Request using the ECDH secret between the sender's private
+// key and the counterparty's public key, the result is the base64 string used for the content field.
+func EncryptNewFundsContent(priv *ecc.PrivateKey, counterparty *ecc.PublicKey, content *FundsContent) (string, error) {
+	if content == nil {
+		return "", errors.New("content cannot be nil")
+	}
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, content.PayeePublicAddress, content.Amount, content.ChainCode, content.TokenCode)
+	writeAbiOptionalStrings(buf, content.Memo, content.Hash, content.OfflineUrl)
+	return encryptContent(priv, counterparty, buf.Bytes())
+}
+
+// DecryptFundsContent decrypts the content of a FIO Request, either party can decrypt it using their private key
+// and the other party's public key.
+func DecryptFundsContent(priv *ecc.PrivateKey, counterparty *ecc.PublicKey, encrypted string) (*FundsContent, error) {
+	message, err := decryptContent(priv, counterparty, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(message)
+	fields, err := readAbiStrings(r, 4)
+	if err != nil {
+		return nil, err
+	}
+	optional, err := readAbiOptionalStrings(r, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &FundsContent{
+		PayeePublicAddress: fields[0],
+		Amount:             fields[1],
+		ChainCode:          fields[2],
+		TokenCode:          fields[3],
+		Memo:               optional[0],
+		Hash:               optional[1],
+		OfflineUrl:         optional[2],
+	}, nil
+}
+
+// ObtContent is the content of a record_obt_data action, recording a transaction on another chain between the payer
+// and payee. Status and ObtId are required by the ABI, Memo, Hash, and OfflineUrl are optional.
+type ObtContent struct {
+	PayerPublicAddress string `json:"payer_public_address"`
+	PayeePublicAddress string `json:"payee_public_address"`
+	Amount             string `json:"amount"`
+	ChainCode          string `json:"chain_code"`
+	TokenCode          string `json:"token_code"`
+	Status             string `json:"status"`
+	ObtId              string `json:"obt_id"`
+	Memo               string `json:"memo,omitempty"`
+	Hash               string `json:"hash,omitempty"`
+	OfflineUrl         string `json:"offline_url,omitempty"`
+}
+
+// EncryptObtContent encrypts the content of an OBT record the same way as EncryptNewFundsContent
+func EncryptObtContent(priv *ecc.PrivateKey, counterparty *ecc.PublicKey, content *ObtContent) (string, error) {
+	if content == nil {
+		return "", errors.New("content cannot be nil")
+	}
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, content.PayerPublicAddress, content.PayeePublicAddress, content.Amount, content.ChainCode,
+		content.TokenCode, content.Status, content.ObtId)
+	writeAbiOptionalStrings(buf, content.Memo, content.Hash, content.OfflineUrl)
+	return encryptContent(priv, counterparty, buf.Bytes())
+}
+
+// DecryptObtContent decrypts the content of an OBT record, either party can decrypt it
+func DecryptObtContent(priv *ecc.PrivateKey, counterparty *ecc.PublicKey, encrypted string) (*ObtContent, error) {
+	message, err := decryptContent(priv, counterparty, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(message)
+	fields, err := readAbiStrings(r, 7)
+	if err != nil {
+		return nil, err
+	}
+	optional, err := readAbiOptionalStrings(r, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &ObtContent{
+		PayerPublicAddress: fields[0],
+		PayeePublicAddress: fields[1],
+		Amount:             fields[2],
+		ChainCode:          fields[3],
+		TokenCode:          fields[4],
+		Status:             fields[5],
+		ObtId:              fields[6],
+		Memo:               optional[0],
+		Hash:               optional[1],
+		OfflineUrl:         optional[2],
+	}, nil
+}
+
+func encryptContent(priv *ecc.PrivateKey, counterparty *ecc.PublicKey, message []byte) (string, error) {
+	secret, err := SharedSecret(priv, counterparty)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(secret)
+	iv := make([]byte, aes.BlockSize)
+	if _, err = rand.Read(iv); err != nil {
+		return "", err
+	}
+	encrypted, err := checkEncrypt(secret, message, iv)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func decryptContent(priv *ecc.PrivateKey, counterparty *ecc.PublicKey, encrypted string) ([]byte, error) {
+	secret, err := SharedSecret(priv, counterparty)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(secret)
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	return checkDecrypt(secret, data)
+}
+
+// checkEncrypt follows the FIO spec: K = sha512(secret), Ke = K[:32] for AES-256-CBC, Km = K[32:] for an
+// HMAC-SHA256 over IV || C. The result is IV || C || M.
+func checkEncrypt(secret []byte, message []byte, iv []byte) ([]byte, error) {
+	k := sha512.Sum512(secret)
+	defer wipe(k[:])
+	block, err := aes.NewCipher(k[:32])
+	if err != nil {
+		return nil, err
+	}
+	padded := pkcs7Pad(message, aes.BlockSize)
+	out := make([]byte, aes.BlockSize+len(padded))
+	copy(out, iv)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out[aes.BlockSize:], padded)
+	mac := hmac.New(sha256.New, k[32:])
+	_, _ = mac.Write(out)
+	return mac.Sum(out), nil
+}
+
+func checkDecrypt(secret []byte, data []byte) ([]byte, error) {
+	if len(data) < aes.BlockSize*2+sha256.Size || (len(data)-sha256.Size)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted content has an invalid length")
+	}
+	k := sha512.Sum512(secret)
+	defer wipe(k[:])
+	body, m := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	mac := hmac.New(sha256.New, k[32:])
+	_, _ = mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), m) {
+		return nil, ErrContentMac
+	}
+	block, err := aes.NewCipher(k[:32])
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(body)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, body[:aes.BlockSize]).CryptBlocks(plain, body[aes.BlockSize:])
+	return pkcs7Unpad(plain, aes.BlockSize)
+}
+
+// writeAbiStrings uses the eosio binary encoding for strings: a varuint32 length followed by the bytes
+func writeAbiStrings(buf *bytes.Buffer, values ...string) {
+	for _, v := range values {
+		writeVarUint(buf, uint64(len(v)))
+		buf.WriteString(v)
+	}
+}
+
+// writeAbiOptionalStrings encodes "string?" fields, empty strings are written as absent
+func writeAbiOptionalStrings(buf *bytes.Buffer, values ...string) {
+	for _, v := range values {
+		if v == "" {
+			buf.WriteByte(0)
+			continue
+		}
+		buf.WriteByte(1)
+		writeAbiStrings(buf, v)
+	}
+}
+
+func readAbiStrings(r *bytes.Reader, count int) ([]string, error) {
+	values := make([]string, count)
+	for i := range values {
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if l > uint64(r.Len()) {
+			return nil, errors.New("invalid string length in content")
+		}
+		b := make([]byte, l)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		values[i] = string(b)
+	}
+	return values, nil
+}
+
+func readAbiOptionalStrings(r *bytes.Reader, count int) ([]string, error) {
+	values := make([]string, count)
+	for i := range values {
+		present, err := r.ReadByte()
+		if err == io.EOF {
+			// trailing optional fields may be left out entirely
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if present == 0 {
+			continue
+		}
+		s, err := readAbiStrings(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = s[0]
+	}
+	return values, nil
+}
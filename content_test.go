@@ -0,0 +1,88 @@
+package fiox
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestFundsContent(t *testing.T) {
+	alice, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	bob, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	aliceKey, _ := alice.keyAt(0)
+	bobKey, _ := bob.keyAt(0)
+	alicePub, bobPub := aliceKey.PublicKey(), bobKey.PublicKey()
+
+	content := &FundsContent{
+		PayeePublicAddress: "0xab5801a7d398351b8be11c439e05c5b3259aec9b",
+		Amount:             "1.5",
+		ChainCode:          "ETH",
+		TokenCode:          "ETH",
+		Memo:               "invoice 42",
+	}
+	encrypted, err := EncryptNewFundsContent(aliceKey, &bobPub, content)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	decrypted, err := DecryptFundsContent(bobKey, &alicePub, encrypted)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if *decrypted != *content {
+		t.Errorf("content did not match: %+v", decrypted)
+	}
+
+	other, _ := bob.keyAt(1)
+	if _, err = DecryptFundsContent(other, &alicePub, encrypted); err != ErrContentMac {
+		t.Error("expected a mac failure with the wrong key, got", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(encrypted)
+	raw[20] ^= 1
+	if _, err = DecryptFundsContent(bobKey, &alicePub, base64.StdEncoding.EncodeToString(raw)); err != ErrContentMac {
+		t.Error("expected a mac failure for modified content, got", err)
+	}
+}
+
+func TestObtContent(t *testing.T) {
+	alice, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	aliceKey, _ := alice.keyAt(0)
+	bobKey, _ := alice.keyAt(1)
+	alicePub, bobPub := aliceKey.PublicKey(), bobKey.PublicKey()
+
+	content := &ObtContent{
+		PayerPublicAddress: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		PayeePublicAddress: "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g",
+		Amount:             "0.001",
+		ChainCode:          "BTC",
+		TokenCode:          "BTC",
+		Status:             "sent_to_blockchain",
+		ObtId:              "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+		OfflineUrl:         "https://example.com/receipt",
+	}
+	encrypted, err := EncryptObtContent(bobKey, &alicePub, content)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	decrypted, err := DecryptObtContent(aliceKey, &bobPub, encrypted)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if *decrypted != *content {
+		t.Errorf("content did not match: %+v", decrypted)
+	}
+}
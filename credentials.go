@@ -0,0 +1,48 @@
+package fiox
+
+import (
+	"errors"
+)
+
+// ErrCredentialNotFound is returned by a CredentialProvider that has no password for a wallet
+var ErrCredentialNotFound = errors.New("no password stored for the wallet")
+
+// CredentialProvider stores wallet passwords somewhere safer than flags or config files, see Keyring
+type CredentialProvider interface {
+	Password(wallet string) (string, error)
+	SetPassword(wallet string, password string) error
+	DeletePassword(wallet string) error
+}
+
+// DefaultKeyringService is the service name Keyring uses when none is given
+const DefaultKeyringService = "fio-extras keosd"
+
+// Keyring is a CredentialProvider using the OS keyring: the macOS Keychain via the security tool, the Secret
+// Service (GNOME Keyring, KWallet) via secret-tool on Linux and the BSDs, and the Windows Credential Manager.
+// Passwords are stored under the service name with the wallet name as the account.
+type Keyring struct {
+	Service string
+}
+
+// NewKeyring provides a Keyring for service, an empty service uses DefaultKeyringService
+func NewKeyring(service string) *Keyring {
+	if service == "" {
+		service = DefaultKeyringService
+	}
+	return &Keyring{Service: service}
+}
+
+func (kr *Keyring) service() string {
+	if kr == nil || kr.Service == "" {
+		return DefaultKeyringService
+	}
+	return kr.Service
+}
+
+// WithCredentials looks up the password when Unlock is called without one, and saves the password of wallets made
+// with Create
+func WithCredentials(provider CredentialProvider) KeosOption {
+	return func(k *KeosClient) {
+		k.credentials = provider
+	}
+}
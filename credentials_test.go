@@ -0,0 +1,57 @@
+package fiox
+
+import (
+	"context"
+	"testing"
+)
+
+// memoryCredentials is a CredentialProvider for tests
+type memoryCredentials map[string]string
+
+func (m memoryCredentials) Password(wallet string) (string, error) {
+	if p, ok := m[wallet]; ok {
+		return p, nil
+	}
+	return "", ErrCredentialNotFound
+}
+
+func (m memoryCredentials) SetPassword(wallet string, password string) error {
+	m[wallet] = password
+	return nil
+}
+
+func (m memoryCredentials) DeletePassword(wallet string) error {
+	delete(m, wallet)
+	return nil
+}
+
+func TestWithCredentials(t *testing.T) {
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/create":       "PW5KFWYKqvt63d4iNvedfDEPVZL227D3RQ1zpVFzuUwhMAJmRAYyX",
+		"/v1/wallet/list_wallets": []string{"new"},
+		"/v1/wallet/unlock":       struct{}{},
+	}, requests)
+	defer server.Close()
+
+	creds := memoryCredentials{}
+	k := NewKeosClient(server.URL, "", WithCredentials(creds))
+	ctx := context.Background()
+	if _, err := k.Create(ctx, "new"); err != nil {
+		t.Error(err)
+		return
+	}
+	if creds["new"] != "PW5KFWYKqvt63d4iNvedfDEPVZL227D3RQ1zpVFzuUwhMAJmRAYyX" {
+		t.Error("password was not saved")
+	}
+	if err := k.Unlock(ctx, "", "new"); err != nil {
+		t.Error(err)
+		return
+	}
+	if requests["/v1/wallet/unlock"] != `["new","PW5KFWYKqvt63d4iNvedfDEPVZL227D3RQ1zpVFzuUwhMAJmRAYyX"]` {
+		t.Error("unlock did not use the saved password", requests["/v1/wallet/unlock"])
+	}
+	if err := k.Unlock(ctx, "", "missing"); err == nil {
+		t.Error("expected an error without a saved password")
+	}
+}
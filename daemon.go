@@ -0,0 +1,169 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeosDaemon runs keosd directly rather than relying on clio to start it, set the fields and call Start
+type KeosDaemon struct {
+	Binary        string        // defaults to keosd or fio-wallet, whichever is found first
+	WalletDir     string        // required
+	SocketPath    string        // defaults to keosd.sock in WalletDir
+	UnlockTimeout time.Duration // defaults to the keosd default of 900 seconds
+	Args          []string      // any other arguments for keosd
+	StopTimeout   time.Duration // how long Stop waits before killing the process, defaults to 5 seconds
+
+	mux  sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{} // closed when keosd exits
+	err  error
+}
+
+// Start launches keosd and waits until its socket accepts connections. If ctx is cancelled keosd is stopped.
+func (d *KeosDaemon) Start(ctx context.Context) error {
+	if err := d.launch(); err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = d.Stop()
+		case <-d.done:
+		}
+	}()
+
+	// wait for the socket
+	for {
+		dialCtx, cancel := context.WithTimeout(ctx, time.Second)
+		conn, err := dialKeosd(dialCtx, d.SocketPath)
+		cancel()
+		if err == nil {
+			return conn.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.done:
+			return fmt.Errorf("keosd exited before its socket was ready: %v", d.err)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func (d *KeosDaemon) launch() error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if d.cmd != nil {
+		return errors.New("keosd has already been started")
+	}
+	if d.WalletDir == "" {
+		return errors.New("a wallet directory is required")
+	}
+	if err := os.MkdirAll(d.WalletDir, 0700); err != nil {
+		return err
+	}
+	if d.Binary == "" {
+		binary, err := findExecutable("keosd", "fio-wallet")
+		if err != nil {
+			return err
+		}
+		d.Binary = binary
+	}
+	if d.SocketPath == "" {
+		d.SocketPath = filepath.Join(d.WalletDir, "keosd.sock")
+	}
+	args := []string{
+		"--wallet-dir=" + d.WalletDir,
+		"--unix-socket-path=" + d.SocketPath,
+		"--http-server-address=",
+	}
+	if d.UnlockTimeout > 0 {
+		args = append(args, "--unlock-timeout="+strconv.Itoa(int(d.UnlockTimeout.Seconds())))
+	}
+	d.cmd = exec.Command(d.Binary, append(args, d.Args...)...)
+	if err := d.cmd.Start(); err != nil {
+		d.cmd = nil
+		return err
+	}
+	d.done = make(chan struct{})
+	go func() {
+		// err is only read after done is closed
+		d.err = d.cmd.Wait()
+		close(d.done)
+	}()
+	return nil
+}
+
+// Version runs the keosd binary with --version, this does not need the daemon to be running
+func (d *KeosDaemon) Version(ctx context.Context) (string, error) {
+	binary := d.Binary
+	if binary == "" {
+		var err error
+		if binary, err = findExecutable("keosd", "fio-wallet"); err != nil {
+			return "", err
+		}
+	}
+	out, err := exec.CommandContext(ctx, binary, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Client provides a KeosClient connected to the daemon's socket
+func (d *KeosDaemon) Client(opts ...KeosOption) *KeosClient {
+	return NewKeosClient("", d.SocketPath, opts...)
+}
+
+// Signal sends a signal to keosd
+func (d *KeosDaemon) Signal(sig os.Signal) error {
+	if d.cmd == nil || d.cmd.Process == nil {
+		return errors.New("keosd is not running")
+	}
+	return d.cmd.Process.Signal(sig)
+}
+
+// Stop asks keosd to exit and waits for it, killing it after StopTimeout
+func (d *KeosDaemon) Stop() error {
+	if d.cmd == nil || d.cmd.Process == nil {
+		return errors.New("keosd is not running")
+	}
+	select {
+	case <-d.done:
+		return nil
+	default:
+	}
+	timeout := d.StopTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if err := d.cmd.Process.Signal(os.Interrupt); err != nil {
+		// signals are not supported on every platform
+		_ = d.cmd.Process.Kill()
+	}
+	select {
+	case <-d.done:
+	case <-time.After(timeout):
+		_ = d.cmd.Process.Kill()
+		<-d.done
+	}
+	return nil
+}
+
+// Wait blocks until keosd exits and returns its exit error
+func (d *KeosDaemon) Wait() error {
+	if d.done == nil {
+		return errors.New("keosd has not been started")
+	}
+	<-d.done
+	return d.err
+}
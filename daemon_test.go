@@ -0,0 +1,101 @@
+package fiox
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// the test binary doubles as a fake keosd for the daemon tests
+	if os.Getenv("FIOX_FAKE_KEOSD") == "1" {
+		fakeKeosd()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeKeosd serves list_wallets on the unix socket until interrupted
+func fakeKeosd() {
+	fs := flag.NewFlagSet("keosd", flag.ExitOnError)
+	socket := fs.String("unix-socket-path", "", "")
+	fs.String("wallet-dir", "", "")
+	fs.String("http-server-address", "", "")
+	fs.Int("unlock-timeout", 900, "")
+	_ = fs.Parse(os.Args[1:])
+	l, err := net.Listen("unix", *socket)
+	if err != nil {
+		os.Exit(1)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		_ = l.Close()
+	}()
+	_ = http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["default *"]`))
+	}))
+}
+
+func TestKeosDaemon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keosd")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	_ = os.Setenv("FIOX_FAKE_KEOSD", "1")
+	defer os.Unsetenv("FIOX_FAKE_KEOSD")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	d := &KeosDaemon{Binary: os.Args[0], WalletDir: filepath.Join(dir, "wallets"), UnlockTimeout: time.Hour}
+	if err = d.Start(ctx); err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.HasSuffix(d.SocketPath, "keosd.sock") {
+		t.Error("unexpected socket path", d.SocketPath)
+	}
+	if err = d.Start(ctx); err == nil {
+		t.Error("expected an error starting twice")
+	}
+	wallets, err := d.Client().ListWallets(ctx)
+	if err != nil || len(wallets) != 1 || !wallets[0].Unlocked {
+		t.Error("unexpected wallets", wallets, err)
+	}
+	if err = d.Stop(); err != nil {
+		t.Error(err)
+	}
+	select {
+	case <-d.done:
+	default:
+		t.Error("keosd did not exit")
+	}
+
+	// cancelling the context stops keosd too
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	d2 := &KeosDaemon{Binary: os.Args[0], WalletDir: dir, SocketPath: filepath.Join(dir, "other.sock")}
+	if err = d2.Start(ctx2); err != nil {
+		t.Error(err)
+		cancel2()
+		return
+	}
+	cancel2()
+	done := make(chan error)
+	go func() { done <- d2.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Error("keosd was not stopped when the context was cancelled")
+	}
+}
@@ -0,0 +1,175 @@
+package fiox
+
+import (
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the number of close words offered for a typo
+const maxSuggestions = 3
+
+// WordError describes a word that is not in the wordlist, Position starts at 1
+type WordError struct {
+	Position    int
+	Word        string
+	Suggestions []string
+}
+
+// MnemonicError explains why a mnemonic is invalid, it is returned by ValidateMnemonic and NewHdFromString
+type MnemonicError struct {
+	Language     Language // the wordlist that matched the most words, empty if none did
+	WordCount    int
+	BadLength    bool
+	UnknownWords []WordError
+	BadChecksum  bool
+}
+
+func (e *MnemonicError) Error() string {
+	problems := make([]string, 0)
+	if e.BadLength {
+		problems = append(problems, fmt.Sprintf("has %d words, should be 12, 15, 18, 21, or 24", e.WordCount))
+	}
+	for _, w := range e.UnknownWords {
+		p := fmt.Sprintf("word %d %q is not in the wordlist", w.Position, w.Word)
+		if len(w.Suggestions) > 0 {
+			p += " (did you mean " + strings.Join(w.Suggestions, ", ") + "?)"
+		}
+		problems = append(problems, p)
+	}
+	if e.BadChecksum {
+		problems = append(problems, "checksum is invalid, a word may be wrong or out of order")
+	}
+	return "mnemonic is invalid: " + strings.Join(problems, "; ")
+}
+
+// Is allows errors.Is to match the sentinel errors for each problem found, such as ErrUnknownWord
+func (e *MnemonicError) Is(target error) bool {
+	switch target {
+	case ErrMnemonicTooShort, ErrMnemonicTooLong, ErrMnemonicLength:
+		return e.BadLength && mnemonicLengthError(e.WordCount) == target
+	case ErrUnknownWord:
+		return len(e.UnknownWords) > 0
+	case ErrInvalidChecksum:
+		return e.BadChecksum
+	}
+	return false
+}
+
+// ValidateMnemonic checks a mnemonic phrase, returning nil if it is valid or a *MnemonicError describing which
+// words are unknown (with suggestions for typos), if the length is wrong, or if the checksum fails.
+func ValidateMnemonic(mnemonic string) error {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	e := &MnemonicError{WordCount: len(words)}
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		e.BadLength = true
+	}
+
+	var best *wordList
+	e.Language, best = bestWordList(words)
+	for i, w := range words {
+		if _, ok := best.index[w]; !ok {
+			e.UnknownWords = append(e.UnknownWords, WordError{
+				Position:    i + 1,
+				Word:        norm.NFC.String(w),
+				Suggestions: suggestWords(w, best),
+			})
+		}
+	}
+	if e.BadLength || len(e.UnknownWords) > 0 {
+		return e
+	}
+	if _, _, _, err := detectWordList(words); err != nil {
+		e.BadChecksum = true
+		return e
+	}
+	return nil
+}
+
+// bestWordList picks the list containing the most words, English is used if no words are known
+func bestWordList(words []string) (Language, *wordList) {
+	var best *wordList
+	var bestLanguage Language
+	bestCount := 0
+	for _, language := range languageOrder {
+		wl, err := getWordList(language)
+		if err != nil {
+			continue
+		}
+		count := 0
+		for _, w := range words {
+			if _, ok := wl.index[w]; ok {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount, bestLanguage = wl, count, language
+		}
+	}
+	if best == nil {
+		best, _ = getWordList(LangEnglish)
+	}
+	return bestLanguage, best
+}
+
+// suggestWords finds the closest words in the list, BIP39 lists are designed so the first four letters are unique
+// so a prefix match is preferred.
+func suggestWords(word string, wl *wordList) []string {
+	type candidate struct {
+		word     string
+		distance int
+	}
+	w := []rune(word)
+	candidates := make([]candidate, 0)
+	for _, listed := range wl.words {
+		l := []rune(norm.NFKD.String(listed))
+		if len(w) >= 4 && len(l) >= 4 && string(w[:4]) == string(l[:4]) {
+			candidates = append(candidates, candidate{listed, 0})
+			continue
+		}
+		if d := levenshtein(w, l); d <= 2 {
+			candidates = append(candidates, candidate{listed, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	result := make([]string, 0)
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		result = append(result, candidates[i].word)
+	}
+	return result
+}
+
+func levenshtein(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
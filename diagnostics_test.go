@@ -0,0 +1,32 @@
+package fiox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateMnemonic(t *testing.T) {
+	if err := ValidateMnemonic("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage"); err != nil {
+		t.Error(err)
+	}
+
+	_, err := NewHdFromString("crater husbnd angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	me := &MnemonicError{}
+	if !errors.As(err, &me) {
+		t.Error("expected a MnemonicError, got", err)
+		return
+	}
+	if me.Language != LangEnglish || len(me.UnknownWords) != 1 || me.UnknownWords[0].Position != 2 ||
+		len(me.UnknownWords[0].Suggestions) == 0 || me.UnknownWords[0].Suggestions[0] != "husband" {
+		t.Errorf("unexpected diagnostics %+v", me)
+	}
+
+	err = ValidateMnemonic("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic aerobic")
+	if !errors.As(err, &me) || !me.BadChecksum || len(me.UnknownWords) != 0 {
+		t.Error("expected a checksum failure, got", err)
+	}
+	err = ValidateMnemonic("crater husband angle")
+	if !errors.As(err, &me) || !me.BadLength || me.WordCount != 3 {
+		t.Error("expected a length failure, got", err)
+	}
+}
@@ -0,0 +1,84 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// DefaultGapLimit is the number of consecutive unused keys most wallets check before stopping discovery
+const DefaultGapLimit = 20
+
+// KeyUsed reports whether a public key has been used, see FioKeyUsed
+type KeyUsed func(pub *ecc.PublicKey) (bool, error)
+
+// Discover walks the derivation indexes from zero, checking each public key with used, and stops after gap
+// consecutive unused keys. It returns the active indexes in order. This works for watch-only Hds, and WithWorkers
+// can be used to check a batch of keys concurrently.
+func (hd Hd) Discover(gap int, used KeyUsed) ([]int, error) {
+	if gap < 1 {
+		return nil, errors.New("gap must be at least 1")
+	}
+	if used == nil {
+		return nil, errors.New("used cannot be nil")
+	}
+	active := make([]int, 0)
+	batch := gap
+	if hd.workers > batch {
+		batch = hd.workers
+	}
+	unused := 0
+	for start := 0; unused < gap; start += batch {
+		pubs, err := hd.PubKeysRange(start, batch)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]bool, batch)
+		err = forRange(hd.workers, batch, func(i int) (err error) {
+			results[i], err = used(pubs[i])
+			return
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, ok := range results {
+			if ok {
+				active = append(active, start+i)
+				unused = 0
+				continue
+			}
+			if unused++; unused >= gap {
+				break
+			}
+		}
+	}
+	return active, nil
+}
+
+// FioKeyUsed provides a KeyUsed that considers a key active if it has registered FIO names or a balance
+func FioKeyUsed(api *fio.API) KeyUsed {
+	return func(pub *ecc.PublicKey) (bool, error) {
+		_, found, err := api.GetFioNames(pub.String())
+		if err != nil {
+			return false, err
+		}
+		if found {
+			return true, nil
+		}
+		balance, err := fioBalance(api, pub.String())
+		if err != nil {
+			return false, err
+		}
+		return balance > 0, nil
+	}
+}
+
+// fioBalance provides the balance of a key, see GetFioBalance
+func fioBalance(api *fio.API, pub string) (uint64, error) {
+	balance, err := GetFioBalance(context.Background(), api, pub)
+	if err != nil {
+		return 0, err
+	}
+	return balance.Balance, nil
+}
@@ -0,0 +1,40 @@
+package fiox
+
+import (
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+func TestHd_Discover(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, err := hd.PubKeys(26)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	usedKeys := map[string]bool{pubs[0].String(): true, pubs[3].String(): true, pubs[25].String(): true}
+	used := func(pub *ecc.PublicKey) (bool, error) {
+		return usedKeys[pub.String()], nil
+	}
+
+	active, err := hd.Discover(5, used)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(active) != 2 || active[0] != 0 || active[1] != 3 {
+		t.Error("unexpected active indexes with a gap of 5:", active)
+	}
+	active, err = hd.WithWorkers(4).Discover(DefaultGapLimit+2, used)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(active) != 3 || active[2] != 25 {
+		t.Error("unexpected active indexes with a gap of 22:", active)
+	}
+}
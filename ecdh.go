@@ -0,0 +1,40 @@
+package fiox
+
+import (
+	"crypto/sha512"
+	"errors"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// SharedSecret derives the ECDH secret between a private key and a counterparty's public key, using the same layout
+// as fio.js (and eosjs-ecc getSharedSecret): the sha512 hash of the X coordinate of the shared point. The result is
+// 64 bytes, and is the same when the roles are reversed.
+func SharedSecret(priv *ecc.PrivateKey, pub *ecc.PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve != ecc.CurveK1 {
+		return nil, errors.New("expected a K1 public key")
+	}
+	raw, err := rawPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(raw)
+	point, err := btcec.ParsePubKey(pub.Content, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	x, _ := btcec.S256().ScalarMult(point.X, point.Y, raw)
+	shared := padScalar(x)
+	defer wipe(shared)
+	secret := sha512.Sum512(shared)
+	return secret[:], nil
+}
+
+// SharedSecretAt derives the ECDH secret between the key at index and a counterparty's public key, see SharedSecret
+func (hd Hd) SharedSecretAt(index int, counterparty *ecc.PublicKey) ([]byte, error) {
+	priv, err := hd.keyAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return SharedSecret(priv, counterparty)
+}
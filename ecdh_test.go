@@ -0,0 +1,38 @@
+package fiox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSharedSecret(t *testing.T) {
+	alice, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	bob, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	alicePub, _ := alice.PubKeyAt(0)
+	bobPub, _ := bob.PubKeyAt(2)
+	a, err := alice.SharedSecretAt(0, bobPub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	b, err := bob.SharedSecretAt(2, alicePub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(a) != 64 || !bytes.Equal(a, b) {
+		t.Error("shared secrets did not match")
+	}
+	c, _ := bob.SharedSecretAt(3, alicePub)
+	if bytes.Equal(a, c) {
+		t.Error("different keys produced the same secret")
+	}
+}
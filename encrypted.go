@@ -0,0 +1,136 @@
+package fiox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"golang.org/x/crypto/scrypt"
+	"strings"
+)
+
+const (
+	encryptedVersion = 1
+	encryptedSaltLen = 16
+	encryptedSeedLen = 64
+
+	// scrypt parameters recommended for interactive logins, roughly 100ms on a modern CPU
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrDecrypt is returned by NewHdFromEncrypted when the passphrase is wrong or the data has been modified
+var ErrDecrypt = errors.New("could not decrypt, invalid passphrase or corrupted data")
+
+// ExportEncrypted encrypts the mnemonic and seed with a key derived from passphrase using scrypt, and AES-256-GCM.
+// The seed is included so that a BIP39 passphrase used to create the Hd is not needed to restore it with
+// NewHdFromEncrypted. The output is binary: version || salt || nonce || ciphertext.
+func (hd Hd) ExportEncrypted(passphrase string) ([]byte, error) {
+	return hd.exportEncrypted(passphrase, nil)
+}
+
+// exportEncrypted also authenticates extra, which is not stored and must be provided again to decrypt
+func (hd Hd) exportEncrypted(passphrase string, extra []byte) ([]byte, error) {
+	if err := hd.canDerive(); err != nil {
+		return nil, err
+	}
+	if hd.Len() == 0 || len(hd.seed) != encryptedSeedLen {
+		return nil, errors.New("Hd does not have a mnemonic")
+	}
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	salt := make([]byte, encryptedSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := encryptedCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// plaintext is seed || language || 0x00 || mnemonic
+	plain := make([]byte, 0, encryptedSeedLen+len(hd.language)+1+len(hd.Reveal()))
+	plain = append(plain, hd.seed...)
+	plain = append(plain, []byte(hd.language)...)
+	plain = append(plain, 0)
+	plain = append(plain, []byte(strings.Join(hd.words, " "))...)
+	defer wipe(plain)
+
+	header := append([]byte{encryptedVersion}, salt...)
+	header = append(header, nonce...)
+	return gcm.Seal(header, nonce, plain, append(header[:1:1], extra...)), nil
+}
+
+// NewHdFromEncrypted restores a Hd that was saved with ExportEncrypted
+func NewHdFromEncrypted(blob []byte, passphrase string) (*Hd, error) {
+	return newHdFromEncrypted(blob, passphrase, nil)
+}
+
+func newHdFromEncrypted(blob []byte, passphrase string, extra []byte) (*Hd, error) {
+	if len(blob) < 1 || blob[0] != encryptedVersion {
+		return nil, errors.New("unsupported encrypted data version")
+	}
+	if len(blob) < 1+encryptedSaltLen {
+		return nil, ErrDecrypt
+	}
+	gcm, err := encryptedCipher(passphrase, blob[1:1+encryptedSaltLen])
+	if err != nil {
+		return nil, err
+	}
+	offset := 1 + encryptedSaltLen + gcm.NonceSize()
+	if len(blob) < offset+gcm.Overhead() {
+		return nil, ErrDecrypt
+	}
+	plain, err := gcm.Open(nil, blob[1+encryptedSaltLen:offset], blob[offset:], append(blob[:1:1], extra...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	defer wipe(plain)
+	if len(plain) < encryptedSeedLen {
+		return nil, ErrDecrypt
+	}
+	sep := bytes.IndexByte(plain[encryptedSeedLen:], 0)
+	if sep < 0 {
+		return nil, ErrDecrypt
+	}
+	language := Language(plain[encryptedSeedLen : encryptedSeedLen+sep])
+	words := strings.Split(string(plain[encryptedSeedLen+sep+1:]), " ")
+	if _, err = getWordList(language); err != nil {
+		return nil, err
+	}
+
+	hd, err := newHdFromSeed(plain[:encryptedSeedLen])
+	if err != nil {
+		return nil, err
+	}
+	hd.words = words
+	hd.language = language
+	return hd, nil
+}
+
+func encryptedCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// wipe overwrites a buffer holding secrets
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
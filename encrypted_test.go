@@ -0,0 +1,44 @@
+package fiox
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestHd_ExportEncrypted(t *testing.T) {
+	hd, err := NewHdFromStringWithPassphrase("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage", "extra")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	blob, err := hd.ExportEncrypted("correct horse battery staple")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restored, err := NewHdFromEncrypted(blob, "correct horse battery staple")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if restored.String() != hd.String() {
+		t.Error("mnemonic did not round trip")
+	}
+	a, _ := hd.Seed()
+	b, _ := restored.Seed()
+	if !bytes.Equal(a, b) {
+		t.Error("seed did not round trip")
+	}
+
+	if _, err = NewHdFromEncrypted(blob, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Error("expected ErrDecrypt with the wrong passphrase, got", err)
+	}
+	blob[len(blob)-1] ^= 1
+	if _, err = NewHdFromEncrypted(blob, "correct horse battery staple"); !errors.Is(err, ErrDecrypt) {
+		t.Error("expected ErrDecrypt for modified data, got", err)
+	}
+	if _, err = NewHdFromEncrypted(blob[:10], "correct horse battery staple"); err == nil {
+		t.Error("truncated data was accepted")
+	}
+}
@@ -0,0 +1,103 @@
+package fiox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NewHdFromReader builds a new Hd using entropy read from source instead of the system random number generator.
+// Only the bytes needed for the word count are read. The reader must provide high quality randomness, see
+// EntropyFromDice and EntropyFromCoins for converting physical randomness.
+func NewHdFromReader(words int, source io.Reader, language Language, passphrase string) (*Hd, error) {
+	bits, err := entropyBits(words)
+	if err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, bits/8)
+	if _, err = io.ReadFull(source, entropy); err != nil {
+		return nil, fmt.Errorf("could not read %d bytes of entropy: %v", bits/8, err)
+	}
+	return NewHdFromEntropy(entropy, language, passphrase)
+}
+
+// EntropyFromDice converts rolls of a die with the given number of sides (values 1 through sides) into the entropy
+// needed for a mnemonic with the word count. Rolls that would cause a modulo bias are discarded, so more rolls may
+// be needed than the raw entropy suggests; an error reports how many are missing.
+func EntropyFromDice(rolls []int, sides int, words int) ([]byte, error) {
+	if sides < 2 || sides > 256 {
+		return nil, errors.New("dice must have between 2 and 256 sides")
+	}
+	bits, err := entropyBits(words)
+	if err != nil {
+		return nil, err
+	}
+	// use the largest power of two that fits in the number of sides, and reject higher values
+	usable := uint(0)
+	for 1<<(usable+1) <= sides {
+		usable++
+	}
+	out := newBitWriter(bits)
+	for i, r := range rolls {
+		if r < 1 || r > sides {
+			return nil, fmt.Errorf("roll %d has invalid value %d for a %d sided die", i+1, r, sides)
+		}
+		v := r - 1
+		if v >= 1<<usable {
+			continue
+		}
+		for b := int(usable) - 1; b >= 0 && !out.full(); b-- {
+			out.write(v>>uint(b)&1 == 1)
+		}
+		if out.full() {
+			return out.bytes(), nil
+		}
+	}
+	perRoll := float64(usable) * float64(int(1)<<usable) / float64(sides)
+	return nil, fmt.Errorf("not enough rolls, need about %d more", int(float64(bits-out.n)/perRoll)+1)
+}
+
+// EntropyFromCoins converts coin flips (true for heads) into the entropy needed for a mnemonic with the word count.
+// Flips are used in pairs to remove bias from an unfair coin (von Neumann), so roughly four flips are needed for
+// every bit of entropy; an error reports when more are needed.
+func EntropyFromCoins(flips []bool, words int) ([]byte, error) {
+	bits, err := entropyBits(words)
+	if err != nil {
+		return nil, err
+	}
+	out := newBitWriter(bits)
+	for i := 0; i+1 < len(flips) && !out.full(); i += 2 {
+		if flips[i] != flips[i+1] {
+			out.write(flips[i])
+		}
+	}
+	if !out.full() {
+		return nil, fmt.Errorf("not enough coin flips, need about %d more", (bits-out.n)*4)
+	}
+	return out.bytes(), nil
+}
+
+// bitWriter collects a fixed number of bits
+type bitWriter struct {
+	buf []byte
+	n   int
+}
+
+func newBitWriter(bits int) *bitWriter {
+	return &bitWriter{buf: make([]byte, bits/8)}
+}
+
+func (w *bitWriter) write(bit bool) {
+	if bit {
+		w.buf[w.n/8] |= 0x80 >> uint(w.n%8)
+	}
+	w.n++
+}
+
+func (w *bitWriter) full() bool {
+	return w.n == len(w.buf)*8
+}
+
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
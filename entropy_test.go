@@ -0,0 +1,72 @@
+package fiox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewHdFromReader(t *testing.T) {
+	source := bytes.NewReader(bytes.Repeat([]byte{0x7f}, 64))
+	hd, err := NewHdFromReader(12, source, LangEnglish, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if hd.String() != "legal winner thank year wave sausage worth useful legal winner thank yellow" {
+		t.Error("mnemonic did not match entropy from reader:", hd.String())
+	}
+	if source.Len() != 48 {
+		t.Error("read more entropy than needed")
+	}
+	if _, err = NewHdFromReader(24, bytes.NewReader([]byte{1, 2, 3}), LangEnglish, ""); err == nil {
+		t.Error("allowed a short read")
+	}
+}
+
+func TestEntropyFromDice(t *testing.T) {
+	// a d6 provides two bits for rolls 1-4, and 5 or 6 are rejected
+	rolls := make([]int, 0)
+	for i := 0; i < 64; i++ {
+		rolls = append(rolls, 2, 5, 4, 6) // 01 11 -> 0x7 per pair, rejected rolls in between
+	}
+	entropy, err := EntropyFromDice(rolls, 6, 12)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(entropy, bytes.Repeat([]byte{0x77}, 16)) {
+		t.Errorf("unexpected entropy %x", entropy)
+	}
+	if _, err = EntropyFromDice(rolls[:10], 6, 12); err == nil {
+		t.Error("allowed too few rolls")
+	}
+	if _, err = EntropyFromDice([]int{7}, 6, 12); err == nil {
+		t.Error("allowed an invalid roll")
+	}
+	d16 := make([]int, 32)
+	for i := range d16 {
+		d16[i] = 16
+	}
+	if entropy, err = EntropyFromDice(d16, 16, 12); err != nil || !bytes.Equal(entropy, bytes.Repeat([]byte{0xff}, 16)) {
+		t.Error("d16 should provide four bits per roll", err)
+	}
+}
+
+func TestEntropyFromCoins(t *testing.T) {
+	flips := make([]bool, 0)
+	for i := 0; i < 128; i++ {
+		// heads,tails -> 1, tails,heads -> 0, matching pairs are discarded
+		flips = append(flips, true, true, i%2 == 0, i%2 != 0)
+	}
+	entropy, err := EntropyFromCoins(flips, 12)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(entropy, bytes.Repeat([]byte{0xaa}, 16)) {
+		t.Errorf("unexpected entropy %x", entropy)
+	}
+	if _, err = EntropyFromCoins(flips[:100], 12); err == nil {
+		t.Error("allowed too few flips")
+	}
+}
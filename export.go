@@ -0,0 +1,173 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"golang.org/x/crypto/ripemd160"
+	"strings"
+)
+
+var (
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1   = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// sec1PrivateKey is the ASN.1 ECPrivateKey structure from RFC 5915
+type sec1PrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkixPublicKey is the ASN.1 SubjectPublicKeyInfo structure from RFC 5480
+type pkixPublicKey struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.ObjectIdentifier
+	}
+	PublicKey asn1.BitString
+}
+
+// PrivateKeyHex provides the 32 byte private key as hex
+func PrivateKeyHex(key *ecc.PrivateKey) (string, error) {
+	raw, err := rawPrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(raw)
+	return hex.EncodeToString(raw), nil
+}
+
+// PrivateKeyK1 provides the private key in the newer PVT_K1_ string format
+func PrivateKeyK1(key *ecc.PrivateKey) (string, error) {
+	raw, err := rawPrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(raw)
+	return "PVT_K1_" + checksumEncode(raw, "K1"), nil
+}
+
+// PublicKeyK1 provides the public key in the newer PUB_K1_ string format
+func PublicKeyK1(pub *ecc.PublicKey) (string, error) {
+	if pub == nil || pub.Curve != ecc.CurveK1 || len(pub.Content) != 33 {
+		return "", errors.New("expected a compressed K1 public key")
+	}
+	return "PUB_K1_" + checksumEncode(pub.Content, "K1"), nil
+}
+
+// publicKeyContent decodes a public key in the FIO, PUB_K1_, or PUB_R1_ format. fio-go leaves "K1" out of the
+// PUB_K1_ checksum, so those keys are decoded here rather than with ecc.NewPublicKey.
+func publicKeyContent(pub string) ([]byte, error) {
+	if strings.HasPrefix(pub, "PUB_K1_") {
+		return checksumDecode(strings.TrimPrefix(pub, "PUB_K1_"), "K1")
+	}
+	key, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return key.Content, nil
+}
+
+// PrivateKeyDER provides the private key as an ASN.1 DER encoded SEC1 (RFC 5915) structure
+func PrivateKeyDER(key *ecc.PrivateKey) ([]byte, error) {
+	raw, err := rawPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(raw)
+	pub := key.PublicKey()
+	point, err := btcec.ParsePubKey(pub.Content, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	uncompressed := point.SerializeUncompressed()
+	return asn1.Marshal(sec1PrivateKey{
+		Version:       1,
+		PrivateKey:    raw,
+		NamedCurveOID: oidSecp256k1,
+		PublicKey:     asn1.BitString{Bytes: uncompressed, BitLength: len(uncompressed) * 8},
+	})
+}
+
+// PrivateKeyPEM provides the SEC1 private key as an "EC PRIVATE KEY" PEM block, compatible with openssl
+func PrivateKeyPEM(key *ecc.PrivateKey) ([]byte, error) {
+	der, err := PrivateKeyDER(key)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(der)
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKeyDER provides the public key as an ASN.1 DER encoded SubjectPublicKeyInfo
+func PublicKeyDER(pub *ecc.PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve != ecc.CurveK1 {
+		return nil, errors.New("expected a K1 public key")
+	}
+	point, err := btcec.ParsePubKey(pub.Content, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	uncompressed := point.SerializeUncompressed()
+	info := pkixPublicKey{PublicKey: asn1.BitString{Bytes: uncompressed, BitLength: len(uncompressed) * 8}}
+	info.Algorithm.Algorithm = oidECPublicKey
+	info.Algorithm.Parameters = oidSecp256k1
+	return asn1.Marshal(info)
+}
+
+// PublicKeyPEM provides the public key as a "PUBLIC KEY" PEM block
+func PublicKeyPEM(pub *ecc.PublicKey) ([]byte, error) {
+	der, err := PublicKeyDER(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// rawPrivateKey is the 32 byte private scalar, callers should wipe it when finished
+func rawPrivateKey(key *ecc.PrivateKey) ([]byte, error) {
+	if key == nil {
+		return nil, errors.New("key cannot be nil")
+	}
+	if key.PublicKey().Curve != ecc.CurveK1 {
+		return nil, errors.New("only K1 keys are supported")
+	}
+	wif, err := btcutil.DecodeWIF(key.String())
+	if err != nil {
+		return nil, err
+	}
+	return padScalar(wif.PrivKey.D), nil
+}
+
+// checksumEncode is base58(data || ripemd160(data || curve)[:4]), used by the PVT_ and PUB_ formats
+func checksumEncode(data []byte, curve string) string {
+	h := ripemd160.New()
+	_, _ = h.Write(data)
+	_, _ = h.Write([]byte(curve))
+	return base58.Encode(append(append([]byte{}, data...), h.Sum(nil)[:4]...))
+}
+
+// checksumDecode reverses checksumEncode, returning an error if the checksum does not match
+func checksumDecode(s string, curve string) ([]byte, error) {
+	decoded := base58.Decode(s)
+	if len(decoded) < 5 {
+		return nil, errors.New("invalid key encoding")
+	}
+	data := decoded[:len(decoded)-4]
+	h := ripemd160.New()
+	_, _ = h.Write(data)
+	_, _ = h.Write([]byte(curve))
+	if !bytes.Equal(h.Sum(nil)[:4], decoded[len(decoded)-4:]) {
+		return nil, errors.New("invalid key checksum")
+	}
+	return data, nil
+}
@@ -0,0 +1,68 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/pem"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+func TestKeyExport(t *testing.T) {
+	key, err := ecc.NewPrivateKey("5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k1, err := PrivateKeyK1(key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k1 != "PVT_K1_2bfGi9rYsXQSXXTvJbDAPhHLQUojjaNLomdm3cEJ1XTzMqUt3V" {
+		t.Error("PVT_K1_ format mismatch", k1)
+	}
+	pub := key.PublicKey()
+	pubK1, err := PublicKeyK1(&pub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if pubK1 != "PUB_K1_6MRyAjQq8ud7hVNYcfnVPJqcVpscN5So8BhtHuGYqET5BoDq63" {
+		t.Error("PUB_K1_ format mismatch", pubK1)
+	}
+	h, err := PrivateKeyHex(key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if h != "d2653ff7cbb2d8ff129ac27ef5781ce68b2558c41a74af1f2ddca635cbeef07d" {
+		t.Error("hex mismatch", h)
+	}
+
+	der, err := PrivateKeyDER(key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p, err := PrivateKeyPEM(key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	block, _ := pem.Decode(p)
+	if block == nil || block.Type != "EC PRIVATE KEY" || !bytes.Equal(block.Bytes, der) {
+		t.Error("PEM did not contain the DER key")
+	}
+	// the SEC1 structure starts with version 1 followed by the 32 byte key
+	if !bytes.HasPrefix(der[2:], []byte{2, 1, 1, 4, 32}) {
+		t.Error("unexpected SEC1 encoding")
+	}
+	pubDer, err := PublicKeyDER(&pub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(pubDer) != 88 {
+		t.Error("unexpected SubjectPublicKeyInfo length", len(pubDer))
+	}
+}
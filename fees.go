@@ -0,0 +1,149 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"sync"
+	"time"
+)
+
+// DefaultFeeTTL is how long FeeCache uses a fee before querying get_fee again, fees only change when the block
+// producers vote on them so this can be long
+const DefaultFeeTTL = 10 * time.Minute
+
+// FeeCache queries get_fee for the max_fee of FIO actions and remembers the results, so sending many transactions
+// does not mean a get_fee request for each one. Fees are cached per endpoint and FIO address, since get_fee
+// returns zero for an address with bundled transactions left.
+type FeeCache struct {
+	api *fio.API
+	ttl time.Duration
+
+	mux  sync.Mutex
+	fees map[feeKey]cachedFee
+	now  func() time.Time
+}
+
+type feeKey struct {
+	endpoint   string
+	fioAddress string
+}
+
+type cachedFee struct {
+	fee     uint64
+	fetched time.Time
+}
+
+// NewFeeCache caches fees from api for ttl, DefaultFeeTTL is used when ttl is zero
+func NewFeeCache(api *fio.API, ttl time.Duration) (*FeeCache, error) {
+	if api == nil {
+		return nil, errors.New("api cannot be nil")
+	}
+	if ttl < 0 {
+		return nil, errors.New("ttl cannot be negative")
+	}
+	if ttl == 0 {
+		ttl = DefaultFeeTTL
+	}
+	return &FeeCache{api: api, ttl: ttl, fees: make(map[feeKey]cachedFee), now: time.Now}, nil
+}
+
+// FeeFor provides the fee in SUFs for an endpoint such as "transfer_tokens_pub_key" or "add_pub_address".
+// fioAddress is the address paying for the action, it may be empty for endpoints that are not bundle eligible.
+func (fc *FeeCache) FeeFor(ctx context.Context, endpoint string, fioAddress string) (uint64, error) {
+	key := feeKey{endpoint: endpoint, fioAddress: fioAddress}
+	fc.mux.Lock()
+	cached, ok := fc.fees[key]
+	fc.mux.Unlock()
+	if ok && fc.now().Sub(cached.fetched) < fc.ttl {
+		return cached.fee, nil
+	}
+	return fc.fetch(ctx, key)
+}
+
+// Invalidate forgets the cached fees of a FIO address, such as after it uses its last bundled transaction, or
+// every cached fee when fioAddress is empty
+func (fc *FeeCache) Invalidate(fioAddress string) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+	if fioAddress == "" {
+		fc.fees = make(map[feeKey]cachedFee)
+		return
+	}
+	for key := range fc.fees {
+		if key.fioAddress == fioAddress {
+			delete(fc.fees, key)
+		}
+	}
+}
+
+// Refresh queries get_fee again for every cached fee and for endpoints, which are fetched without an address. The
+// first error is returned after trying them all.
+func (fc *FeeCache) Refresh(ctx context.Context, endpoints ...string) error {
+	keys := make(map[feeKey]bool)
+	fc.mux.Lock()
+	for key := range fc.fees {
+		keys[key] = true
+	}
+	fc.mux.Unlock()
+	for _, endpoint := range endpoints {
+		keys[feeKey{endpoint: endpoint}] = true
+	}
+	var first error
+	for key := range keys {
+		if _, err := fc.fetch(ctx, key); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// RefreshEvery starts refreshing the cache in the background every interval, so FeeFor rarely has to wait on
+// nodeos. endpoints are fetched right away. It stops when ctx is cancelled, and the returned channel is closed
+// once it has stopped.
+func (fc *FeeCache) RefreshEvery(ctx context.Context, interval time.Duration, endpoints ...string) (<-chan struct{}, error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// errors keep the previous fees, they are retried on the next tick
+		_ = fc.Refresh(ctx, endpoints...)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = fc.Refresh(ctx, endpoints...)
+			}
+		}
+	}()
+	return done, nil
+}
+
+func (fc *FeeCache) fetch(ctx context.Context, key feeKey) (uint64, error) {
+	if key.endpoint == "" {
+		return 0, errors.New("endpoint is required")
+	}
+	resp := struct {
+		Fee uint64 `json:"fee"`
+	}{}
+	found, err := chainPost(ctx, fc.api, "/v1/chain/get_fee", map[string]string{
+		"end_point":   key.endpoint,
+		"fio_address": key.fioAddress,
+	}, &resp)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("no fee found for %s", key.endpoint)
+	}
+	fc.mux.Lock()
+	fc.fees[key] = cachedFee{fee: resp.Fee, fetched: fc.now()}
+	fc.mux.Unlock()
+	return resp.Fee, nil
+}
@@ -0,0 +1,90 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFeeCache(t *testing.T) {
+	var queries int32
+	fee := uint64(2_000_000_000)
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/v1/chain/get_fee" || req["end_point"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&queries, 1)
+		if req["fio_address"] == "bundled@fiotestnet" {
+			_, _ = w.Write([]byte(`{"fee":0}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]uint64{"fee": fee})
+	}))
+	defer nodeos.Close()
+
+	fc, err := NewFeeCache(&fio.API{API: eos.API{BaseURL: nodeos.URL}}, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	now := time.Now()
+	fc.now = func() time.Time { return now }
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if f, err := fc.FeeFor(ctx, "transfer_tokens_pub_key", ""); err != nil || f != fee {
+			t.Error("unexpected fee", f, err)
+		}
+	}
+	if f, err := fc.FeeFor(ctx, "add_pub_address", "bundled@fiotestnet"); err != nil || f != 0 {
+		t.Error("expected a bundled transaction to be free", f, err)
+	}
+	if q := atomic.LoadInt32(&queries); q != 2 {
+		t.Error("expected fees to be cached, got queries:", q)
+	}
+
+	now = now.Add(DefaultFeeTTL)
+	if _, err = fc.FeeFor(ctx, "transfer_tokens_pub_key", ""); err != nil || atomic.LoadInt32(&queries) != 3 {
+		t.Error("expected an expired fee to be queried again", err)
+	}
+	fc.Invalidate("bundled@fiotestnet")
+	if _, err = fc.FeeFor(ctx, "add_pub_address", "bundled@fiotestnet"); err != nil || atomic.LoadInt32(&queries) != 4 {
+		t.Error("expected an invalidated fee to be queried again", err)
+	}
+	if _, err = fc.FeeFor(ctx, "", ""); err == nil {
+		t.Error("expected an endpoint to be required")
+	}
+
+	fee = 3_000_000_000
+	if err = fc.Refresh(ctx, "register_fio_address"); err != nil {
+		t.Error(err)
+	}
+	if atomic.LoadInt32(&queries) != 7 {
+		t.Error("expected the cached fees and the new endpoint to be refreshed, got queries:", queries)
+	}
+	if f, _ := fc.FeeFor(ctx, "transfer_tokens_pub_key", ""); f != fee {
+		t.Error("expected the refreshed fee, got", f)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done, err := fc.RefreshEvery(ctx, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("refresh did not stop")
+	}
+}
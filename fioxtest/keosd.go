@@ -0,0 +1,429 @@
+// Package fioxtest provides a fake keosd for testing code that uses fiox.KeosClient without running keosd.
+package fioxtest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/blockpane/fio-extras"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+)
+
+// Keosd is an in-memory keosd served by an httptest.Server. It supports the wallet endpoints used by KeosClient and
+// returns errors in the same format as keosd. Close it when finished.
+type Keosd struct {
+	*httptest.Server
+	Socket string // set when serving on a unix socket
+
+	mux     sync.Mutex
+	wallets map[string]*wallet
+}
+
+type wallet struct {
+	password string
+	unlocked bool
+	keys     map[string]*ecc.PrivateKey // by FIO public key
+}
+
+// NewKeosd starts a fake keosd listening on a loopback address, use the URL field or Client to connect
+func NewKeosd() *Keosd {
+	k := &Keosd{wallets: make(map[string]*wallet)}
+	k.Server = httptest.NewServer(http.HandlerFunc(k.serve))
+	return k
+}
+
+// NewKeosdSocket starts a fake keosd listening on a unix socket, the socket file must not exist
+func NewKeosdSocket(socket string) (*Keosd, error) {
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	k := &Keosd{Socket: socket, wallets: make(map[string]*wallet)}
+	k.Server = &httptest.Server{Listener: l, Config: &http.Server{Handler: http.HandlerFunc(k.serve)}}
+	k.Server.Start()
+	return k, nil
+}
+
+// Client provides a KeosClient connected to the fake keosd
+func (k *Keosd) Client(opts ...fiox.KeosOption) *fiox.KeosClient {
+	if k.Socket != "" {
+		return fiox.NewKeosClient("", k.Socket, opts...)
+	}
+	return fiox.NewKeosClient(k.URL, "", opts...)
+}
+
+// AddWallet creates a locked wallet holding the WIF keys, replacing any wallet with the same name
+func (k *Keosd) AddWallet(name string, password string, wifs ...string) error {
+	w := &wallet{password: password, keys: make(map[string]*ecc.PrivateKey)}
+	for _, wif := range wifs {
+		key, err := ecc.NewPrivateKey(wif)
+		if err != nil {
+			return err
+		}
+		w.keys[key.PublicKey().String()] = key
+	}
+	k.mux.Lock()
+	k.wallets[name] = w
+	k.mux.Unlock()
+	return nil
+}
+
+// keosdError is an exception as keosd reports it, the names are what fiox maps to its sentinel errors
+type keosdError struct {
+	code int
+	name string
+	what string
+}
+
+func (e keosdError) Error() string {
+	return e.what
+}
+
+var (
+	errWalletExists     = keosdError{3120001, "wallet_exist_exception", "Wallet already exists"}
+	errWalletNotFound   = keosdError{3120002, "wallet_nonexistent_exception", "Nonexistent wallet"}
+	errWalletLocked     = keosdError{3120003, "wallet_locked_exception", "Locked wallet"}
+	errMissingPubKey    = keosdError{3120004, "wallet_missing_pub_key_exception", "Missing public key"}
+	errInvalidPassword  = keosdError{3120005, "wallet_invalid_password_exception", "Invalid wallet password"}
+	errWalletUnlocked   = keosdError{3120007, "wallet_unlocked_exception", "Already unlocked"}
+	errKeyExists        = keosdError{3120008, "key_exist_exception", "Key already exists"}
+	errKeyNotFound      = keosdError{3120009, "key_nonexistent_exception", "Nonexistent key"}
+	errUnsupportedKey   = keosdError{3120010, "unsupported_key_type_exception", "Unsupported key type"}
+	errInvalidArguments = keosdError{3200006, "invalid_http_request", "invalid http request"}
+)
+
+func (k *Keosd) serve(w http.ResponseWriter, r *http.Request) {
+	params := make([]json.RawMessage, 0)
+	// some endpoints take a single string or nothing rather than an array
+	raw := json.RawMessage{}
+	if err := json.NewDecoder(r.Body).Decode(&raw); err == nil && len(raw) > 0 && raw[0] == '[' {
+		_ = json.Unmarshal(raw, &params)
+	} else if len(raw) > 0 {
+		params = append(params, raw)
+	}
+	k.mux.Lock()
+	result, err := k.handle(r.URL.Path, params)
+	k.mux.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		kErr, ok := err.(keosdError)
+		if !ok {
+			kErr = keosdError{errInvalidArguments.code, errInvalidArguments.name, err.Error()}
+		}
+		status := http.StatusInternalServerError
+		if kErr.code == errInvalidArguments.code {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":    status,
+			"message": http.StatusText(status),
+			"error": map[string]interface{}{
+				"code":    kErr.code,
+				"name":    kErr.name,
+				"what":    kErr.what,
+				"details": []interface{}{},
+			},
+		})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (k *Keosd) handle(path string, params []json.RawMessage) (interface{}, error) {
+	empty := struct{}{}
+	switch path {
+	case "/v1/wallet/create":
+		var name string
+		if err := decode(params, &name); err != nil {
+			return nil, err
+		}
+		if k.wallets[name] != nil {
+			return nil, errWalletExists
+		}
+		seed, err := ecc.NewRandomPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		password := "PW" + seed.String()
+		k.wallets[name] = &wallet{password: password, unlocked: true, keys: make(map[string]*ecc.PrivateKey)}
+		return password, nil
+
+	case "/v1/wallet/open":
+		var name string
+		if err := decode(params, &name); err != nil {
+			return nil, err
+		}
+		if k.wallets[name] == nil {
+			return nil, errWalletNotFound
+		}
+		return empty, nil
+
+	case "/v1/wallet/list_wallets":
+		names := make([]string, 0, len(k.wallets))
+		for name, wal := range k.wallets {
+			if wal.unlocked {
+				name += " *"
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+
+	case "/v1/wallet/unlock":
+		var name, password string
+		if err := decode(params, &name, &password); err != nil {
+			return nil, err
+		}
+		wal, err := k.wallet(name, false)
+		if err != nil {
+			return nil, err
+		}
+		if wal.unlocked {
+			return nil, errWalletUnlocked
+		}
+		if wal.password != password {
+			return nil, errInvalidPassword
+		}
+		wal.unlocked = true
+		return empty, nil
+
+	case "/v1/wallet/lock":
+		var name string
+		if err := decode(params, &name); err != nil {
+			return nil, err
+		}
+		wal, err := k.wallet(name, false)
+		if err != nil {
+			return nil, err
+		}
+		wal.unlocked = false
+		return empty, nil
+
+	case "/v1/wallet/lock_all":
+		for _, wal := range k.wallets {
+			wal.unlocked = false
+		}
+		return empty, nil
+
+	case "/v1/wallet/set_timeout":
+		var seconds int64
+		if err := decode(params, &seconds); err != nil {
+			return nil, err
+		}
+		return empty, nil
+
+	case "/v1/wallet/list_keys":
+		var name, password string
+		if err := decode(params, &name, &password); err != nil {
+			return nil, err
+		}
+		wal, err := k.wallet(name, true)
+		if err != nil {
+			return nil, err
+		}
+		if wal.password != password {
+			return nil, errInvalidPassword
+		}
+		keys := make([][]string, 0, len(wal.keys))
+		for pub, key := range wal.keys {
+			keys = append(keys, []string{pub, key.String()})
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i][0] < keys[j][0] })
+		return keys, nil
+
+	case "/v1/wallet/get_public_keys":
+		keys := make([]string, 0)
+		locked := true
+		for _, wal := range k.wallets {
+			if !wal.unlocked {
+				continue
+			}
+			locked = false
+			for pub := range wal.keys {
+				keys = append(keys, pub)
+			}
+		}
+		if locked {
+			return nil, errWalletLocked
+		}
+		sort.Strings(keys)
+		return keys, nil
+
+	case "/v1/wallet/import_key":
+		var name, wif string
+		if err := decode(params, &name, &wif); err != nil {
+			return nil, err
+		}
+		wal, err := k.wallet(name, true)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ecc.NewPrivateKey(wif)
+		if err != nil {
+			return nil, err
+		}
+		pub := key.PublicKey().String()
+		if wal.keys[pub] != nil {
+			return nil, errKeyExists
+		}
+		wal.keys[pub] = key
+		return empty, nil
+
+	case "/v1/wallet/create_key":
+		var name, keyType string
+		if err := decode(params, &name, &keyType); err != nil {
+			return nil, err
+		}
+		if keyType != "" && keyType != "K1" {
+			return nil, errUnsupportedKey
+		}
+		wal, err := k.wallet(name, true)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ecc.NewRandomPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		pub := key.PublicKey().String()
+		wal.keys[pub] = key
+		return pub, nil
+
+	case "/v1/wallet/remove_key":
+		var name, password, pub string
+		if err := decode(params, &name, &password, &pub); err != nil {
+			return nil, err
+		}
+		wal, err := k.wallet(name, true)
+		if err != nil {
+			return nil, err
+		}
+		if wal.password != password {
+			return nil, errInvalidPassword
+		}
+		pub = normalize(pub)
+		if wal.keys[pub] == nil {
+			return nil, errKeyNotFound
+		}
+		delete(wal.keys, pub)
+		return empty, nil
+
+	case "/v1/wallet/sign_digest":
+		var digest, pub string
+		if err := decode(params, &digest, &pub); err != nil {
+			return nil, err
+		}
+		hash, err := hex.DecodeString(digest)
+		if err != nil || len(hash) != 32 {
+			return nil, errInvalidArguments
+		}
+		key, err := k.unlockedKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := key.Sign(hash)
+		if err != nil {
+			return nil, err
+		}
+		return sig.String(), nil
+
+	case "/v1/wallet/sign_transaction":
+		var tx json.RawMessage
+		var pubs []string
+		var chainID string
+		if err := decode(params, &tx, &pubs, &chainID); err != nil {
+			return nil, err
+		}
+		return k.signTransaction(tx, pubs, chainID)
+	}
+	return nil, fmt.Errorf("unknown endpoint %s", path)
+}
+
+func (k *Keosd) signTransaction(tx json.RawMessage, pubs []string, chainID string) (interface{}, error) {
+	id, err := hex.DecodeString(chainID)
+	if err != nil || len(id) != 32 {
+		return nil, errInvalidArguments
+	}
+	packed, err := fiox.PackTransactionJSON(tx)
+	if err != nil {
+		return nil, err
+	}
+	signed := make(map[string]interface{})
+	if err = json.Unmarshal(tx, &signed); err != nil {
+		return nil, err
+	}
+	signatures := make([]string, 0)
+	if existing, ok := signed["signatures"].([]interface{}); ok {
+		for _, sig := range existing {
+			if s, ok := sig.(string); ok {
+				signatures = append(signatures, s)
+			}
+		}
+	}
+	digest := fiox.TransactionDigest(id, packed, nil)
+	for _, pub := range pubs {
+		key, err := k.unlockedKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := key.Sign(digest)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, sig.String())
+	}
+	signed["signatures"] = signatures
+	return signed, nil
+}
+
+// wallet finds a wallet by name, optionally requiring it to be unlocked
+func (k *Keosd) wallet(name string, unlocked bool) (*wallet, error) {
+	wal := k.wallets[name]
+	if wal == nil {
+		return nil, errWalletNotFound
+	}
+	if unlocked && !wal.unlocked {
+		return nil, errWalletLocked
+	}
+	return wal, nil
+}
+
+// unlockedKey finds a private key in any unlocked wallet
+func (k *Keosd) unlockedKey(pub string) (*ecc.PrivateKey, error) {
+	pub = normalize(pub)
+	for _, wal := range k.wallets {
+		if key := wal.keys[pub]; key != nil && wal.unlocked {
+			return key, nil
+		}
+	}
+	return nil, errMissingPubKey
+}
+
+// normalize converts a PUB_K1_ key to the FIO format used for lookups
+func normalize(pub string) string {
+	key, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return pub
+	}
+	return key.String()
+}
+
+// decode unmarshals positional parameters, every destination is required
+func decode(params []json.RawMessage, dest ...interface{}) error {
+	if len(params) < len(dest) {
+		return errInvalidArguments
+	}
+	for i := range dest {
+		if err := json.Unmarshal(params[i], dest[i]); err != nil {
+			return errors.New("invalid parameter: " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package fioxtest
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/blockpane/fio-extras"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testWif = "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
+
+func TestKeosd(t *testing.T) {
+	keosd := NewKeosd()
+	defer keosd.Close()
+	if err := keosd.AddWallet("default", "secret", testWif); err != nil {
+		t.Error(err)
+		return
+	}
+	k := keosd.Client()
+	ctx := context.Background()
+
+	if _, err := k.GetPublicKeys(ctx); !errors.Is(err, fiox.ErrWalletLocked) {
+		t.Error("expected the wallet to be locked, got", err)
+	}
+	if err := k.Unlock(ctx, "wrong", "default"); !errors.Is(err, fiox.ErrInvalidPassword) {
+		t.Error("expected an invalid password, got", err)
+	}
+	if err := k.Unlock(ctx, "secret", "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := k.GetKeys(ctx, nil); err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := ecc.NewPrivateKey(testWif)
+	pub := key.PublicKey().String()
+	if _, ok := k.KeyByPub(pub); !ok {
+		t.Error("did not find the imported key")
+	}
+	if err := k.ImportKey(ctx, "default", testWif); !errors.Is(err, fiox.ErrKeyExists) {
+		t.Error("expected the key to exist, got", err)
+	}
+
+	password, err := k.Create(ctx, "second")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	created, err := k.CreateKey(ctx, "second", "K1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err = k.RemoveKey(ctx, "second", password, created); err != nil {
+		t.Error(err)
+	}
+	wallets, err := k.ListWallets(ctx)
+	if err != nil || len(wallets) != 2 || !wallets[1].Unlocked {
+		t.Error("unexpected wallets", wallets, err)
+	}
+
+	digest := make([]byte, 32)
+	sig, err := k.SignDigest(ctx, digest, pub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !sig.Verify(digest, key.PublicKey()) {
+		t.Error("digest signature did not verify")
+	}
+
+	trx := []byte(`{"expiration":"2020-10-01T00:00:00","ref_block_num":1,"ref_block_prefix":2,"actions":[{"account":"eosio","name":"regproducer","authorization":[],"data":"0102ff"}]}`)
+	sigs, err := k.SignTransaction(ctx, json.RawMessage(trx), []string{pub}, fiox.FioTestnetChainID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	packed, _ := fiox.PackTransactionJSON(trx)
+	chainID, _ := hex.DecodeString(fiox.FioTestnetChainID)
+	if len(sigs) != 1 || !sigs[0].Verify(fiox.TransactionDigest(chainID, packed, nil), key.PublicKey()) {
+		t.Error("transaction signature did not verify")
+	}
+
+	if err = k.LockAll(ctx); err != nil {
+		t.Error(err)
+	}
+	if _, err = k.SignDigest(ctx, digest, pub); !errors.Is(err, fiox.ErrWalletMissingKey) {
+		t.Error("expected the key to be unavailable once locked, got", err)
+	}
+}
+
+func TestKeosdSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keosd")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	keosd, err := NewKeosdSocket(filepath.Join(dir, "keosd.sock"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer keosd.Close()
+	if err = keosd.AddWallet("default", "secret"); err != nil {
+		t.Error(err)
+		return
+	}
+	k := keosd.Client()
+	if err = k.Unlock(context.Background(), "secret", "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	unlocked, err := k.IsUnlocked(context.Background(), "default")
+	if err != nil || !unlocked {
+		t.Error("expected the wallet to be unlocked", err)
+	}
+}
+
+func TestKeosdSessions(t *testing.T) {
+	keosd := NewKeosd()
+	defer keosd.Close()
+	second := "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"
+	if err := keosd.AddWallet("default", "secret", testWif); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := keosd.AddWallet("cold", "other", second); err != nil {
+		t.Error(err)
+		return
+	}
+	k := keosd.Client()
+	ctx := context.Background()
+	for wallet, password := range map[string]string{"default": "secret", "cold": "other"} {
+		if err := k.Unlock(ctx, password, wallet); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	if sessions := k.Sessions(); len(sessions) != 2 || sessions[0] != "cold" {
+		t.Error("unexpected sessions", sessions)
+	}
+	for _, wallet := range []string{"default", "cold"} {
+		if err := k.GetWalletKeys(ctx, wallet, nil); err != nil {
+			t.Error(err)
+			return
+		}
+		if len(k.WalletKeyList(wallet)) != 1 {
+			t.Error("expected one key in", wallet)
+		}
+	}
+	if k.KeyCount() != 2 {
+		t.Error("expected the keys from both wallets, got", k.KeyCount())
+	}
+
+	key, _ := ecc.NewPrivateKey(testWif)
+	signer, err := fiox.NewKeosSigner(k)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	cold := signer.ForWallet("cold")
+	available, err := cold.AvailableKeys()
+	if err != nil || len(available) != 1 || available[0].String() == key.PublicKey().String() {
+		t.Error("expected only the cold wallet key", available, err)
+	}
+
+	if err = k.Lock(ctx, "cold"); err != nil {
+		t.Error(err)
+	}
+	if sessions := k.Sessions(); len(sessions) != 1 || sessions[0] != "default" {
+		t.Error("expected locking to end the session", sessions)
+	}
+}
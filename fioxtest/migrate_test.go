@@ -0,0 +1,75 @@
+package fioxtest
+
+import (
+	"context"
+	"github.com/blockpane/fio-extras"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMigrateKeys(t *testing.T) {
+	keosd := NewKeosd()
+	defer keosd.Close()
+	second := "5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf"
+	if err := keosd.AddWallet("default", "secret", testWif, second); err != nil {
+		t.Error(err)
+		return
+	}
+	dir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	local, err := fiox.NewLocalWallet(dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	password, err := local.Create(ctx, "migrated")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err = local.ImportKey(ctx, "migrated", second); err != nil {
+		t.Error(err)
+		return
+	}
+
+	from := fiox.MigrationWallet{Backend: keosd.Client(), Name: "default", Password: "secret"}
+	to := fiox.MigrationWallet{Backend: local, Name: "migrated", Password: password}
+	report, err := fiox.MigrateKeys(ctx, from, to, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(report.Imported) != 1 || len(report.Skipped) != 1 {
+		t.Error("unexpected dry run", report)
+	}
+	if pubs, _ := local.GetPublicKeys(ctx); len(pubs) != 1 {
+		t.Error("a dry run should not import anything")
+	}
+
+	if report, err = fiox.MigrateKeys(ctx, from, to, false); err != nil {
+		t.Error(err)
+		return
+	}
+	if report.String() != "imported 1 keys, skipped 1 already present, 0 invalid" {
+		t.Error("unexpected report:", report)
+	}
+	if pubs, _ := local.GetPublicKeys(ctx); len(pubs) != 2 {
+		t.Error("expected both keys in the local wallet")
+	}
+
+	// and back again into a new keosd wallet
+	if err = keosd.AddWallet("restored", "other"); err != nil {
+		t.Error(err)
+		return
+	}
+	back := fiox.MigrationWallet{Backend: keosd.Client(), Name: "restored", Password: "other"}
+	if report, err = fiox.MigrateKeys(ctx, to, back, false); err != nil || len(report.Imported) != 2 {
+		t.Error("unexpected migration back to keosd", report, err)
+	}
+}
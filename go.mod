@@ -12,8 +12,8 @@ require (
 	github.com/cmars/basen v0.0.0-20150613233007-fe3947df716e // indirect
 	github.com/ethereum/go-ethereum v1.9.16
 	github.com/fioprotocol/fio-go v1.0.0
-	github.com/mitchellh/go-ps v1.0.0
-	github.com/tyler-smith/go-bip32 v0.0.0-20170922074101-2c9cfd177564
-	github.com/tyler-smith/go-bip39 v1.0.2
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	golang.org/x/text v0.3.2
 	launchpad.net/gocheck v0.0.0-20140225173054-000000000087 // indirect
 )
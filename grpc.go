@@ -0,0 +1,183 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// The signing service is small enough that its protobuf messages are encoded by hand rather than pulling in the
+// grpc and protobuf modules, cmd/fioxd/fioxd.proto describes them for other clients.
+
+// gRPC status codes used by the signing service
+const (
+	grpcOK               = 0
+	grpcInvalidArgument  = 3
+	grpcNotFound         = 5
+	grpcPermissionDenied = 7
+	grpcFailedCondition  = 9
+	grpcUnimplemented    = 12
+	grpcInternal         = 13
+)
+
+// protobuf wire types
+const (
+	protoVarint = 0
+	protoBytes  = 2
+)
+
+// protoMessage builds an encoded protobuf message, zero values are skipped as proto3 does
+type protoMessage struct {
+	bytes.Buffer
+}
+
+func (m *protoMessage) key(field int, wire int) {
+	m.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (m *protoMessage) varint(v uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	m.Write(buf[:binary.PutUvarint(buf, v)])
+}
+
+func (m *protoMessage) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	m.key(field, protoVarint)
+	m.varint(v)
+}
+
+func (m *protoMessage) bytes(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	m.key(field, protoBytes)
+	m.varint(uint64(len(b)))
+	m.Write(b)
+}
+
+func (m *protoMessage) string(field int, s string) {
+	m.bytes(field, []byte(s))
+}
+
+// repeated strings are written even when empty so their position in the list is kept
+func (m *protoMessage) strings(field int, values []string) {
+	for _, s := range values {
+		m.key(field, protoBytes)
+		m.varint(uint64(len(s)))
+		m.WriteString(s)
+	}
+}
+
+// protoFields calls fn for each field of an encoded message, data holds length delimited values and v varints
+func protoFields(b []byte, fn func(field int, v uint64, data []byte) error) error {
+	r := bytes.NewReader(b)
+	for r.Len() > 0 {
+		key, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		field := int(key >> 3)
+		switch key & 7 {
+		case protoVarint:
+			var v uint64
+			if v, err = binary.ReadUvarint(r); err != nil {
+				return err
+			}
+			err = fn(field, v, nil)
+		case protoBytes:
+			var l uint64
+			if l, err = binary.ReadUvarint(r); err != nil {
+				return err
+			}
+			if l > uint64(r.Len()) {
+				return errors.New("invalid length in protobuf message")
+			}
+			data := make([]byte, l)
+			_, _ = io.ReadFull(r, data)
+			err = fn(field, 0, data)
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grpcFrame prefixes a message with the uncompressed flag and its length
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// grpcUnframe reads the single message of a unary call
+func grpcUnframe(b []byte) ([]byte, error) {
+	if len(b) < 5 {
+		return nil, errors.New("grpc message is too short")
+	}
+	if b[0] != 0 {
+		return nil, errors.New("compressed grpc messages are not supported")
+	}
+	l := binary.BigEndian.Uint32(b[1:5])
+	if uint64(l) != uint64(len(b)-5) {
+		return nil, errors.New("grpc message length does not match")
+	}
+	return b[5:], nil
+}
+
+// GRPCError is a failed call to the signing service
+type GRPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *GRPCError) Error() string {
+	return fmt.Sprintf("grpc error %d: %s", e.Code, e.Message)
+}
+
+// Is matches the status codes the signing service uses for the package's sentinel errors
+func (e *GRPCError) Is(target error) bool {
+	switch e.Code {
+	case grpcNotFound:
+		return target == ErrWalletMissingKey
+	case grpcPermissionDenied:
+		return target == ErrPolicyDenied
+	}
+	return false
+}
+
+// grpcStatus reads the status trailers, or the headers of a trailers-only response
+func grpcStatus(trailer map[string][]string, header map[string][]string) error {
+	get := func(name string) string {
+		for _, h := range []map[string][]string{trailer, header} {
+			if v := h[name]; len(v) > 0 {
+				return v[0]
+			}
+		}
+		return ""
+	}
+	status := get("Grpc-Status")
+	if status == "" {
+		return errors.New("grpc response is missing the status")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return fmt.Errorf("invalid grpc status %q", status)
+	}
+	if code == grpcOK {
+		return nil
+	}
+	msg, err := url.PathUnescape(get("Grpc-Message"))
+	if err != nil {
+		msg = get("Grpc-Message")
+	}
+	return &GRPCError{Code: code, Message: msg}
+}
@@ -0,0 +1,44 @@
+package fiox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProtoFields(t *testing.T) {
+	m := &protoMessage{}
+	m.uint(1, 300)
+	m.string(2, "fio")
+	m.strings(3, []string{"", "b"})
+
+	var seen []int
+	err := protoFields(m.Bytes(), func(field int, v uint64, data []byte) error {
+		seen = append(seen, field)
+		if (field == 1 && v != 300) || (field == 2 && string(data) != "fio") {
+			t.Error("unexpected value for field", field, v, data)
+		}
+		return nil
+	})
+	if err != nil || len(seen) != 4 || seen[3] != 3 {
+		t.Error("unexpected fields", seen, err)
+	}
+
+	// errors from the callback stop decoding, for both wire types
+	stop := errors.New("stop")
+	for _, field := range []int{1, 2} {
+		calls := 0
+		err = protoFields(m.Bytes(), func(f int, v uint64, data []byte) error {
+			calls++
+			if f == field {
+				return stop
+			}
+			return nil
+		})
+		if !errors.Is(err, stop) || calls != field {
+			t.Error("expected the callback error to be returned", field, calls, err)
+		}
+	}
+	if err = protoFields([]byte{0x0a, 0x05, 'a'}, func(int, uint64, []byte) error { return nil }); err == nil {
+		t.Error("expected a truncated message to fail")
+	}
+}
@@ -1,130 +1,322 @@
 package fiox
 
 import (
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	hdwallet "github.com/blockpane/fio-extras/internal/go-ethereum-hdwallet"
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/fioprotocol/fio-go"
 	"github.com/fioprotocol/fio-go/eos"
 	"github.com/fioprotocol/fio-go/eos/ecc"
-	"github.com/tyler-smith/go-bip32"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/text/unicode/norm"
 	mrand "math/rand" // #nosec
 	"strings"
+	"sync"
 	"time"
 )
 
 // Hd is an HD Wallet with BIP39 mnemonic phrase based on a BIP32 derivation path. Note: FIO uses m/44'/235'/0
 type Hd struct {
 	words  []string
-	wallet *hdwallet.Wallet
+	seed   []byte                  // locked in memory when possible, see Zeroize
+	locked bool                    // seed was successfully locked
+	master *hdkeychain.ExtendedKey // bip32 root key, nil for watch-only
+
+	language Language
+	redact   bool
+
+	account int // account and change used for building the path m/44'/235'/account'/change/index
+	change  int
+	workers int // number of goroutines used by the Keys and PubKeys functions, see WithWorkers
+
+	quizSeed     int64 // only used if quizSeeded, see WithQuizSeed
+	quizSeeded   bool
+	quizAttempts int // incorrect answers allowed per question, see WithQuizAttempts
+
+	xpub *hdkeychain.ExtendedKey // account level public key, only set for watch-only
+
+	nodes *nodeCache // derived m/44'/235'/account'/change nodes, shared by copies of the Hd
 }
 
+// nodeCache avoids re-deriving the hardened part of the path for every key
+type nodeCache struct {
+	sync.Mutex
+	nodes map[[2]int]*hdkeychain.ExtendedKey
+}
+
+func newNodeCache() *nodeCache {
+	return &nodeCache{nodes: make(map[[2]int]*hdkeychain.ExtendedKey)}
+}
+
+// ErrWatchOnly is returned when a private key is requested from a Hd that was created from an extended public key
+var ErrWatchOnly = errors.New("watch-only Hd does not hold private keys")
+
+// ErrZeroized is returned when a Hd is used after Zeroize or Close
+var ErrZeroized = errors.New("Hd key material has been zeroized")
+
 // NewHdFromString verifies a mnemonic string and creates a Hd containing a HD Wallet
 func NewHdFromString(mnemonic string) (*Hd, error) {
-	mn := strings.Split(mnemonic, " ")
+	return NewHdFromStringWithPassphrase(mnemonic, "")
+}
+
+// NewHdFromStringWithPassphrase verifies a mnemonic string and creates a Hd using a BIP39 passphrase (sometimes
+// called the "25th word") when deriving the seed. An empty passphrase gives the same result as NewHdFromString.
+// The language of the mnemonic is detected automatically, see DetectLanguage.
+func NewHdFromStringWithPassphrase(mnemonic string, passphrase string) (*Hd, error) {
+	// normalizing also converts the ideographic spaces used in Japanese phrases
+	mn := strings.Split(norm.NFKD.String(mnemonic), " ")
 	switch len(mn) {
 	case 12, 15, 18, 21, 24:
 		for _, w := range mn {
 			if w == "" {
-				return nil, errors.New("malformed mnemonic, had empty word")
+				return nil, ErrEmptyWord
 			}
 		}
 		break
 	default:
-		return nil, errors.New("mnemonic length should be 12, 15, 18, 21, or 24 words")
+		return nil, mnemonicLengthError(len(mn))
 	}
-	var result Hd
-	var err error
-	result.wallet, err = hdwallet.NewFromMnemonic(mnemonic)
+	language, wl, _, err := detectWordList(mn)
 	if err != nil {
+		if verr := ValidateMnemonic(mnemonic); verr != nil {
+			return nil, verr
+		}
 		return nil, err
 	}
-	result.words = make([]string, len(mn))
+	words := make([]string, len(mn))
 	for i := range mn {
-		result.words[i] = mn[i]
+		words[i] = wl.words[wl.index[mn[i]]]
+	}
+	result, err := newHdFromSeed(mnemonicSeed(words, passphrase))
+	if err != nil {
+		return nil, err
+	}
+	result.words = words
+	result.language = language
+	return result, nil
+}
+
+// newHdFromSeed creates a Hd that does not have a mnemonic
+func newHdFromSeed(seed []byte) (*Hd, error) {
+	var result Hd
+	var err error
+	result.words = make([]string, 0)
+	result.seed = make([]byte, len(seed))
+	copy(result.seed, seed)
+	result.locked = lockMemory(result.seed)
+	result.nodes = newNodeCache()
+	result.master, err = hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
 	}
 	return &result, nil
 }
 
 // NewRandomHd builds a new Hd with a specific word count (12, 15, 18, 21, or 24,) longer is better
 func NewRandomHd(words int) (*Hd, error) {
-	var bits int
+	return NewRandomHdWithPassphrase(words, "")
+}
+
+// NewRandomHdWithPassphrase builds a new Hd with a specific word count, and uses a BIP39 passphrase for deriving
+// the seed. The passphrase is not stored, and will be required along with the mnemonic to recover the keys.
+func NewRandomHdWithPassphrase(words int, passphrase string) (*Hd, error) {
+	return NewRandomHdInLanguage(words, LangEnglish, passphrase)
+}
+
+// NewRandomHdInLanguage builds a new Hd with a mnemonic using the wordlist for a language, the passphrase
+// may be empty.
+func NewRandomHdInLanguage(words int, language Language, passphrase string) (*Hd, error) {
+	bits, err := entropyBits(words)
+	if err != nil {
+		return nil, err
+	}
+	// confirmed as using crypto/rand not math:
+	entropy, err := hdwallet.NewEntropy(bits)
+	if err != nil {
+		return nil, err
+	}
+	return NewHdFromEntropy(entropy, language, passphrase)
+}
+
+// entropyBits is the amount of entropy encoded by a mnemonic with a given word count
+func entropyBits(words int) (int, error) {
 	switch words {
 	case 24:
-		bits = 256
+		return 256, nil
 	case 21:
-		bits = 224
+		return 224, nil
 	case 18:
-		bits = 192
+		return 192, nil
 	case 15:
-		bits = 160
+		return 160, nil
 	case 12:
-		bits = 128
-	default:
-		return nil, errors.New("word count must be 12, 15, 18, 21, or 24")
+		return 128, nil
 	}
-	// confirmed as using crypto/rand not math:
-	phrase, err := hdwallet.NewMnemonic(bits)
+	return 0, fmt.Errorf("%w, got %d", mnemonicLengthError(words), words)
+}
+
+// NewHdFromEntropy builds a Hd from raw entropy (16, 20, 24, 28, or 32 bytes) such as dice rolls or output from
+// an HSM. The mnemonic uses the wordlist for language, and the passphrase may be empty.
+func NewHdFromEntropy(entropy []byte, language Language, passphrase string) (*Hd, error) {
+	wl, err := getWordList(language)
+	if err != nil {
+		return nil, err
+	}
+	phrase, err := entropyToMnemonic(entropy, wl)
+	if err != nil {
+		return nil, err
+	}
+	return NewHdFromStringWithPassphrase(joinWords(phrase, language), passphrase)
+}
+
+// Entropy provides the raw entropy encoded by the mnemonic
+func (hd Hd) Entropy() ([]byte, error) {
+	if hd.zeroized() {
+		return nil, ErrZeroized
+	}
+	if hd.Len() == 0 {
+		return nil, errors.New("Hd does not have a mnemonic")
+	}
+	wl, err := getWordList(hd.language)
 	if err != nil {
 		return nil, err
 	}
-	return NewHdFromString(phrase)
+	return mnemonicToEntropy(hd.words, wl)
+}
+
+// Seed provides a copy of the 64 byte BIP39 seed, which includes the passphrase if one was used
+func (hd Hd) Seed() ([]byte, error) {
+	if hd.zeroized() {
+		return nil, ErrZeroized
+	}
+	if len(hd.seed) == 0 {
+		return nil, errors.New("Hd does not have a seed")
+	}
+	seed := make([]byte, len(hd.seed))
+	copy(seed, hd.seed)
+	return seed, nil
 }
 
 // Xpriv is the bip32 root key as a string, this may not import for bip44 compatible wallets,
 // that is a planned addition.
 func (hd Hd) Xpriv() (string, error) {
-	key, err := bip32.NewMasterKey(hd.wallet.Seed)
-	if err != nil {
+	if err := hd.canDerive(); err != nil {
 		return "", err
 	}
-	return key.String(), nil
+	return hd.master.String(), nil
 }
 
 // Xpub is the bip32 root public key as a string, some wallets will expect a bip44 xpub or the
 // bip32 derivation xpub key, these are planned additions.
 func (hd Hd) Xpub() (string, error) {
-	key, err := bip32.NewMasterKey(hd.wallet.Seed)
+	if err := hd.canDerive(); err != nil {
+		return "", err
+	}
+	key, err := hd.master.Neuter()
 	if err != nil {
 		return "", err
 	}
-	return key.PublicKey().String(), nil
+	return key.String(), nil
 }
 
 func (hd Hd) Len() int {
 	return len(hd.words)
 }
 
+// String provides the mnemonic phrase, unless the Hd was created with Redacted
 func (hd Hd) String() string {
-	return strings.Join(hd.words[:], " ")
+	if hd.redact {
+		fp, err := hd.Fingerprint()
+		if err != nil {
+			return "[redacted]"
+		}
+		return "[redacted " + fp + "]"
+	}
+	return hd.Reveal()
+}
+
+// Reveal provides the mnemonic phrase, even if the Hd was created with Redacted
+func (hd Hd) Reveal() string {
+	return joinWords(hd.words, hd.language)
+}
+
+// Redacted returns a copy of the Hd where String prints the fingerprint instead of the mnemonic, useful for avoiding
+// leaks in logs. Use Reveal to get the mnemonic.
+func (hd Hd) Redacted() *Hd {
+	hd.redact = true
+	return &hd
+}
+
+// GoString is used for the %#v format, it never includes secrets
+func (hd Hd) GoString() string {
+	fp, err := hd.Fingerprint()
+	if err != nil {
+		fp = "unavailable"
+	}
+	return fmt.Sprintf("fiox.Hd{Fingerprint: %q, Language: %q, Account: %d, Change: %d, WatchOnly: %v}",
+		fp, hd.language, hd.account, hd.change, hd.WatchOnly())
+}
+
+// Fingerprint is the BIP32 key fingerprint of the root key as hex, or of the account key if watch-only. It is safe
+// to display, and identifies which wallet is in use.
+func (hd Hd) Fingerprint() (string, error) {
+	if hd.zeroized() {
+		return "", ErrZeroized
+	}
+	key := hd.master
+	if hd.WatchOnly() {
+		key = hd.xpub
+	}
+	pub, err := key.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(btcutil.Hash160(pub.SerializeCompressed())[:4]), nil
+}
+
+// Language is the wordlist used by the mnemonic, it is empty if the Hd was not created from a mnemonic
+func (hd Hd) Language() Language {
+	return hd.language
 }
 
 // Keys provides a keybag with the requested number of keys, use KeyAt for a single key
 func (hd Hd) Keys(keys int) (*eos.KeyBag, error) {
-	if keys < 1 {
+	return hd.KeysRange(0, keys)
+}
+
+// KeysRange provides a keybag holding count keys beginning at index start, allowing large wallets to be paged
+func (hd Hd) KeysRange(start int, count int) (*eos.KeyBag, error) {
+	if count < 1 {
 		return nil, errors.New("cannot derive 0 keys")
 	}
+	if err := checkIndexRange(start, count); err != nil {
+		return nil, err
+	}
 	keybag := &eos.KeyBag{}
-	keybag.Keys = make([]*ecc.PrivateKey, 0)
-	for i := 0; i < keys; i++ {
-		k, err := keyAt(hd.wallet, i)
-		if err != nil {
-			return nil, err
-		}
-		keybag.Keys = append(keybag.Keys, k)
+	keybag.Keys = make([]*ecc.PrivateKey, count)
+	err := forRange(hd.workers, count, func(i int) (err error) {
+		keybag.Keys[i], err = hd.keyAt(start + i)
+		return
+	})
+	if err != nil {
+		return nil, err
 	}
 	return keybag, nil
 }
 
-// KeyAt creates a keybag holding a single key at m/44'/235'/0'/0/index
+// KeyAt creates a keybag holding a single key at m/44'/235'/account'/change/index, by default m/44'/235'/0'/0/index
 func (hd Hd) KeyAt(index int) (*eos.KeyBag, error) {
 	keybag := &eos.KeyBag{}
 	keybag.Keys = make([]*ecc.PrivateKey, 1)
 	var err error
-	keybag.Keys[0], err = keyAt(hd.wallet, index)
+	keybag.Keys[0], err = hd.keyAt(index)
 	if err != nil {
 		return nil, err
 	}
@@ -133,56 +325,375 @@ func (hd Hd) KeyAt(index int) (*eos.KeyBag, error) {
 
 // PubKeys derives a number of public keys for the Hd
 func (hd Hd) PubKeys(count int) ([]*ecc.PublicKey, error) {
+	return hd.PubKeysRange(0, count)
+}
+
+// PubKeysRange derives count public keys beginning at index start
+func (hd Hd) PubKeysRange(start int, count int) ([]*ecc.PublicKey, error) {
 	if count < 1 {
 		return nil, errors.New("cannot derive 0 public keys")
 	}
-	privs, err := hd.Keys(count)
+	if err := checkIndexRange(start, count); err != nil {
+		return nil, err
+	}
+	pks := make([]*ecc.PublicKey, count)
+	err := forRange(hd.workers, count, func(i int) (err error) {
+		pks[i], err = hd.pubKeyAt(start + i)
+		return
+	})
 	if err != nil {
 		return nil, err
 	}
-	pks := make([]*ecc.PublicKey, 0)
-	for _, priv := range privs.Keys {
-		pk, err := ecc.NewPublicKey("FIO" + priv.PublicKey().String()[3:])
-		if err != nil {
+	return pks, nil
+}
+
+// PubKeyAt derives a public key at a specific location - by default m/44'/235'/0'/0/index
+func (hd Hd) PubKeyAt(index int) (*ecc.PublicKey, error) {
+	return hd.pubKeyAt(index)
+}
+
+// ActorAt provides the FIO account name (actor) for the public key at index
+func (hd Hd) ActorAt(index int) (eos.AccountName, error) {
+	pub, err := hd.PubKeyAt(index)
+	if err != nil {
+		return "", err
+	}
+	return fio.ActorFromPub(pub.String())
+}
+
+// Actors provides the FIO account names for the first count public keys
+func (hd Hd) Actors(count int) ([]eos.AccountName, error) {
+	pubs, err := hd.PubKeys(count)
+	if err != nil {
+		return nil, err
+	}
+	actors := make([]eos.AccountName, len(pubs))
+	for i := range pubs {
+		if actors[i], err = fio.ActorFromPub(pubs[i].String()); err != nil {
 			return nil, err
 		}
-		pks = append(pks, &pk)
 	}
-	return pks, nil
+	return actors, nil
 }
 
-// PubKeyAt derives a public key at a specific location - m/44'/235'/0'/0/index
-func (hd Hd) PubKeyAt(index int) (*ecc.PublicKey, error) {
-	if index < 0 {
-		return nil, errors.New("index must not be negative")
+func (hd Hd) pubKeyAt(index int) (*ecc.PublicKey, error) {
+	if err := checkIndex(index); err != nil {
+		return nil, err
+	}
+	if hd.zeroized() {
+		return nil, ErrZeroized
+	}
+	if hd.WatchOnly() {
+		change, err := hd.changeNode()
+		if err != nil {
+			return nil, err
+		}
+		child, err := change.Child(uint32(index))
+		if err != nil {
+			return nil, err
+		}
+		pub, err := child.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		return fioPubKey(pub)
 	}
-	priv, err := hd.KeyAt(index)
+	priv, err := hd.keyAt(index)
 	if err != nil {
 		return nil, err
 	}
-	pk, err := ecc.NewPublicKey("FIO" + priv.Keys[0].PublicKey().String()[3:])
+	pk, err := ecc.NewPublicKey("FIO" + priv.PublicKey().String()[3:])
 	if err != nil {
 		return nil, err
 	}
 	return &pk, nil
 }
 
-func keyAt(wallet *hdwallet.Wallet, index int) (*ecc.PrivateKey, error) {
-	path, err := hdwallet.ParseDerivationPath(fmt.Sprintf("m/44'/235'/0'/0/%d", index))
+// fioPubKey encodes a secp256k1 public key using the FIO prefixed (legacy EOS) format
+func fioPubKey(pub *btcec.PublicKey) (*ecc.PublicKey, error) {
+	compressed := pub.SerializeCompressed()
+	h := ripemd160.New()
+	_, _ = h.Write(compressed)
+	pk, err := ecc.NewPublicKey("FIO" + base58.Encode(append(compressed, h.Sum(nil)[:4]...)))
 	if err != nil {
 		return nil, err
 	}
-	account, err := wallet.Derive(path, false)
+	return &pk, nil
+}
+
+// NewHdFromXpub creates a watch-only Hd from an account level extended public key (m/44'/235'/account',) such as
+// the one provided by AccountXpub. Only public keys can be derived, methods needing a private key return ErrWatchOnly.
+func NewHdFromXpub(xpub string) (*Hd, error) {
+	key, err := hdkeychain.NewKeyFromString(xpub)
 	if err != nil {
 		return nil, err
 	}
-	priv, err := wallet.PrivateKey(account)
+	if key.IsPrivate() {
+		return nil, errors.New("expected an extended public key, got an extended private key")
+	}
+	return &Hd{
+		words: make([]string, 0),
+		xpub:  key,
+		nodes: newNodeCache(),
+	}, nil
+}
+
+// AccountXpub is the extended public key at m/44'/235'/account', it can be used with NewHdFromXpub to derive
+// public keys without access to the seed.
+func (hd Hd) AccountXpub() (string, error) {
+	if hd.zeroized() {
+		return "", ErrZeroized
+	}
+	if hd.WatchOnly() {
+		return hd.xpub.String(), nil
+	}
+	var err error
+	key := hd.master
+	for _, i := range []int{44, 235, hd.account} {
+		key, err = key.Child(hdkeychain.HardenedKeyStart + uint32(i))
+		if err != nil {
+			return "", err
+		}
+	}
+	pub, err := key.Neuter()
+	if err != nil {
+		return "", err
+	}
+	return pub.String(), nil
+}
+
+// WatchOnly is true if the Hd was created from an extended public key and cannot provide private keys
+func (hd Hd) WatchOnly() bool {
+	return hd.xpub != nil
+}
+
+// Zeroize overwrites the seed, extended keys, and mnemonic words held by the Hd, after which any method needing
+// key material returns ErrZeroized. Copies made with WithAccount or Redacted share the same memory and are also
+// wiped. Go strings cannot be overwritten, so a mnemonic previously returned by String may remain in memory.
+func (hd Hd) Zeroize() {
+	wipe(hd.seed)
+	if hd.locked {
+		unlockMemory(hd.seed)
+	}
+	if hd.master != nil {
+		hd.master.Zero()
+	}
+	if hd.xpub != nil {
+		hd.xpub.Zero()
+	}
+	if hd.nodes != nil {
+		hd.nodes.Lock()
+		for k, node := range hd.nodes.nodes {
+			node.Zero()
+			delete(hd.nodes.nodes, k)
+		}
+		hd.nodes.Unlock()
+	}
+	for i := range hd.words {
+		hd.words[i] = ""
+	}
+}
+
+// Close satisfies io.Closer, it calls Zeroize and never returns an error
+func (hd Hd) Close() error {
+	hd.Zeroize()
+	return nil
+}
+
+// zeroized is true once Zeroize has been called on the Hd or one of its copies
+func (hd Hd) zeroized() bool {
+	switch {
+	case hd.xpub != nil:
+		_, err := hd.xpub.ECPubKey()
+		return err != nil
+	case hd.master != nil:
+		return !hd.master.IsPrivate()
+	}
+	return true
+}
+
+// canDerive checks that private keys are available
+func (hd Hd) canDerive() error {
+	if hd.zeroized() {
+		return ErrZeroized
+	}
+	if hd.WatchOnly() {
+		return ErrWatchOnly
+	}
+	return nil
+}
+
+// WithAccount returns a copy of the Hd that derives keys at m/44'/235'/account'/change/index instead of the default
+// account and change of zero. This affects Keys, KeyAt, PubKeys, and PubKeyAt.
+func (hd Hd) WithAccount(account int, change int) (*Hd, error) {
+	if account < 0 || change < 0 {
+		return nil, errors.New("account and change must not be negative")
+	}
+	if hd.WatchOnly() && account != hd.account {
+		return nil, errors.New("watch-only Hd can only use the account of its extended public key")
+	}
+	hd.account = account
+	hd.change = change
+	return &hd, nil
+}
+
+// WithWorkers returns a copy of the Hd that uses up to workers goroutines when deriving multiple keys with Keys,
+// KeysRange, PubKeys, and PubKeysRange. Results are always in index order. A value less than 2 derives serially.
+func (hd Hd) WithWorkers(workers int) *Hd {
+	hd.workers = workers
+	return &hd
+}
+
+// forRange calls f for 0 through count-1, using up to workers goroutines when it is more than 1. If any calls fail the
+// error for the lowest position is returned.
+func forRange(workers int, count int, f func(i int) error) error {
+	if workers > count {
+		workers = count
+	}
+	if workers < 2 {
+		for i := 0; i < count; i++ {
+			if err := f(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, count)
+	next := make(chan int)
+	failed := make(chan struct{})
+	var once sync.Once
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if errs[i] = f(i); errs[i] != nil {
+					once.Do(func() { close(failed) })
+				}
+			}
+		}()
+	}
+feed:
+	for i := 0; i < count; i++ {
+		select {
+		case next <- i:
+		case <-failed:
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PathAt provides the derivation path that will be used for a key at index
+func (hd Hd) PathAt(index int) string {
+	return fmt.Sprintf("m/44'/235'/%d'/%d/%d", hd.account, hd.change, index)
+}
+
+// Derive returns the private key at an arbitrary BIP32 path, for example "m/44'/235'/0'/0/0". Hardened segments
+// are denoted with an apostrophe.
+func (hd Hd) Derive(path string) (*ecc.PrivateKey, error) {
+	if err := hd.canDerive(); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(path, "m/") {
+		return nil, errors.New("derivation path must be absolute, starting with 'm/'")
+	}
+	return deriveKey(hd.master, path)
+}
+
+// maxIndex is the highest non-hardened child index, anything above it would quietly derive a hardened key
+const maxIndex = 1<<31 - 1
+
+// checkIndex rejects indexes that cannot be the last, non-hardened, step of a derivation path
+func checkIndex(index int) error {
+	if index < 0 {
+		return errors.New("index must not be negative")
+	}
+	if index > maxIndex {
+		return fmt.Errorf("index must not be more than %d", maxIndex)
+	}
+	return nil
+}
+
+// checkIndexRange is checkIndex for count indexes beginning at start
+func checkIndexRange(start int, count int) error {
+	if err := checkIndex(start); err != nil {
+		return err
+	}
+	if int64(start)+int64(count)-1 > maxIndex {
+		return fmt.Errorf("index must not be more than %d", maxIndex)
+	}
+	return nil
+}
+
+func (hd Hd) keyAt(index int) (*ecc.PrivateKey, error) {
+	if err := checkIndex(index); err != nil {
+		return nil, err
+	}
+	if err := hd.canDerive(); err != nil {
+		return nil, err
+	}
+	change, err := hd.changeNode()
+	if err != nil {
+		return nil, err
+	}
+	key, err := change.Child(uint32(index))
 	if err != nil {
 		return nil, err
 	}
+	return eccPrivateKey(key)
+}
 
-	btcPriv := btcec.PrivateKey(*priv)
-	wif, err := btcutil.NewWIF(&btcPriv, &chaincfg.MainNetParams, false)
+// changeNode provides the extended key at m/44'/235'/account'/change, or the change child of the xpub if watch-only.
+// Nodes are cached so that only the final, non-hardened step is needed for each key.
+func (hd Hd) changeNode() (*hdkeychain.ExtendedKey, error) {
+	if hd.nodes != nil {
+		hd.nodes.Lock()
+		defer hd.nodes.Unlock()
+		if node := hd.nodes.nodes[[2]int{hd.account, hd.change}]; node != nil {
+			return node, nil
+		}
+	}
+	var node *hdkeychain.ExtendedKey
+	var err error
+	if hd.WatchOnly() {
+		node, err = hd.xpub.Child(uint32(hd.change))
+	} else {
+		node, err = deriveExtended(hd.master, fmt.Sprintf("m/44'/235'/%d'/%d", hd.account, hd.change))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hd.nodes != nil {
+		hd.nodes.nodes[[2]int{hd.account, hd.change}] = node
+	}
+	return node, nil
+}
+
+func deriveKey(master *hdkeychain.ExtendedKey, derivationPath string) (*ecc.PrivateKey, error) {
+	key, err := deriveExtended(master, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return eccPrivateKey(key)
+}
+
+// eccPrivateKey converts an extended private key to a FIO key
+func eccPrivateKey(key *hdkeychain.ExtendedKey) (*ecc.PrivateKey, error) {
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, false)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +704,41 @@ func keyAt(wallet *hdwallet.Wallet, index int) (*ecc.PrivateKey, error) {
 	return k, nil
 }
 
+func deriveExtended(master *hdkeychain.ExtendedKey, derivationPath string) (*hdkeychain.ExtendedKey, error) {
+	path, err := hdwallet.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	key := master
+	for _, n := range path {
+		key, err = key.Child(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// NewHdFromXpriv restores a Hd from a bip32 root extended private key, such as the one provided by Xpriv. The
+// mnemonic can't be recovered from the key, so String will be empty and Quiz is not available.
+func NewHdFromXpriv(xpriv string) (*Hd, error) {
+	key, err := hdkeychain.NewKeyFromString(xpriv)
+	if err != nil {
+		return nil, err
+	}
+	if !key.IsPrivate() {
+		return nil, errors.New("expected an extended private key, got an extended public key")
+	}
+	if key.Depth() != 0 {
+		return nil, errors.New("expected a root extended private key, got a derived key")
+	}
+	return &Hd{
+		words:  make([]string, 0),
+		master: key,
+		nodes:  newNodeCache(),
+	}, nil
+}
+
 // NewAccountFromString returns a fio.Account from the first key derived, this is a shortcut for getting the first key
 // as an account.
 func NewAccountFromString(mnemonic string) (account *fio.Account, err error) {
@@ -212,93 +758,276 @@ func NewAccountFromString(mnemonic string) (account *fio.Account, err error) {
 type HdQuiz struct {
 	Description string
 	Check       func(s string) bool // function confirming correct answer
+	Choices     []string            // only set by QuizMultipleChoice, includes the correct answer
+
+	// ChecksRemaining is the number of incorrect answers allowed before Check always fails, or -1 if unlimited.
+	// See WithQuizAttempts.
+	ChecksRemaining func() int
 
 	index int // for tests
 	word  string
 }
 
-// Quiz generates a number of randomized quiz questions, if less than one is provided, it uses hd.Len()/3
-func (hd Hd) Quiz(count int) (questions []HdQuiz, err error) {
+// WithQuizSeed returns a copy of the Hd where Quiz, QuizMultipleChoice, and QuizSession use a fixed seed, so the
+// questions are reproducible. This is intended for tests.
+func (hd Hd) WithQuizSeed(seed int64) *Hd {
+	hd.quizSeed = seed
+	hd.quizSeeded = true
+	return &hd
+}
+
+// WithQuizAttempts returns a copy of the Hd where each quiz question only allows a limited number of incorrect
+// answers, after which Check always returns false. This supports lockout policies.
+func (hd Hd) WithQuizAttempts(attempts int) *Hd {
+	hd.quizAttempts = attempts
+	return &hd
+}
+
+func (hd Hd) quizRand() *mrand.Rand {
+	seed := time.Now().UnixNano()
+	if hd.quizSeeded {
+		seed = hd.quizSeed
+	}
+	return mrand.New(mrand.NewSource(seed)) // #nosec
+}
+
+// quizCheck validates the Hd can be quizzed, and provides the default count
+func (hd Hd) quizCheck(count int) (int, error) {
+	if err := hd.canDerive(); err != nil {
+		return 0, err
+	}
+	if hd.Len() == 0 {
+		return 0, errors.New("Hd does not have a mnemonic")
+	}
 	if count > hd.Len() {
-		return nil, errors.New("invalid count requested, exceeds number of words")
+		return 0, errors.New("invalid count requested, exceeds number of words")
 	}
 	if count < 1 {
 		count = len(hd.words) / 3
 	}
 	for _, n := range hd.words {
 		if n == "" {
-			return nil, errors.New("invalid mnemonic, got an empty word")
+			return 0, ErrEmptyWord
 		}
 	}
-	mrand.Seed(time.Now().UnixNano())
-	chosen := make(map[int]bool)
-	i := 0
-	questions = make([]HdQuiz, count)
-	for i < count {
-		r := mrand.Intn(len(hd.words))
-		if chosen[r] {
-			continue
-		}
-		chosen[r] = true
+	return count, nil
+}
+
+// Quiz generates a number of randomized quiz questions, if less than one is provided, it uses hd.Len()/3
+func (hd Hd) Quiz(count int) (questions []HdQuiz, err error) {
+	if count, err = hd.quizCheck(count); err != nil {
+		return nil, err
+	}
+	return hd.quizQuestions(hd.quizRand().Perm(hd.Len())[:count]), nil
+}
+
+// quizQuestions builds the questions for word positions
+func (hd Hd) quizQuestions(positions []int) []HdQuiz {
+	questions := make([]HdQuiz, len(positions))
+	for i, r := range positions {
+		q := &questions[i]
 		switch r {
 		case 0:
-			questions[i].Description = "first"
+			q.Description = "first"
 		case 1:
-			questions[i].Description = "second"
+			q.Description = "second"
 		case 2:
-			questions[i].Description = "third"
+			q.Description = "third"
 		case 3:
-			questions[i].Description = "fourth"
+			q.Description = "fourth"
 		case 4:
-			questions[i].Description = "fifth"
+			q.Description = "fifth"
 		case 5:
-			questions[i].Description = "sixth"
+			q.Description = "sixth"
 		case 6:
-			questions[i].Description = "seventh"
+			q.Description = "seventh"
 		case 7:
-			questions[i].Description = "eighth"
+			q.Description = "eighth"
 		case 8:
-			questions[i].Description = "ninth"
+			q.Description = "ninth"
 		case 9:
-			questions[i].Description = "tenth"
+			q.Description = "tenth"
 		case 10:
-			questions[i].Description = "eleventh"
+			q.Description = "eleventh"
 		case 11:
-			questions[i].Description = "twelfth"
+			q.Description = "twelfth"
 		case 12:
-			questions[i].Description = "thirteenth"
+			q.Description = "thirteenth"
 		case 13:
-			questions[i].Description = "fourteenth"
+			q.Description = "fourteenth"
 		case 14:
-			questions[i].Description = "fifteenth"
+			q.Description = "fifteenth"
 		case 15:
-			questions[i].Description = "sixteenth"
+			q.Description = "sixteenth"
 		case 16:
-			questions[i].Description = "seventeenth"
+			q.Description = "seventeenth"
 		case 17:
-			questions[i].Description = "eighteenth"
+			q.Description = "eighteenth"
 		case 18:
-			questions[i].Description = "nineteenth"
+			q.Description = "nineteenth"
 		case 19:
-			questions[i].Description = "twentieth"
+			q.Description = "twentieth"
 		case 20:
-			questions[i].Description = "twenty-first"
+			q.Description = "twenty-first"
 		case 21:
-			questions[i].Description = "twenty-second"
+			q.Description = "twenty-second"
 		case 22:
-			questions[i].Description = "twenty-third"
+			q.Description = "twenty-third"
 		case 23:
-			questions[i].Description = "twenty-fourth"
+			q.Description = "twenty-fourth"
 		}
-		// closure ensures dereference of iterator
-		func(i int, r int) {
-			questions[i].word = hd.words[r]
-			questions[i].index = r
-			questions[i].Check = func(s string) bool {
-				return strings.TrimSpace(s) == hd.words[r]
+		word := hd.words[r]
+		q.word = word
+		q.index = r
+		limit, failures := hd.quizAttempts, 0
+		mux := sync.Mutex{}
+		q.ChecksRemaining = func() int {
+			mux.Lock()
+			defer mux.Unlock()
+			if limit < 1 {
+				return -1
 			}
-		}(i, r)
-		i += 1
+			return limit - failures
+		}
+		q.Check = func(s string) bool {
+			mux.Lock()
+			defer mux.Unlock()
+			if limit > 0 && failures >= limit {
+				return false
+			}
+			// the comparison time only depends on the length of the answer
+			if subtle.ConstantTimeCompare([]byte(quizNormalize(s)), []byte(quizNormalize(word))) == 1 {
+				return true
+			}
+			failures++
+			return false
+		}
 	}
-	return
+	return questions
+}
+
+// quizNormalize ignores differences in case, surrounding whitespace, and unicode composition
+func quizNormalize(s string) string {
+	return strings.ToLower(norm.NFKD.String(strings.TrimSpace(s)))
+}
+
+// QuizMultipleChoice generates quiz questions like Quiz, each with a number of distractor words added to Choices
+// along with the correct answer, for building a multiple-choice UI. Distractors are chosen from the wordlist, and
+// are similar to the answer where possible.
+func (hd Hd) QuizMultipleChoice(count int, distractors int) ([]HdQuiz, error) {
+	if distractors < 1 || distractors > 20 {
+		return nil, errors.New("distractors must be between 1 and 20")
+	}
+	count, err := hd.quizCheck(count)
+	if err != nil {
+		return nil, err
+	}
+	rng := hd.quizRand()
+	questions := hd.quizQuestions(rng.Perm(hd.Len())[:count])
+	if err = hd.addChoices(questions, distractors, rng); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (hd Hd) addChoices(questions []HdQuiz, distractors int, rng *mrand.Rand) error {
+	wl, err := getWordList(hd.language)
+	if err != nil {
+		return err
+	}
+	for i := range questions {
+		choices := append(distractorWords(questions[i].word, wl, distractors, rng), questions[i].word)
+		rng.Shuffle(len(choices), func(a, b int) {
+			choices[a], choices[b] = choices[b], choices[a]
+		})
+		questions[i].Choices = choices
+	}
+	return nil
+}
+
+// distractorWords picks words that start with the same letter and have a similar length, or random words if
+// there are not enough similar ones
+func distractorWords(word string, wl *wordList, count int, rng *mrand.Rand) []string {
+	w := []rune(word)
+	similar := make([]string, 0)
+	for _, candidate := range wl.words {
+		c := []rune(candidate)
+		if candidate != word && c[0] == w[0] && len(c) >= len(w)-1 && len(c) <= len(w)+1 {
+			similar = append(similar, candidate)
+		}
+	}
+	rng.Shuffle(len(similar), func(a, b int) {
+		similar[a], similar[b] = similar[b], similar[a]
+	})
+	if len(similar) > count {
+		return similar[:count]
+	}
+	chosen := make(map[string]bool)
+	for _, s := range similar {
+		chosen[s] = true
+	}
+	for len(similar) < count {
+		candidate := wl.words[rng.Intn(len(wl.words))]
+		if candidate == word || chosen[candidate] {
+			continue
+		}
+		chosen[candidate] = true
+		similar = append(similar, candidate)
+	}
+	return similar
+}
+
+// QuizSession hands out quizzes that together cover every word of the mnemonic exactly once, so a backup
+// verification flow can show that the user confirmed all of the words. It is not safe for concurrent use.
+type QuizSession struct {
+	hd          Hd
+	rng         *mrand.Rand
+	order       []int
+	next        int
+	distractors int
+}
+
+// QuizSession starts a full-coverage quiz session, if distractors is greater than zero the questions will have
+// multiple choice answers as with QuizMultipleChoice.
+func (hd Hd) QuizSession(distractors int) (*QuizSession, error) {
+	if _, err := hd.quizCheck(1); err != nil {
+		return nil, err
+	}
+	if distractors < 0 || distractors > 20 {
+		return nil, errors.New("distractors must be between 0 and 20")
+	}
+	rng := hd.quizRand()
+	return &QuizSession{hd: hd, rng: rng, order: rng.Perm(hd.Len()), distractors: distractors}, nil
+}
+
+// Next provides up to count questions for words that have not been covered yet, if less than one is provided it
+// uses hd.Len()/3. An error is returned once every word has been covered.
+func (qs *QuizSession) Next(count int) ([]HdQuiz, error) {
+	if qs.Done() {
+		return nil, errors.New("quiz session has covered every word")
+	}
+	if count < 1 {
+		count = qs.hd.Len() / 3
+	}
+	if count > qs.Remaining() {
+		count = qs.Remaining()
+	}
+	questions := qs.hd.quizQuestions(qs.order[qs.next : qs.next+count])
+	if qs.distractors > 0 {
+		if err := qs.hd.addChoices(questions, qs.distractors, qs.rng); err != nil {
+			return nil, err
+		}
+	}
+	qs.next += count
+	return questions, nil
+}
+
+// Remaining is the number of words that have not been covered
+func (qs *QuizSession) Remaining() int {
+	return len(qs.order) - qs.next
+}
+
+// Done is true when every word has been covered
+func (qs *QuizSession) Done() bool {
+	return qs.Remaining() == 0
 }
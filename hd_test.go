@@ -1,8 +1,12 @@
 package fiox
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"strings"
 	"testing"
 )
 
@@ -159,6 +163,118 @@ func TestHd_Quiz(t *testing.T) {
 	}
 }
 
+func TestHd_QuizMultipleChoice(t *testing.T) {
+	hd, err := NewHdFromString("dream knife language movie cannon remove width like wedding gate help patient ocean usage system steak screen summer subway field venture")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	q, err := hd.QuizMultipleChoice(5, 3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, quiz := range q {
+		if len(quiz.Choices) != 4 {
+			t.Error("expected 4 choices, got", len(quiz.Choices))
+		}
+		correct := 0
+		seen := make(map[string]bool)
+		for _, c := range quiz.Choices {
+			if seen[c] {
+				t.Error("duplicate choice", c)
+			}
+			seen[c] = true
+			if quiz.Check(c) {
+				correct++
+			}
+		}
+		if correct != 1 {
+			t.Error("expected exactly one correct choice, got", correct)
+		}
+	}
+	if _, err = hd.QuizMultipleChoice(5, 0); err == nil {
+		t.Error("allowed zero distractors")
+	}
+}
+
+func TestHd_QuizSession(t *testing.T) {
+	hd, err := NewHdFromString("dream knife language movie cannon remove width like wedding gate help patient ocean usage system steak screen summer subway field venture")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	a, _ := hd.WithQuizSeed(42).QuizMultipleChoice(5, 3)
+	b, _ := hd.WithQuizSeed(42).QuizMultipleChoice(5, 3)
+	for i := range a {
+		if a[i].index != b[i].index || strings.Join(a[i].Choices, " ") != strings.Join(b[i].Choices, " ") {
+			t.Error("seeded quizzes were different")
+		}
+	}
+
+	session, err := hd.QuizSession(0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	covered := make(map[int]int)
+	for !session.Done() {
+		q, err := session.Next(4)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, quiz := range q {
+			covered[quiz.index]++
+			if quiz.Choices != nil {
+				t.Error("expected no choices")
+			}
+		}
+	}
+	if len(covered) != hd.Len() {
+		t.Error("session did not cover every word")
+	}
+	for i, n := range covered {
+		if n != 1 {
+			t.Error("word covered more than once", i)
+		}
+	}
+	if _, err = session.Next(1); err == nil {
+		t.Error("expected an error after covering all words")
+	}
+}
+
+func TestHd_QuizAttempts(t *testing.T) {
+	hd, err := NewHdFromString("dream knife language movie cannon remove width like wedding gate help patient ocean usage system steak screen summer subway field venture")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	q, err := hd.Quiz(3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !q[0].Check(" "+strings.ToUpper(q[0].word)+" ") || q[0].ChecksRemaining() != -1 {
+		t.Error("answer was not normalized")
+	}
+
+	q, err = hd.WithQuizAttempts(2).Quiz(3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if q[0].Check("wrong") || q[0].ChecksRemaining() != 1 {
+		t.Error("expected one remaining check")
+	}
+	if q[0].Check("wrong") || q[0].Check(q[0].word) || q[0].ChecksRemaining() != 0 {
+		t.Error("correct answer was accepted after the attempt limit")
+	}
+	if !q[1].Check(q[1].word) {
+		t.Error("attempts should be tracked per question")
+	}
+}
+
 func TestHd_Xpriv(t *testing.T) {
 	hd, err := NewHdFromString("struggle dream fetch aunt marriage adult merry machine vessel help slogan bright balcony extend stomach sun father essay surface call song bitter economy approve")
 	if err != nil {
@@ -192,3 +308,448 @@ func TestHd_Xpub(t *testing.T) {
 		fmt.Println(xp)
 	}
 }
+
+func TestNewHdFromStringWithPassphrase(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	hd, err := NewHdFromStringWithPassphrase(mnemonic, "TREZOR")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// BIP39 reference test vector
+	xp, err := hd.Xpriv()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if xp != "xprv9s21ZrQH143K3h3fDYiay8mocZ3afhfULfb5GX8kCBdno77K4HiA15Tg23wpbeF1pLfs1c5SPmYHrEpTuuRhxMwvKDwqdKiGJS9XFKzUsAF" {
+		t.Error("Xpriv with passphrase did not match")
+		fmt.Println(xp)
+	}
+	noPass, err := NewHdFromString(mnemonic)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	emptyPass, err := NewHdFromStringWithPassphrase(mnemonic, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k, _ := hd.KeyAt(0)
+	kNoPass, _ := noPass.KeyAt(0)
+	kEmpty, _ := emptyPass.KeyAt(0)
+	if k.Keys[0].String() == kNoPass.Keys[0].String() {
+		t.Error("passphrase did not change derived key")
+	}
+	if kNoPass.Keys[0].String() != kEmpty.Keys[0].String() {
+		t.Error("empty passphrase should derive the same keys as no passphrase")
+	}
+	if _, err = NewHdFromStringWithPassphrase("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "TREZOR"); err == nil {
+		t.Error("accepted mnemonic with a bad checksum")
+	}
+	r, err := NewRandomHdWithPassphrase(12, "TREZOR")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Len() != 12 {
+		t.Error("got wrong word length")
+	}
+}
+
+func TestHd_Derive(t *testing.T) {
+	hd, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := hd.Derive("m/44'/235'/0'/0/15")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if key.String() != "5J6NKGL4cqbZXfi3fTbXZtPqtDL2wHeoLdmkLg2bnHQF2KSHijs" {
+		t.Error("derived key 15 mismatch")
+	}
+	if _, err = hd.Derive("44'/235'/0'/0/15"); err == nil {
+		t.Error("allowed a relative derivation path")
+	}
+	if _, err = hd.Derive("m/44'/235'/x'/0/15"); err == nil {
+		t.Error("allowed an invalid derivation path")
+	}
+	second, err := hd.WithAccount(1, 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if second.PathAt(3) != "m/44'/235'/1'/1/3" {
+		t.Error("got wrong path for account:", second.PathAt(3))
+	}
+	k3, err := second.KeyAt(3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	d3, err := hd.Derive("m/44'/235'/1'/1/3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k3.Keys[0].String() != d3.String() {
+		t.Error("key from account did not match derived key")
+	}
+	if orig, _ := hd.KeyAt(3); orig.Keys[0].String() == d3.String() {
+		t.Error("changing account modified the original Hd")
+	}
+	if _, err = hd.WithAccount(-1, 0); err == nil {
+		t.Error("allowed a negative account")
+	}
+}
+
+func TestNewHdFromXpub(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	xpub, err := hd.AccountXpub()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	watch, err := NewHdFromXpub(xpub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !watch.WatchOnly() || hd.WatchOnly() {
+		t.Error("watch-only flag incorrect")
+	}
+	pubs, err := watch.PubKeys(9)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if pubs[3].String() != "FIO7KFe37B9FHxRLNGzDA3ACGVY15V6LvVLdohC4ppajUYtwj17KH" {
+		t.Error("watch-only public key 3 mismatch")
+	}
+	if pubs[8].String() != "FIO6qBcB36nBfvbqvmc6xHfucZGQSVJkHHcScvgWvu47oboW2FGxX" {
+		t.Error("watch-only public key 8 mismatch")
+	}
+	pub, err := watch.PubKeyAt(17)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if pub.String() != "FIO79wTtYceEozALgxmxQBieRRiK2AiiHL66ssEcNKF49xjbdDWew" {
+		t.Error("watch-only public key 17 mismatch")
+	}
+	if _, err = watch.KeyAt(0); !errors.Is(err, ErrWatchOnly) {
+		t.Error("watch-only Hd should not provide private keys")
+	}
+	if _, err = watch.Xpriv(); !errors.Is(err, ErrWatchOnly) {
+		t.Error("watch-only Hd should not provide xpriv")
+	}
+	xpriv, _ := hd.Xpriv()
+	if _, err = NewHdFromXpub(xpriv); err == nil {
+		t.Error("accepted an extended private key as watch-only")
+	}
+}
+
+func TestNewHdFromXpriv(t *testing.T) {
+	hd, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	xpriv, err := hd.Xpriv()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restored, err := NewHdFromXpriv(xpriv)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	keys, err := restored.Keys(2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if keys.Keys[0].String() != "5J4s3zFEdkkxTDW7vGvbMFbCnp7Lp2CYKPshdFEqQabPYhiTTZY" {
+		t.Error("restored key 0 mismatch")
+	}
+	if keys.Keys[1].String() != "5KhG6QigfDLEDmE5UsHJnYqcHbuEyxDjqmFZBeUgY1sYJpqxqRW" {
+		t.Error("restored key 1 mismatch")
+	}
+	if rx, _ := restored.Xpriv(); rx != xpriv {
+		t.Error("restored xpriv did not match")
+	}
+	xpub, _ := hd.Xpub()
+	if rx, _ := restored.Xpub(); rx != xpub {
+		t.Error("restored xpub did not match")
+	}
+	if _, err = restored.Quiz(0); err == nil {
+		t.Error("quiz should not be available without a mnemonic")
+	}
+	if _, err = NewHdFromXpriv(xpub); err == nil {
+		t.Error("accepted an extended public key")
+	}
+	if _, err = NewHdFromXpriv("xprv-not-a-key"); err == nil {
+		t.Error("accepted an invalid key")
+	}
+}
+
+func TestNewHdFromEntropy(t *testing.T) {
+	// BIP39 reference test vector
+	entropy, _ := hex.DecodeString("7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f")
+	hd, err := NewHdFromEntropy(entropy, LangEnglish, "TREZOR")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if hd.String() != "legal winner thank year wave sausage worth useful legal winner thank yellow" {
+		t.Error("mnemonic did not match entropy:", hd.String())
+	}
+	seed, err := hd.Seed()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if hex.EncodeToString(seed) != "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607" {
+		t.Error("seed did not match")
+	}
+	e, err := hd.Entropy()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(e, entropy) {
+		t.Error("entropy did not round trip")
+	}
+	seed[0] ^= 0xff
+	if again, _ := hd.Seed(); again[0] == seed[0] {
+		t.Error("modifying the returned seed changed the Hd")
+	}
+	if _, err = NewHdFromEntropy(entropy[:15], LangEnglish, ""); err == nil {
+		t.Error("allowed entropy with an invalid length")
+	}
+	xpriv, _ := hd.Xpriv()
+	fromKey, _ := NewHdFromXpriv(xpriv)
+	if _, err = fromKey.Seed(); err == nil {
+		t.Error("Hd from xpriv should not have a seed")
+	}
+	if _, err = fromKey.Entropy(); err == nil {
+		t.Error("Hd from xpriv should not have entropy")
+	}
+}
+
+func TestHd_Zeroize(t *testing.T) {
+	hd, err := NewRandomHd(12)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if hd.Redacted().String() == hd.String() {
+		t.Error("redacted Hd printed the mnemonic")
+	}
+	other, _ := hd.WithAccount(1, 0)
+	if err = hd.Close(); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = hd.KeyAt(0); !errors.Is(err, ErrZeroized) {
+		t.Error("expected ErrZeroized after Close, got", err)
+	}
+	if _, err = other.PubKeyAt(0); !errors.Is(err, ErrZeroized) {
+		t.Error("copy from WithAccount was not zeroized")
+	}
+	if seed, err := hd.Seed(); err == nil || seed != nil {
+		t.Error("seed was available after Zeroize")
+	}
+	if strings.Join(hd.words, "") != "" {
+		t.Error("words were not cleared")
+	}
+}
+
+func TestHd_PubKeysRange(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, err := hd.PubKeysRange(8, 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(pubs) != 10 || pubs[0].String() != "FIO6qBcB36nBfvbqvmc6xHfucZGQSVJkHHcScvgWvu47oboW2FGxX" ||
+		pubs[9].String() != "FIO79wTtYceEozALgxmxQBieRRiK2AiiHL66ssEcNKF49xjbdDWew" {
+		t.Error("public key range mismatch")
+	}
+	keys, err := hd.KeysRange(8, 2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if keys.Keys[0].PublicKey().String()[3:] != pubs[0].String()[3:] {
+		t.Error("private key range did not match public key range")
+	}
+	if _, err = hd.KeysRange(-1, 2); err == nil {
+		t.Error("allowed a negative start")
+	}
+}
+
+func TestHd_MaxIndex(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// the last non-hardened index works, the next would be hardened and one more wraps around to 0
+	if _, err = hd.KeyAt(maxIndex); err != nil {
+		t.Error(err)
+	}
+	if _, err = hd.PubKeysRange(maxIndex-1, 2); err != nil {
+		t.Error(err)
+	}
+	hardened, wrapped := int64(maxIndex)+1, int64(1)<<32
+	for _, index := range []int{int(hardened), int(wrapped)} {
+		if _, err = hd.KeyAt(index); err == nil {
+			t.Error("allowed index", index)
+		}
+		if _, err = hd.PubKeyAt(index); err == nil {
+			t.Error("allowed public key index", index)
+		}
+	}
+	if _, err = hd.KeysRange(maxIndex, 2); err == nil {
+		t.Error("allowed a range past the last index")
+	}
+	if _, err = hd.PubKeysRange(maxIndex, 2); err == nil {
+		t.Error("allowed a public key range past the last index")
+	}
+	if _, err = hd.KeySet(maxIndex-1, 3); err == nil {
+		t.Error("allowed a key set past the last index")
+	}
+	xpub, _ := hd.Xpub()
+	watch, err := NewHdFromXpub(xpub)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = watch.PubKeyAt(int(hardened)); err == nil {
+		t.Error("allowed a watch-only hardened index")
+	}
+}
+
+func TestHd_WithWorkers(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	serial, err := hd.PubKeysRange(3, 40)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	parallel, err := hd.WithWorkers(8).PubKeysRange(3, 40)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for i := range serial {
+		if serial[i].String() != parallel[i].String() {
+			t.Error("parallel derivation returned keys out of order at", i)
+			return
+		}
+	}
+	keys, err := hd.WithWorkers(4).Keys(10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if keys.Keys[3].PublicKey().String()[3:] != serial[0].String()[3:] {
+		t.Error("parallel private key mismatch")
+	}
+	hd.Zeroize()
+	if _, err = hd.WithWorkers(4).PubKeys(10); !errors.Is(err, ErrZeroized) {
+		t.Error("expected ErrZeroized, got", err)
+	}
+}
+
+func BenchmarkHd_KeyAt(b *testing.B) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = hd.KeyAt(i % 1000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHd_Derive(b *testing.B) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = hd.Derive(hd.PathAt(i % 1000)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestHd_Actors(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	actors, err := hd.Actors(4)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected, _ := fio.ActorFromPub("FIO7KFe37B9FHxRLNGzDA3ACGVY15V6LvVLdohC4ppajUYtwj17KH")
+	if actors[3] != expected || len(actors) != 4 {
+		t.Error("actor 3 mismatch")
+	}
+	actor, err := hd.ActorAt(3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if actor != expected {
+		t.Error("ActorAt did not match Actors")
+	}
+}
+
+func TestHd_Redacted(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fp, err := hd.Fingerprint()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if fp != "73c5da0a" {
+		t.Error("unexpected fingerprint", fp)
+	}
+	redacted := hd.Redacted()
+	if redacted.String() != "[redacted 73c5da0a]" || redacted.Reveal() != hd.String() {
+		t.Error("redacted Hd printed the mnemonic", redacted.String())
+	}
+	if s := fmt.Sprintf("%v %+v %#v", redacted, *redacted, hd); strings.Contains(s, "abandon") {
+		t.Error("formatted Hd leaked the mnemonic", s)
+	}
+}
@@ -0,0 +1,138 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultHistoryPageSize is how many actions AllAccountActions requests at once
+const DefaultHistoryPageSize = 100
+
+// HistoryAction is one action involving an account, including actions where it was only notified such as
+// receiving a transfer
+type HistoryAction struct {
+	GlobalSequence uint64        `json:"global_sequence"`
+	BlockNum       uint32        `json:"block_num"`
+	Timestamp      string        `json:"@timestamp"`
+	TransactionID  string        `json:"trx_id"`
+	Action         DecodedAction `json:"act"`
+}
+
+// HistoryClient is a source of account history, HyperionClient implements it for the v2/history API
+type HistoryClient interface {
+	// AccountActions provides up to limit actions involving account, oldest first, after skipping the first skip
+	AccountActions(ctx context.Context, account string, skip int, limit int) ([]HistoryAction, error)
+}
+
+// HyperionClient queries a Hyperion history server
+type HyperionClient struct {
+	baseURL    string
+	HttpClient *http.Client
+}
+
+// NewHyperionClient uses the Hyperion server at baseURL, such as https://fio.eosusa.news
+func NewHyperionClient(baseURL string) (*HyperionClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.New("history url must be http or https")
+	}
+	return &HyperionClient{baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// AccountActions uses /v2/history/get_actions
+func (h *HyperionClient) AccountActions(ctx context.Context, account string, skip int, limit int) ([]HistoryAction, error) {
+	query := url.Values{}
+	query.Set("account", account)
+	query.Set("skip", strconv.Itoa(skip))
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("sort", "asc")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.baseURL+"/v2/history/get_actions?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := h.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := readLimited(resp.Body, MaxChainResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get_actions returned %d: %s", resp.StatusCode, string(b))
+	}
+	page := struct {
+		Actions []HistoryAction `json:"actions"`
+	}{}
+	if err = json.Unmarshal(b, &page); err != nil {
+		return nil, err
+	}
+	return page.Actions, nil
+}
+
+// AllAccountActions pages through every action involving account, oldest first
+func AllAccountActions(ctx context.Context, client HistoryClient, account string) ([]HistoryAction, error) {
+	if client == nil {
+		return nil, errors.New("history client cannot be nil")
+	}
+	all := make([]HistoryAction, 0)
+	for {
+		page, err := client.AccountActions(ctx, account, len(all), DefaultHistoryPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < DefaultHistoryPageSize {
+			return all, nil
+		}
+	}
+}
+
+// KeyHistory is the history of the account belonging to one derived key
+type KeyHistory struct {
+	Index     int             `json:"index"`
+	PublicKey string          `json:"public_key"`
+	Actor     string          `json:"actor"`
+	Actions   []HistoryAction `json:"actions"`
+}
+
+// History pulls the actions of the accounts for count keys starting at index start. Keys that were never used have
+// no actions, which is how a restore can tell where to stop. It works for watch-only Hds and uses the WithWorkers
+// setting to query more than one account at a time.
+func (hd Hd) History(ctx context.Context, client HistoryClient, start int, count int) ([]KeyHistory, error) {
+	pubs, err := hd.PubKeysRange(start, count)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]KeyHistory, count)
+	err = forRange(hd.workers, count, func(i int) error {
+		kh := &history[i]
+		kh.Index, kh.PublicKey = start+i, pubs[i].String()
+		actor, err := fio.ActorFromPub(kh.PublicKey)
+		if err != nil {
+			return err
+		}
+		kh.Actor = string(actor)
+		kh.Actions, err = AllAccountActions(ctx, client, kh.Actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,80 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fioprotocol/fio-go"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestHyperionClient(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := hd.PubKeysRange(0, 2)
+	used, _ := fio.ActorFromPub(pubs[1].String())
+	// the used account has one more action than fits in a page
+	total := DefaultHistoryPageSize + 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v2/history/get_actions" || q.Get("sort") != "asc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page := struct {
+			Actions []map[string]interface{} `json:"actions"`
+		}{Actions: []map[string]interface{}{}}
+		if q.Get("account") == string(used) {
+			skip, _ := strconv.Atoi(q.Get("skip"))
+			limit, _ := strconv.Atoi(q.Get("limit"))
+			for seq := skip; seq < total && seq < skip+limit; seq++ {
+				page.Actions = append(page.Actions, map[string]interface{}{
+					"global_sequence": seq,
+					"block_num":       1000 + seq,
+					"@timestamp":      "2020-07-01T00:00:00.000",
+					"trx_id":          "d1e5c1b5",
+					"act": map[string]interface{}{
+						"account":       "fio.token",
+						"name":          "trnsfiopubky",
+						"authorization": []PermissionLevel{{Actor: string(used), Permission: "active"}},
+						"data":          map[string]interface{}{"amount": 1_000_000_000, "max_fee": 0, "actor": string(used), "payee_public_key": "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"},
+					},
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	client, err := NewHyperionClient(server.URL + "/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	history, err := hd.WithWorkers(2).History(context.Background(), client, 0, 2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(history) != 2 || len(history[0].Actions) != 0 || history[1].Index != 1 || history[1].Actor != string(used) {
+		t.Error("unexpected history", history)
+		return
+	}
+	actions := history[1].Actions
+	if len(actions) != total || actions[total-1].GlobalSequence != uint64(total-1) || actions[0].Timestamp == "" {
+		t.Error("unexpected actions", len(actions))
+		return
+	}
+	if s := DescribeAction(actions[0].Action); s != string(used)+" sent 1.000000000 FIO to FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy, max fee 0.000000000 FIO" {
+		t.Error("unexpected action", s)
+	}
+
+	if _, err = NewHyperionClient("ftp://example.com"); err == nil {
+		t.Error("expected a non http url to be rejected")
+	}
+}
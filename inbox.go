@@ -0,0 +1,187 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"sync"
+	"time"
+)
+
+// requestPageSize is how many requests are fetched per call to get_pending_fio_requests and get_sent_fio_requests
+const requestPageSize = 100
+
+// FioRequest is a FIO Request to or from the inbox key, with its content decrypted. If the content could not be
+// decrypted Content is nil and DecryptError says why.
+type FioRequest struct {
+	ID                uint64        `json:"fio_request_id"`
+	PayerFioAddress   string        `json:"payer_fio_address"`
+	PayeeFioAddress   string        `json:"payee_fio_address"`
+	PayerFioPublicKey string        `json:"payer_fio_public_key"`
+	PayeeFioPublicKey string        `json:"payee_fio_public_key"`
+	Status            string        `json:"status,omitempty"`
+	TimeStamp         string        `json:"time_stamp"`
+	Sent              bool          `json:"sent"`
+	Content           *FundsContent `json:"content,omitempty"`
+	DecryptError      string        `json:"decrypt_error,omitempty"`
+}
+
+// RequestInbox polls for the FIO Requests a key has received (pending, waiting for it to pay) and sent, decrypts
+// them, and only delivers each request once. The private key is needed to decrypt the content, keys held in a
+// wallet can be exported with ListKeys.
+type RequestInbox struct {
+	api  *fio.API
+	priv *ecc.PrivateKey
+	pub  string
+
+	mux  sync.Mutex
+	seen map[uint64]bool
+}
+
+// NewRequestInbox watches the requests of priv
+func NewRequestInbox(api *fio.API, priv *ecc.PrivateKey) (*RequestInbox, error) {
+	if api == nil || priv == nil {
+		return nil, errors.New("api and key are required")
+	}
+	return &RequestInbox{api: api, priv: priv, pub: priv.PublicKey().String(), seen: make(map[uint64]bool)}, nil
+}
+
+// RequestInbox watches the requests of the key at index
+func (hd Hd) RequestInbox(api *fio.API, index int) (*RequestInbox, error) {
+	priv, err := hd.keyAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequestInbox(api, priv)
+}
+
+// Poll provides the requests that have not been returned by an earlier Poll, pending requests first
+func (ri *RequestInbox) Poll(ctx context.Context) ([]FioRequest, error) {
+	rows := make([]fioRequestRow, 0)
+	for _, sent := range []bool{false, true} {
+		endpoint := "/v1/chain/get_pending_fio_requests"
+		if sent {
+			endpoint = "/v1/chain/get_sent_fio_requests"
+		}
+		page, err := ri.fetch(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			page[i].Sent = sent
+		}
+		rows = append(rows, page...)
+	}
+
+	fresh := make([]fioRequestRow, 0, len(rows))
+	ri.mux.Lock()
+	for _, row := range rows {
+		if ri.seen[row.ID] {
+			continue
+		}
+		ri.seen[row.ID] = true
+		fresh = append(fresh, row)
+	}
+	ri.mux.Unlock()
+	requests := make([]FioRequest, len(fresh))
+	for i := range fresh {
+		requests[i] = ri.decrypt(fresh[i])
+	}
+	return requests, nil
+}
+
+// Watch polls every interval and calls fn for each new request, the first poll is made right away. Errors are
+// retried on the next poll. It stops when ctx is cancelled, and the returned channel is closed once it has stopped.
+func (ri *RequestInbox) Watch(ctx context.Context, interval time.Duration, fn func(FioRequest)) (<-chan struct{}, error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if fn == nil {
+		return nil, errors.New("fn cannot be nil")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if requests, err := ri.Poll(ctx); err == nil {
+				for _, req := range requests {
+					fn(req)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done, nil
+}
+
+// Subscribe is Watch delivering the requests on a channel, which is closed when ctx is cancelled
+func (ri *RequestInbox) Subscribe(ctx context.Context, interval time.Duration) (<-chan FioRequest, error) {
+	requests := make(chan FioRequest)
+	done, err := ri.Watch(ctx, interval, func(req FioRequest) {
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-done
+		close(requests)
+	}()
+	return requests, nil
+}
+
+// fioRequestRow is a request as nodeos returns it, with the content still encrypted
+type fioRequestRow struct {
+	FioRequest
+	Content string `json:"content"`
+}
+
+// fetch reads every page of an endpoint, nodeos returns 404 when there are no requests
+func (ri *RequestInbox) fetch(ctx context.Context, endpoint string) ([]fioRequestRow, error) {
+	rows := make([]fioRequestRow, 0)
+	for {
+		page := struct {
+			Requests []fioRequestRow `json:"requests"`
+			More     int             `json:"more"`
+		}{}
+		found, err := chainPost(ctx, ri.api, endpoint, map[string]interface{}{
+			"fio_public_key": ri.pub,
+			"limit":          requestPageSize,
+			"offset":         len(rows),
+		}, &page)
+		if err != nil || !found {
+			return rows, err
+		}
+		rows = append(rows, page.Requests...)
+		if page.More == 0 || len(page.Requests) == 0 {
+			return rows, nil
+		}
+	}
+}
+
+// decrypt provides the request with its content decrypted, the content is encrypted with the other party's key
+func (ri *RequestInbox) decrypt(row fioRequestRow) FioRequest {
+	req := row.FioRequest
+	counterparty := req.PayeeFioPublicKey
+	if req.Sent {
+		counterparty = req.PayerFioPublicKey
+	}
+	pub, err := ecc.NewPublicKey(counterparty)
+	if err == nil {
+		req.Content, err = DecryptFundsContent(ri.priv, &pub, row.Content)
+	}
+	if err != nil {
+		req.DecryptError = err.Error()
+	}
+	return req
+}
@@ -0,0 +1,117 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestInbox(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	me, _ := hd.keyAt(0)
+	other, _ := hd.keyAt(1)
+	mePub, otherPub := me.PublicKey(), other.PublicKey()
+	content := &FundsContent{PayeePublicAddress: "bc1q", Amount: "0.1", ChainCode: "BTC", TokenCode: "BTC", Memo: "invoice 7"}
+	// requests to me were encrypted by the payee, requests from me by me
+	toMe, _ := EncryptNewFundsContent(other, &mePub, content)
+	fromMe, _ := EncryptNewFundsContent(me, &otherPub, content)
+
+	request := func(id int, payer string, payee string, content string) map[string]interface{} {
+		return map[string]interface{}{
+			"fio_request_id": id, "payer_fio_address": "me@fiotestnet", "payee_fio_address": "other@fiotestnet",
+			"payer_fio_public_key": payer, "payee_fio_public_key": payee, "content": content,
+			"time_stamp": "2021-01-01T00:00:00",
+		}
+	}
+	pending := []interface{}{
+		request(1, mePub.String(), otherPub.String(), toMe),
+		request(2, mePub.String(), otherPub.String(), "garbage"),
+		request(3, mePub.String(), otherPub.String(), toMe),
+	}
+	sent := []interface{}{request(4, otherPub.String(), mePub.String(), fromMe)}
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Key    string `json:"fio_public_key"`
+			Offset int    `json:"offset"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Key != mePub.String() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/chain/get_pending_fio_requests":
+			// two pages
+			if req.Offset == 0 {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"requests": pending[:2], "more": len(pending) - 2})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"requests": pending[req.Offset:], "more": 0})
+		case "/v1/chain/get_sent_fio_requests":
+			if len(sent) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"requests": sent, "more": 0})
+		}
+	}))
+	defer nodeos.Close()
+
+	inbox, err := hd.RequestInbox(&fio.API{API: eos.API{BaseURL: nodeos.URL}}, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	requests, err := inbox.Poll(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(requests) != 4 {
+		t.Fatal("expected four requests, got", len(requests))
+	}
+	for _, i := range []int{0, 2, 3} {
+		if requests[i].Content == nil || requests[i].Content.Memo != "invoice 7" {
+			t.Error("content was not decrypted", requests[i])
+		}
+	}
+	if requests[1].Content != nil || requests[1].DecryptError == "" {
+		t.Error("expected a decrypt error", requests[1])
+	}
+	if requests[0].Sent || !requests[3].Sent {
+		t.Error("pending and sent requests were mixed up")
+	}
+
+	// only new requests are delivered
+	sent = nil
+	pending = append(pending, request(5, mePub.String(), otherPub.String(), toMe))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch, err := inbox.Subscribe(ctx, time.Hour)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	select {
+	case req := <-ch:
+		if req.ID != 5 {
+			t.Error("expected request 5, got", req.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no request was delivered")
+	}
+	cancel()
+	for range ch {
+		t.Error("no more requests were expected")
+	}
+}
@@ -0,0 +1,45 @@
+package fiox
+
+import (
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// KeyIter lazily derives keys in index order, it is created with Hd.Iter. A KeyIter is not safe for concurrent use.
+type KeyIter struct {
+	hd   Hd
+	next int
+}
+
+// Iter provides a KeyIter that begins at index start, avoiding the allocation of a large slice with Keys or PubKeys.
+// Watch-only Hds can only use NextPub.
+func (hd Hd) Iter(start int) (*KeyIter, error) {
+	if err := checkIndex(start); err != nil {
+		return nil, err
+	}
+	return &KeyIter{hd: hd, next: start}, nil
+}
+
+// Next derives the private key at the next index. The index is only advanced if the key was derived successfully.
+func (it *KeyIter) Next() (index int, key *ecc.PrivateKey, err error) {
+	key, err = it.hd.keyAt(it.next)
+	if err != nil {
+		return it.next, nil, err
+	}
+	it.next++
+	return it.next - 1, key, nil
+}
+
+// NextPub derives the public key at the next index
+func (it *KeyIter) NextPub() (index int, key *ecc.PublicKey, err error) {
+	key, err = it.hd.pubKeyAt(it.next)
+	if err != nil {
+		return it.next, nil, err
+	}
+	it.next++
+	return it.next - 1, key, nil
+}
+
+// Index is the index that will be derived by the next call to Next or NextPub
+func (it *KeyIter) Index() int {
+	return it.next
+}
@@ -0,0 +1,46 @@
+package fiox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHd_Iter(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	it, err := hd.Iter(8)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	i, pub, err := it.NextPub()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if i != 8 || pub.String() != "FIO6qBcB36nBfvbqvmc6xHfucZGQSVJkHHcScvgWvu47oboW2FGxX" {
+		t.Error("public key 8 mismatch")
+	}
+	i, key, err := it.Next()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected, _ := hd.PubKeyAt(9)
+	if i != 9 || key.PublicKey().String()[3:] != expected.String()[3:] || it.Index() != 10 {
+		t.Error("key 9 mismatch")
+	}
+
+	xpub, _ := hd.AccountXpub()
+	watch, _ := NewHdFromXpub(xpub)
+	it, _ = watch.Iter(0)
+	if _, _, err = it.Next(); !errors.Is(err, ErrWatchOnly) || it.Index() != 0 {
+		t.Error("watch-only iterator should not provide private keys")
+	}
+	if _, _, err = it.NextPub(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,202 @@
+package fiox
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// DerivedKey is a key derived from a Hd along with where it came from, PrivateKey is nil for watch-only Hds
+type DerivedKey struct {
+	Index      int
+	Path       string
+	Actor      eos.AccountName
+	PublicKey  *ecc.PublicKey
+	PrivateKey *ecc.PrivateKey
+}
+
+type derivedKeyJSON struct {
+	Index      int             `json:"index"`
+	Path       string          `json:"path"`
+	Actor      eos.AccountName `json:"actor"`
+	PublicKey  string          `json:"public_key"`
+	PrivateKey string          `json:"private_key,omitempty"`
+}
+
+// MarshalJSON encodes the keys as strings, note that the output includes the private key
+func (dk DerivedKey) MarshalJSON() ([]byte, error) {
+	if dk.PublicKey == nil {
+		return nil, errors.New("derived key does not have a public key")
+	}
+	j := derivedKeyJSON{Index: dk.Index, Path: dk.Path, Actor: dk.Actor, PublicKey: dk.PublicKey.String()}
+	if dk.PrivateKey != nil {
+		j.PrivateKey = dk.PrivateKey.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a DerivedKey, checking that the private key matches the public key
+func (dk *DerivedKey) UnmarshalJSON(b []byte) error {
+	j := derivedKeyJSON{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	pub, err := ecc.NewPublicKey(j.PublicKey)
+	if err != nil {
+		return err
+	}
+	var priv *ecc.PrivateKey
+	if j.PrivateKey != "" {
+		if priv, err = ecc.NewPrivateKey(j.PrivateKey); err != nil {
+			return err
+		}
+		if priv.PublicKey().String()[3:] != pub.String()[3:] {
+			return fmt.Errorf("private key for index %d does not match the public key", j.Index)
+		}
+	}
+	*dk = DerivedKey{Index: j.Index, Path: j.Path, Actor: j.Actor, PublicKey: &pub, PrivateKey: priv}
+	return nil
+}
+
+// KeySet is a range of derived keys that can be marshalled to JSON, see Hd.KeySet
+type KeySet struct {
+	Keys []DerivedKey `json:"keys"`
+}
+
+// KeySet derives count keys starting at index start, for watch-only Hds only the public keys are included
+func (hd Hd) KeySet(start int, count int) (*KeySet, error) {
+	if count < 1 {
+		return nil, errors.New("cannot derive 0 keys")
+	}
+	if err := checkIndexRange(start, count); err != nil {
+		return nil, err
+	}
+	ks := &KeySet{Keys: make([]DerivedKey, count)}
+	err := forRange(hd.workers, count, func(i int) (err error) {
+		dk := DerivedKey{Index: start + i, Path: hd.PathAt(start + i)}
+		if !hd.WatchOnly() {
+			if dk.PrivateKey, err = hd.keyAt(start + i); err != nil {
+				return err
+			}
+		}
+		if dk.PublicKey, err = hd.pubKeyAt(start + i); err != nil {
+			return err
+		}
+		if dk.Actor, err = fio.ActorFromPub(dk.PublicKey.String()); err != nil {
+			return err
+		}
+		ks.Keys[i] = dk
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// KeyBag provides the private keys in the set as an eos.KeyBag
+func (ks KeySet) KeyBag() (*eos.KeyBag, error) {
+	keybag := &eos.KeyBag{}
+	keybag.Keys = make([]*ecc.PrivateKey, 0)
+	for _, k := range ks.Keys {
+		if k.PrivateKey == nil {
+			return nil, ErrWatchOnly
+		}
+		keybag.Keys = append(keybag.Keys, k.PrivateKey)
+	}
+	return keybag, nil
+}
+
+// hdJSON is the public state of a Hd, enough to restore a watch-only Hd
+type hdJSON struct {
+	AccountXpub string   `json:"account_xpub"`
+	Account     int      `json:"account"`
+	Change      int      `json:"change"`
+	Language    Language `json:"language,omitempty"`
+}
+
+// MarshalJSON only includes public information: the account xpub, account, and change. Unmarshalling the result
+// gives a watch-only Hd. Use EncryptedJSON to persist the mnemonic.
+func (hd Hd) MarshalJSON() ([]byte, error) {
+	xpub, err := hd.AccountXpub()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(hdJSON{AccountXpub: xpub, Account: hd.account, Change: hd.change, Language: hd.language})
+}
+
+// UnmarshalJSON restores a watch-only Hd from the output of MarshalJSON
+func (hd *Hd) UnmarshalJSON(b []byte) error {
+	j := hdJSON{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	restored, err := NewHdFromXpub(j.AccountXpub)
+	if err != nil {
+		return err
+	}
+	restored.account = j.Account
+	restored.language = j.Language
+	if restored, err = restored.WithAccount(j.Account, j.Change); err != nil {
+		return err
+	}
+	*hd = *restored
+	return nil
+}
+
+// encryptedHdJSON is the envelope used by EncryptedJSON, data is the output of ExportEncrypted
+type encryptedHdJSON struct {
+	Version int    `json:"version"`
+	Cipher  string `json:"cipher"`
+	Data    []byte `json:"data"`
+	Account int    `json:"account"`
+	Change  int    `json:"change"`
+}
+
+// EncryptedJSON provides a JSON envelope holding the mnemonic and seed encrypted with ExportEncrypted, along with
+// the account and change in use, which are authenticated by the encryption. Restore it with NewHdFromEncryptedJSON.
+func (hd Hd) EncryptedJSON(passphrase string) ([]byte, error) {
+	data, err := hd.exportEncrypted(passphrase, encryptedPathData(hd.account, hd.change))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedHdJSON{
+		Version: encryptedVersion,
+		Cipher:  "scrypt-aes-256-gcm",
+		Data:    data,
+		Account: hd.account,
+		Change:  hd.change,
+	})
+}
+
+// NewHdFromEncryptedJSON restores a Hd from the output of EncryptedJSON
+func NewHdFromEncryptedJSON(b []byte, passphrase string) (*Hd, error) {
+	j := encryptedHdJSON{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return nil, err
+	}
+	if j.Version != encryptedVersion {
+		return nil, fmt.Errorf("unsupported encrypted Hd version %d", j.Version)
+	}
+	if j.Account < 0 || j.Change < 0 {
+		return nil, errors.New("account and change must not be negative")
+	}
+	hd, err := newHdFromEncrypted(j.Data, passphrase, encryptedPathData(j.Account, j.Change))
+	if err != nil {
+		return nil, err
+	}
+	return hd.WithAccount(j.Account, j.Change)
+}
+
+// encryptedPathData is the account and change of an encrypted Hd, they are authenticated along with the encrypted
+// data so that changing them in the file is detected rather than quietly deriving different keys
+func encryptedPathData(account int, change int) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(account))
+	binary.BigEndian.PutUint64(b[8:], uint64(change))
+	return b
+}
@@ -0,0 +1,85 @@
+package fiox
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeySet_JSON(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ks, err := hd.KeySet(3, 2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	b, err := json.Marshal(ks)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restored := &KeySet{}
+	if err = json.Unmarshal(b, restored); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(restored.Keys) != 2 || restored.Keys[0].Index != 3 || restored.Keys[0].Path != "m/44'/235'/0'/0/3" ||
+		restored.Keys[0].PublicKey.String() != "FIO7KFe37B9FHxRLNGzDA3ACGVY15V6LvVLdohC4ppajUYtwj17KH" ||
+		restored.Keys[1].PrivateKey.String() != ks.Keys[1].PrivateKey.String() {
+		t.Error("key set did not round trip")
+	}
+	if kb, err := restored.KeyBag(); err != nil || len(kb.Keys) != 2 {
+		t.Error("could not build a keybag", err)
+	}
+}
+
+func TestHd_JSON(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	b, err := json.Marshal(hd)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	watch := &Hd{}
+	if err = json.Unmarshal(b, watch); err != nil {
+		t.Error(err)
+		return
+	}
+	pub, err := watch.PubKeyAt(3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !watch.WatchOnly() || pub.String() != "FIO7KFe37B9FHxRLNGzDA3ACGVY15V6LvVLdohC4ppajUYtwj17KH" {
+		t.Error("watch-only Hd did not restore from JSON")
+	}
+
+	other, _ := hd.WithAccount(2, 1)
+	b, err = other.EncryptedJSON("secret")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restored, err := NewHdFromEncryptedJSON(b, "secret")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if restored.String() != hd.String() || restored.PathAt(0) != "m/44'/235'/2'/1/0" {
+		t.Error("encrypted JSON did not round trip")
+	}
+	// the account and change are stored in the clear but can't be changed without detection
+	tampered := strings.Replace(string(b), `"account":2`, `"account":3`, 1)
+	if _, err = NewHdFromEncryptedJSON([]byte(tampered), "secret"); !errors.Is(err, ErrDecrypt) {
+		t.Error("expected a modified account to be detected", err)
+	}
+}
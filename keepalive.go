@@ -0,0 +1,48 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// KeepUnlocked starts a watchdog that touches keosd every interval so the unlock timeout does not lock the wallets
+// during long batch operations. If a wallet unlocked by this client was locked anyway it is unlocked again, Unlock
+// must be called first. The watchdog stops when ctx is cancelled, and the returned channel is closed once it has
+// stopped.
+func (k *KeosClient) KeepUnlocked(ctx context.Context, interval time.Duration) (<-chan struct{}, error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if len(k.Sessions()) == 0 {
+		return nil, errors.New("the wallet must be unlocked with Unlock first")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// any wallet call resets the keosd timeout, errors are retried on the next tick
+				wallets, err := k.ListWallets(ctx)
+				if err != nil {
+					continue
+				}
+				unlocked := make(map[string]bool)
+				for _, w := range wallets {
+					unlocked[w.Name] = w.Unlocked
+				}
+				for wallet, password := range k.sessions.passwords() {
+					if !unlocked[wallet] {
+						_ = k.post(ctx, "/v1/wallet/unlock", []string{wallet, password}, nil)
+					}
+				}
+			}
+		}
+	}()
+	return done, nil
+}
@@ -0,0 +1,59 @@
+package fiox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeepUnlocked(t *testing.T) {
+	var touches, unlocks int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/wallet/list_wallets":
+			// report the wallet locked on the second touch
+			if atomic.AddInt32(&touches, 1) == 2 {
+				_, _ = w.Write([]byte(`["default"]`))
+				return
+			}
+			_, _ = w.Write([]byte(`["default *"]`))
+		case "/v1/wallet/unlock":
+			atomic.AddInt32(&unlocks, 1)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := k.KeepUnlocked(ctx, time.Millisecond); err == nil {
+		t.Error("expected an error before Unlock")
+	}
+	if err := k.Unlock(ctx, "PW5", "default"); err != nil {
+		t.Error(err)
+		cancel()
+		return
+	}
+	done, err := k.KeepUnlocked(ctx, 5*time.Millisecond)
+	if err != nil {
+		t.Error(err)
+		cancel()
+		return
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&touches) < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("watchdog did not stop")
+	}
+	if atomic.LoadInt32(&touches) < 4 || atomic.LoadInt32(&unlocks) != 1 {
+		t.Error("unexpected keosd calls", touches, unlocks)
+	}
+}
@@ -3,16 +3,21 @@ package fiox
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/fioprotocol/fio-go"
-	"github.com/mitchellh/go-ps"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"os/exec"
-	"sync"
+	"runtime"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -20,179 +25,666 @@ type KeosClient struct {
 	BaseUrl    string
 	HttpClient *http.Client
 	Socket     string
-	Keys       map[string]KeosKeys `json:"-"`
 	Wallet     string
-	password   string
+	keys       *keyMap     // loaded by GetKeys, see KeyByActor and the other accessors
+	sessions   *sessionMap // wallets unlocked by this client
+
+	// AutoUnlock re-unlocks the wallets and retries once when keosd reports one locked, such as after the unlock
+	// timeout fires during a long running session. It only applies to wallets unlocked with Unlock.
+	AutoUnlock bool
+
+	retry            RetryPolicy
+	requestTimeout   time.Duration
+	timeout          time.Duration
+	proxy            *url.URL
+	logger           KeosLogger
+	lookupWorkers    int
+	accountDetails   bool
+	tracer           Tracer
+	metrics          Metrics
+	maxResponseSize  int64
+	credentials      CredentialProvider
+	passwordProvider PasswordProvider
+	traceBodies      bool
+	optErr           error // an invalid option, returned by NewKeosClientOpts
 }
 
 type KeosKeys struct {
+	Actor      string `json:"actor"`
 	PublicKey  string `json:"public_key"`
-	PrivateKey string `json:"private_key"`
+	PrivateKey string `json:"private_key,omitempty"` // empty when loaded with GetPublicKeysOnly
 	FioAddress string `json:"fio_address"`
+
+	// Account and Permissions are only loaded when the client uses WithAccountDetails, Permissions lists the
+	// account permissions that include this key, such as "owner" and "active"
+	Account     *AccountDetails `json:"account,omitempty"`
+	Permissions []string        `json:"permissions,omitempty"`
 }
 
-// NewKeosClient provides a connection to keosd. It allows either a Unix socket or a TCP connection.
-func NewKeosClient(keosUrl string, socket string) *KeosClient {
+// NewKeosClient provides a connection to keosd. It allows either a Unix socket or a TCP connection, when both are
+// empty the socket is found with DiscoverSocket.
+func NewKeosClient(keosUrl string, socket string, opts ...KeosOption) *KeosClient {
 	client := &KeosClient{}
-	client.BaseUrl = "http://unix"
-	client.HttpClient = &http.Client{}
-	client.Keys = make(map[string]KeosKeys)
+	client.keys = newKeyMap()
+	client.sessions = newSessionMap()
+	if keosUrl == "" && socket == "" {
+		socket = defaultSocket()
+		// Windows builds of keosd are normally reached over the loopback address
+		if socket == "" {
+			keosUrl = DefaultKeosdURL
+		}
+	}
 	// by default we use a unix socket in the user's home directory:
 	if keosUrl == "" {
-		client.HttpClient = &http.Client{
-			Transport: &http.Transport{
-				IdleConnTimeout:    3 * time.Second,
-				DisableCompression: true,
-				DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
-					return net.Dial("unix", socket)
-				},
-			},
-		}
+		client.BaseUrl = "http://unix"
+		client.Socket = socket
+		client.HttpClient = socketHttpClient(socket)
 	} else {
 		client.BaseUrl = keosUrl
-		client.HttpClient = &http.Client{
-			Transport: &http.Transport{
-				MaxIdleConns:       1,
-				IdleConnTimeout:    30 * time.Second,
-				DisableCompression: true,
-			},
-		}
+		client.HttpClient = tcpHttpClient(nil)
 	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.applyTimeout()
 	return client
 }
 
-type alreadyUnlocked struct {
-	Error struct {
-		What string `json:"what"`
-	} `json:"error"`
-}
-
-// Unlock opens a locked keos wallet, it does not return an error if already unlocked
-func (k *KeosClient) Unlock(password string, wallet string) error {
-	k.Wallet = wallet
-	k.password = password
-	if password != "" {
-		resp, err := k.HttpClient.Post(k.BaseUrl+"/v1/wallet/unlock", "application/json", bytes.NewReader([]byte(`["`+k.Wallet+`","`+k.password+`"]`)))
-		if err != nil {
-			return err
+// NewKeosClientOpts provides a connection to keosd configured with options, WithSocket or WithURL select where
+// keosd is, otherwise DiscoverSocket is used to find it.
+func NewKeosClientOpts(opts ...KeosOption) (*KeosClient, error) {
+	client := &KeosClient{}
+	client.keys = newKeyMap()
+	client.sessions = newSessionMap()
+	for _, opt := range opts {
+		opt(client)
+	}
+	if client.optErr != nil {
+		return nil, client.optErr
+	}
+	if client.BaseUrl != "" && client.Socket != "" {
+		return nil, errors.New("only one of a socket or a URL can be used")
+	}
+	switch {
+	case client.Socket != "":
+		client.BaseUrl = "http://unix"
+		if client.HttpClient == nil {
+			client.HttpClient = socketHttpClient(client.Socket)
 		}
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
+	case client.BaseUrl != "":
+		if _, err := url.Parse(client.BaseUrl); err != nil {
+			return nil, err
 		}
-		err = resp.Body.Close()
+		if client.HttpClient == nil {
+			client.HttpClient = tcpHttpClient(client.proxy)
+		}
+	default:
+		socket, err := DiscoverSocket()
 		if err != nil {
-			return err
+			return nil, err
+		}
+		client.Socket = socket
+		client.BaseUrl = "http://unix"
+		if client.HttpClient == nil {
+			client.HttpClient = socketHttpClient(socket)
 		}
+	}
+	client.applyTimeout()
+	return client, nil
+}
 
-		if resp.StatusCode != http.StatusOK {
-			already := &alreadyUnlocked{}
-			e := json.Unmarshal(body, already)
-			if e == nil && already.Error.What == "Already unlocked" {
-				// not a problem, already unlocked
-				return nil
-			}
-			j, e := json.MarshalIndent(json.RawMessage(body), "", "  ")
-			if e != nil {
-				return errors.New("could not open wallet. Ensure it is unlocked, or use '-p' to provide the password")
-			}
-			return errors.New(string(j))
+// defaultSocket is a running keosd's socket if one is found, otherwise the usual location so later requests return a
+// meaningful error. It is empty on Windows when nothing is found.
+func defaultSocket() string {
+	if socket, err := DiscoverSocket(); err == nil {
+		return socket
+	}
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	if candidates := socketCandidates(); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return "keosd.sock"
+}
+
+func socketHttpClient(socket string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			IdleConnTimeout:    3 * time.Second,
+			DisableCompression: true,
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialKeosd(ctx, socket)
+			},
+		},
+	}
+}
+
+func tcpHttpClient(proxy *url.URL) *http.Client {
+	transport := &http.Transport{
+		MaxIdleConns:       1,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: true,
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: transport}
+}
+
+// applyTimeout sets the overall timeout on a copy of the http client, so a client passed in is not modified
+func (k *KeosClient) applyTimeout() {
+	if k.timeout <= 0 || k.HttpClient == nil {
+		return
+	}
+	c := *k.HttpClient
+	c.Timeout = k.timeout
+	k.HttpClient = &c
+}
+
+// Unlock opens a locked keos wallet, it does not return an error if already unlocked. Several wallets can be
+// unlocked by one client, Wallet is set to the last one and is used by GetKeys. If password is empty it is read
+// from the client's CredentialProvider, and then asked for from its PasswordProvider.
+func (k *KeosClient) Unlock(ctx context.Context, password string, wallet string) error {
+	if password == "" && k.credentials != nil {
+		var err error
+		if password, err = k.credentials.Password(wallet); err != nil && !errors.Is(err, ErrCredentialNotFound) {
+			return fmt.Errorf("could not read the password for %s: %w", wallet, err)
 		}
-	} else {
+	}
+	if password == "" && k.passwordProvider != nil {
+		var err error
+		if password, err = k.passwordProvider.WalletPassword(ctx, wallet); err != nil {
+			return err
+		}
+	}
+	if password == "" {
 		return errors.New("password not supplied, '-password' option is mandatory")
 	}
+	k.Wallet = wallet
+	if k.sessions == nil {
+		k.sessions = newSessionMap()
+	}
+	if unlocked, e := k.IsUnlocked(ctx, wallet); e == nil && unlocked {
+		k.sessions.set(wallet, password)
+		return nil
+	}
+	err := k.call(ctx, "/v1/wallet/unlock", []string{wallet, password}, nil)
+	if err != nil && !errors.Is(err, ErrWalletUnlocked) {
+		return err
+	}
+	// an already unlocked wallet is not a problem
+	k.sessions.set(wallet, password)
 	return nil
 }
 
-// Start attempts to launch the keosd process by spawning clio
-func (k KeosClient) Start(noKeosd bool) error {
-	if noKeosd {
+// Start makes sure keosd is running, if it does not answer it is launched by running clio which starts keosd
+func (k KeosClient) Start(ctx context.Context, noKeosd bool) error {
+	if noKeosd || k.Ping(ctx) == nil {
 		return nil
 	}
-	cmd := exec.Command("clio", "wallet", "list") // let clio start keosd
-	_ = cmd.Run()                                 // ignore output
-	var isRunning bool
-	procs, _ := ps.Processes()
-	for _, p := range procs {
-		if p.Executable() == "keosd" {
-			isRunning = true
-			break
-		}
+	clio, err := findExecutable("clio")
+	if err != nil {
+		return fmt.Errorf("keosd is not running: %w", err)
 	}
-	if !isRunning {
-		return errors.New("could not verify keosd is running")
+	cmd := exec.CommandContext(ctx, clio, "wallet", "list") // let clio start keosd
+	_ = cmd.Run()                                           // ignore output
+	for i := 0; i < 20; i++ {
+		if k.Ping(ctx) == nil {
+			return nil
+		}
+		if err := sleepContext(ctx, 100*time.Millisecond); err != nil {
+			return err
+		}
 	}
-	return nil
+	return errors.New("could not verify keosd is running")
 }
 
-func firstName(pubkey string, api *fio.API) string {
-	names, found, err := api.GetFioNames(pubkey)
-	if err != nil || !found {
-		return ""
-	}
-	return names.FioAddresses[0].FioAddress
+// KeosHealth is the result of a health check
+type KeosHealth struct {
+	Latency time.Duration `json:"latency"`
+	Wallets []KeosWallet  `json:"wallets"`
 }
 
-// GetKeys populates the list of keys stored in the wallet
-func (k *KeosClient) GetKeys(nodeosApi *fio.API) error {
-	// get a list of available keys:
-	resp, err := k.HttpClient.Post(k.BaseUrl+"/v1/wallet/list_keys", "application/json", bytes.NewReader([]byte(`["`+k.Wallet+`","`+k.password+`"]`)))
-	if err != nil {
-		return errors.New("could not connect to keosd, is the wallet unlocked?\n" + err.Error())
+// Ping checks that keosd is answering on the socket or URL, it is not retried
+func (k *KeosClient) Ping(ctx context.Context) error {
+	return k.send(ctx, "/v1/wallet/list_wallets", nil, nil)
+}
+
+// Health checks that keosd is answering and reports the response time and open wallets
+func (k *KeosClient) Health(ctx context.Context) (*KeosHealth, error) {
+	names := make([]string, 0)
+	start := time.Now()
+	if err := k.send(ctx, "/v1/wallet/list_wallets", nil, &names); err != nil {
+		return nil, err
+	}
+	h := &KeosHealth{Latency: time.Since(start), Wallets: make([]KeosWallet, len(names))}
+	for i, name := range names {
+		h.Wallets[i] = parseWalletName(name)
 	}
-	body, err := ioutil.ReadAll(resp.Body)
+	return h, nil
+}
+
+// GetKeys populates the list of keys stored in the wallet. If nodeosApi is nil the FIO addresses are not looked up,
+// which is faster and works offline. Use GetWalletKeys when more than one wallet is unlocked.
+func (k *KeosClient) GetKeys(ctx context.Context, nodeosApi *fio.API) error {
+	return k.GetWalletKeys(ctx, k.Wallet, nodeosApi)
+}
+
+// GetPublicKeysOnly populates the list of keys with only the public keys and FIO addresses, the private keys never
+// leave keosd. As with GetKeys, nodeosApi can be nil to skip looking up addresses. Note that keosd returns the keys
+// for all unlocked wallets, not only k.Wallet.
+func (k *KeosClient) GetPublicKeysOnly(ctx context.Context, nodeosApi *fio.API) error {
+	pubs, err := k.GetPublicKeys(ctx)
 	if err != nil {
 		return err
 	}
-	err = resp.Body.Close()
+	pairs := make([][]string, len(pubs))
+	for i := range pubs {
+		pairs[i] = []string{pubs[i], ""}
+	}
+	keys, err := k.populate(ctx, pairs, nodeosApi)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != http.StatusOK {
-		j, e := json.MarshalIndent(json.RawMessage(body), "", "  ")
-		if e != nil {
-			return errors.New("could not open wallet. Ensure it is unlocked, or use '-p' to provide the password")
-		}
-		return errors.New(string(j))
+	if k.keys == nil {
+		k.keys = newKeyMap()
+	}
+	for _, key := range keys {
+		k.keys.set(key)
 	}
+	return nil
+}
 
-	// build a map of available keys by actor:
-	pubKeys := make([][]string, 0)
-	_ = json.Unmarshal(body, &pubKeys)
+// populate builds the keys from [public, private] pairs, looking up the FIO address and account details
+func (k *KeosClient) populate(ctx context.Context, pubKeys [][]string, nodeosApi *fio.API) ([]KeosKeys, error) {
 	if len(pubKeys) == 0 {
-		return errors.New("no keys found in the wallet")
+		return nil, errors.New("no keys found in the wallet")
+	}
+	// the same key can be in more than one wallet, only look it up once
+	unique := make([]string, 0, len(pubKeys))
+	addresses := make(map[string]string)
+	for _, pk := range pubKeys {
+		if _, ok := addresses[pk[0]]; !ok {
+			addresses[pk[0]] = ""
+			unique = append(unique, pk[0])
+		}
+	}
+	found := make([]string, len(unique))
+	workers := k.lookupWorkers
+	if workers < 1 {
+		workers = DefaultLookupWorkers
+	}
+	lookups := len(unique)
+	if nodeosApi == nil {
+		// offline, only the actors and public keys are filled in
+		lookups = 0
 	}
-	mux := sync.Mutex{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(pubKeys))
+	err := forRange(workers, lookups, func(i int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// a failed lookup only leaves the address blank
+		found[i], _ = fioFirstAddress(ctx, nodeosApi, unique[i])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, pub := range unique {
+		addresses[pub] = found[i]
+	}
+	keys := make([]KeosKeys, 0, len(unique))
+	seen := make(map[string]bool)
 	for _, pk := range pubKeys {
-		go func(pk []string) {
-			defer wg.Done()
-			a, e := fio.ActorFromPub(pk[0])
-			if e != nil {
-				return
+		a, e := fio.ActorFromPub(pk[0])
+		if e != nil || seen[pk[0]] {
+			continue
+		}
+		seen[pk[0]] = true
+		keys = append(keys, KeosKeys{
+			Actor:      string(a),
+			PublicKey:  pk[0],
+			PrivateKey: pk[1],
+			FioAddress: addresses[pk[0]],
+		})
+	}
+	if k.accountDetails && nodeosApi != nil {
+		err = forRange(workers, len(keys), func(i int) error {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-			first := firstName(pk[0], nodeosApi)
-			mux.Lock()
-			k.Keys[string(a)] = KeosKeys{
-				PublicKey:  pk[0],
-				PrivateKey: pk[1],
-				FioAddress: first,
+			// as with addresses, a failed lookup leaves the details empty
+			keys[i].Account, _ = GetAccountDetails(ctx, nodeosApi, keys[i].Actor)
+			if keys[i].Account != nil {
+				keys[i].Permissions = keys[i].Account.PermissionsForKey(keys[i].PublicKey)
 			}
-			mux.Unlock()
-		}(pk)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, ctx.Err()
+}
+
+// Create creates a new wallet, returning the generated password. The wallet is left unlocked. With WithCredentials
+// the password is also saved, if that fails both the password and an error are returned.
+func (k *KeosClient) Create(ctx context.Context, wallet string) (string, error) {
+	if wallet == "" {
+		return "", errors.New("wallet name cannot be empty")
+	}
+	var password string
+	if err := k.call(ctx, "/v1/wallet/create", wallet, &password); err != nil {
+		return "", err
+	}
+	if k.credentials != nil {
+		if err := k.credentials.SetPassword(wallet, password); err != nil {
+			// the wallet exists now, so the password must still reach the caller
+			return password, fmt.Errorf("wallet was created but the password could not be saved: %w", err)
+		}
+	}
+	return password, nil
+}
+
+// CreateKey creates a new key in an unlocked wallet and returns the public key, keyType is "K1" or "R1" and
+// defaults to "K1"
+func (k *KeosClient) CreateKey(ctx context.Context, wallet string, keyType string) (string, error) {
+	if keyType == "" {
+		keyType = "K1"
+	}
+	if keyType != "K1" && keyType != "R1" {
+		return "", fmt.Errorf("unsupported key type %q, must be K1 or R1", keyType)
+	}
+	var pub string
+	if err := k.call(ctx, "/v1/wallet/create_key", []string{wallet, keyType}, &pub); err != nil {
+		return "", err
+	}
+	k.sessions.setPublicKeys(wallet, nil)
+	return pub, nil
+}
+
+// ListKeys provides the [public, private] key pairs in an unlocked wallet, see GetKeys to load them with their
+// actors and addresses instead
+func (k *KeosClient) ListKeys(ctx context.Context, wallet string, password string) ([][]string, error) {
+	pairs := make([][]string, 0)
+	if err := k.call(ctx, "/v1/wallet/list_keys", []string{wallet, password}, &pairs); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// ImportKey adds a private key to an unlocked wallet, ErrKeyExists is returned if it is already there
+func (k *KeosClient) ImportKey(ctx context.Context, wallet string, wif string) error {
+	if wallet == "" || wif == "" {
+		return errors.New("wallet and key are required")
+	}
+	if err := k.call(ctx, "/v1/wallet/import_key", []string{wallet, wif}, nil); err != nil {
+		return err
 	}
-	wg.Wait()
+	k.sessions.setPublicKeys(wallet, nil)
 	return nil
 }
 
-// PrintKeys provides a human readable list of keys in a wallet
-func (k *KeosClient) PrintKeys() string {
-	buf := bytes.NewBufferString("")
-	buf.WriteString(fmt.Sprintf("\n%-12s  %-53s  %s\n", "Account", "Public Key", "FIO Address"))
-	buf.WriteString(fmt.Sprintf("%-12s  %-53s  %s\n", "⎺⎺⎺⎺⎺⎺⎺", "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺", "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺"))
-	for k, v := range k.Keys {
-		buf.WriteString(fmt.Sprintf("%12s  %53s  %s\n", k, v.PublicKey, v.FioAddress))
+// RemoveKey deletes a key from a wallet, ErrKeyNotFound is returned if it is not in the wallet
+func (k *KeosClient) RemoveKey(ctx context.Context, wallet string, password string, pub string) error {
+	if wallet == "" || password == "" || pub == "" {
+		return errors.New("wallet, password, and public key are required")
+	}
+	if err := k.call(ctx, "/v1/wallet/remove_key", []string{wallet, password, pub}, nil); err != nil {
+		return err
+	}
+	k.sessions.setPublicKeys(wallet, nil)
+	return nil
+}
+
+// Lock locks a wallet
+func (k *KeosClient) Lock(ctx context.Context, wallet string) error {
+	if wallet == "" {
+		return errors.New("wallet name cannot be empty")
+	}
+	if err := k.call(ctx, "/v1/wallet/lock", wallet, nil); err != nil {
+		return err
+	}
+	k.sessions.remove(wallet)
+	return nil
+}
+
+// LockAll locks every wallet that keosd has open
+func (k *KeosClient) LockAll(ctx context.Context) error {
+	if err := k.call(ctx, "/v1/wallet/lock_all", nil, nil); err != nil {
+		return err
+	}
+	k.sessions.clear()
+	return nil
+}
+
+// SetTimeout sets how long keosd keeps wallets unlocked without activity
+func (k *KeosClient) SetTimeout(ctx context.Context, seconds int64) error {
+	if seconds < 1 {
+		return errors.New("timeout must be at least one second")
+	}
+	return k.call(ctx, "/v1/wallet/set_timeout", seconds, nil)
+}
+
+// KeosWallet is a wallet that keosd has open
+type KeosWallet struct {
+	Name     string `json:"name"`
+	Unlocked bool   `json:"unlocked"`
+}
+
+// ListWallets lists the wallets keosd has open, keosd marks unlocked wallets with a trailing "*"
+func (k *KeosClient) ListWallets(ctx context.Context) ([]KeosWallet, error) {
+	names := make([]string, 0)
+	if err := k.call(ctx, "/v1/wallet/list_wallets", nil, &names); err != nil {
+		return nil, err
+	}
+	wallets := make([]KeosWallet, len(names))
+	for i, name := range names {
+		wallets[i] = parseWalletName(name)
+	}
+	return wallets, nil
+}
+
+func parseWalletName(name string) KeosWallet {
+	return KeosWallet{
+		Name:     strings.TrimSuffix(strings.TrimSpace(name), " *"),
+		Unlocked: strings.HasSuffix(name, " *"),
+	}
+}
+
+// IsUnlocked reports whether a wallet is open and unlocked
+func (k *KeosClient) IsUnlocked(ctx context.Context, wallet string) (bool, error) {
+	wallets, err := k.ListWallets(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, w := range wallets {
+		if w.Name == wallet {
+			return w.Unlocked, nil
+		}
+	}
+	return false, nil
+}
+
+// SignDigest asks keosd to sign a 32 byte digest with the key for pub, the wallet holding it must be unlocked
+func (k *KeosClient) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	if len(digest) != 32 {
+		return ecc.Signature{}, errors.New("digest must be 32 bytes")
+	}
+	var sig string
+	if err := k.call(ctx, "/v1/wallet/sign_digest", []string{hex.EncodeToString(digest), pub}, &sig); err != nil {
+		return ecc.Signature{}, err
+	}
+	return ecc.NewSignature(sig)
+}
+
+// SignTransaction asks keosd to sign a transaction with each of pubkeys, returning the new signatures. tx is
+// anything that marshals to a transaction with serialized (hex) action data, such as the output of clio
+// --skip-sign --dont-broadcast --json or a json.RawMessage. chainID is hex.
+func (k *KeosClient) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	if tx == nil {
+		return nil, errors.New("transaction cannot be nil")
+	}
+	if len(pubkeys) == 0 {
+		return nil, errors.New("at least one public key is required")
+	}
+	if id, err := hex.DecodeString(chainID); err != nil || len(id) != 32 {
+		return nil, errors.New("chain ID must be 32 bytes of hex")
+	}
+	signed := struct {
+		Signatures []string `json:"signatures"`
+	}{}
+	if err := k.call(ctx, "/v1/wallet/sign_transaction", []interface{}{tx, pubkeys, chainID}, &signed); err != nil {
+		return nil, err
+	}
+	// keosd returns every signature on the transaction, including any it already had
+	existing := struct {
+		Signatures []string `json:"signatures"`
+	}{}
+	if b, err := json.Marshal(tx); err == nil {
+		_ = json.Unmarshal(b, &existing)
+	}
+	had := make(map[string]bool)
+	for _, s := range existing.Signatures {
+		had[s] = true
+	}
+	sigs := make([]ecc.Signature, 0, len(signed.Signatures))
+	for _, s := range signed.Signatures {
+		if had[s] {
+			continue
+		}
+		sig, err := ecc.NewSignature(s)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// GetPublicKeys lists the public keys in all unlocked wallets without exporting the private keys
+func (k *KeosClient) GetPublicKeys(ctx context.Context) ([]string, error) {
+	keys := make([]string, 0)
+	if err := k.call(ctx, "/v1/wallet/get_public_keys", nil, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// call posts params as JSON to a keosd endpoint and decodes the response into result, which may be nil
+func (k *KeosClient) call(ctx context.Context, path string, params interface{}, result interface{}) error {
+	err := k.post(ctx, path, params, result)
+	if !k.AutoUnlock || path == "/v1/wallet/unlock" || !errors.Is(err, ErrWalletLocked) {
+		return err
+	}
+	if e := k.reunlock(ctx); e != nil {
+		return err
+	}
+	return k.post(ctx, path, params, result)
+}
+
+// post makes a request to keosd, retrying according to the client's retry policy
+func (k *KeosClient) post(ctx context.Context, path string, params interface{}, result interface{}) error {
+	var payload []byte
+	if params != nil {
+		var err error
+		if payload, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+	attempts := k.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	retryable := k.retry.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if e := sleepContext(ctx, k.retry.backoff(attempt)); e != nil {
+				return e
+			}
+		}
+		if err = k.send(ctx, path, payload, result); err == nil || !retryable(err) {
+			return err
+		}
+		if keosdNotIdempotent[path] && !notSent(err) {
+			// keosd may have acted on it, repeating would create a second wallet or key
+			return err
+		}
+	}
+	return err
+}
+
+// keosdNotIdempotent lists the endpoints that cannot safely be repeated, they are only retried when the request
+// never reached keosd
+var keosdNotIdempotent = map[string]bool{
+	"/v1/wallet/create":     true,
+	"/v1/wallet/create_key": true,
+	"/v1/wallet/import_key": true,
+	"/v1/wallet/remove_key": true,
+}
+
+// notSent reports whether a request failed before it could be delivered
+func notSent(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT)
+}
+
+// send makes a single request to keosd
+func (k *KeosClient) send(ctx context.Context, path string, payload []byte, result interface{}) (err error) {
+	if k.metrics != nil {
+		defer func(start time.Time) {
+			k.metrics.ObserveRequest("keosd", path, time.Since(start), err)
+		}(time.Now())
+	}
+	if k.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.requestTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.BaseUrl+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	resp, err := k.HttpClient.Do(req)
+	if err != nil {
+		k.logf("keosd %s failed after %v: %v", path, time.Since(start), err)
+		k.trace(TraceEvent{Method: http.MethodPost, Path: path, Duration: time.Since(start), Err: err}, payload, nil)
+		return err
+	}
+	k.logf("keosd %s returned %d in %v", path, resp.StatusCode, time.Since(start))
+	body, err := readLimited(resp.Body, k.maxResponseSize)
+	_ = resp.Body.Close()
+	k.trace(TraceEvent{Method: http.MethodPost, Path: path, StatusCode: resp.StatusCode, Duration: time.Since(start), Err: err}, payload, body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newKeosError(resp.StatusCode, body)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+// readLimited reads a response body, returning ErrResponseTooLarge rather than reading more than limit bytes. A
+// limit of zero uses DefaultMaxResponseSize.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		limit = DefaultMaxResponseSize
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
 	}
-	return buf.String()
+	return b, nil
 }
@@ -0,0 +1,438 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// keosdServer answers keosd endpoints with canned responses, recording the request bodies
+func keosdServer(responses map[string]interface{}, requests map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if requests != nil {
+			requests[r.URL.Path] = string(body)
+		}
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code":500,"message":"Internal Service Error","error":{"code":3120002,"name":"wallet_nonexistent_exception","what":"Nonexistent wallet","details":[]}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestKeosCreate(t *testing.T) {
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/create":     "PW5KFWYKqvt63d4iNvedfDEPVZL227D3RQ1zpVFzuUwhMAJmRAYyX",
+		"/v1/wallet/create_key": "PUB_K1_6LgWGNcU7sUrB8NkJvvRBgcTSBCo7uH8pWs3mvcUPmE8hU6mFN",
+	}, requests)
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	password, err := k.Create(context.Background(), "test")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if password != "PW5KFWYKqvt63d4iNvedfDEPVZL227D3RQ1zpVFzuUwhMAJmRAYyX" || requests["/v1/wallet/create"] != `"test"` {
+		t.Error("unexpected create", password, requests["/v1/wallet/create"])
+	}
+	pub, err := k.CreateKey(context.Background(), "test", "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if pub == "" || requests["/v1/wallet/create_key"] != `["test","K1"]` {
+		t.Error("unexpected create_key", pub, requests["/v1/wallet/create_key"])
+	}
+	if _, err = k.CreateKey(context.Background(), "test", "EM"); err == nil {
+		t.Error("expected an error for an unsupported key type")
+	}
+}
+
+func TestKeosImportRemoveKey(t *testing.T) {
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/import_key": struct{}{},
+	}, requests)
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	if err := k.ImportKey(context.Background(), "test", "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"); err != nil {
+		t.Error(err)
+		return
+	}
+	if requests["/v1/wallet/import_key"] != `["test","5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"]` {
+		t.Error("unexpected import_key request", requests["/v1/wallet/import_key"])
+	}
+	err := k.RemoveKey(context.Background(), "test", "PW5", "FIO6LgWGNcU7sUrB8NkJvvRBgcTSBCo7uH8pWs3mvcUPmE8hU6mFN")
+	if err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Error("expected a nonexistent wallet error, got", err)
+	}
+}
+
+func TestKeosWalletPublicKeys(t *testing.T) {
+	var listed int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/wallet/list_keys":
+			atomic.AddInt32(&listed, 1)
+			_, _ = w.Write([]byte(`[["FIO6LgWGNcU7sUrB8NkJvvRBgcTSBCo7uH8pWs3mvcUPmE8hU6mFN","5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"]]`))
+		case "/v1/wallet/list_wallets":
+			_, _ = w.Write([]byte(`["test *"]`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	ctx := context.Background()
+	if err := k.Unlock(ctx, "PW5", "test"); err != nil {
+		t.Error(err)
+		return
+	}
+	// the private keys come along with list_keys, so they are only listed once per change to the wallet
+	for i := 0; i < 3; i++ {
+		pubs, err := k.WalletPublicKeys(ctx, "test")
+		if err != nil || len(pubs) != 1 || pubs[0] != "FIO6LgWGNcU7sUrB8NkJvvRBgcTSBCo7uH8pWs3mvcUPmE8hU6mFN" {
+			t.Error("unexpected public keys", pubs, err)
+		}
+	}
+	if err := k.ImportKey(ctx, "test", "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"); err != nil {
+		t.Error(err)
+	}
+	_, _ = k.WalletPublicKeys(ctx, "test")
+	if n := atomic.LoadInt32(&listed); n != 2 {
+		t.Error("expected keys to be listed once before and once after the import, got", n)
+	}
+}
+
+func TestKeosKeyErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		switch r.URL.Path {
+		case "/v1/wallet/import_key":
+			_, _ = w.Write([]byte(`{"code":500,"error":{"code":3120008,"name":"key_exist_exception","what":"Key already exists"}}`))
+		case "/v1/wallet/remove_key":
+			_, _ = w.Write([]byte(`{"code":500,"error":{"code":3120009,"name":"key_nonexistent_exception","what":"Nonexistent key"}}`))
+		}
+	}))
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	if err := k.ImportKey(context.Background(), "test", "5K"); !errors.Is(err, ErrKeyExists) {
+		t.Error("expected ErrKeyExists, got", err)
+	}
+	if err := k.RemoveKey(context.Background(), "test", "PW5", "FIO6"); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("expected ErrKeyNotFound, got", err)
+	}
+}
+
+func TestKeosLock(t *testing.T) {
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/lock":        struct{}{},
+		"/v1/wallet/lock_all":    struct{}{},
+		"/v1/wallet/set_timeout": struct{}{},
+	}, requests)
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	ctx := context.Background()
+	if err := k.Lock(ctx, "test"); err != nil {
+		t.Error(err)
+	}
+	if err := k.LockAll(ctx); err != nil {
+		t.Error(err)
+	}
+	if err := k.SetTimeout(ctx, 300); err != nil {
+		t.Error(err)
+	}
+	if requests["/v1/wallet/lock"] != `"test"` || requests["/v1/wallet/set_timeout"] != "300" {
+		t.Error("unexpected requests", requests)
+	}
+	if _, ok := requests["/v1/wallet/lock_all"]; !ok {
+		t.Error("lock_all was not called")
+	}
+}
+
+func TestKeosListWallets(t *testing.T) {
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/list_wallets": []string{"default *", "cold"},
+	}, requests)
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	ctx := context.Background()
+	wallets, err := k.ListWallets(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(wallets) != 2 || wallets[0] != (KeosWallet{"default", true}) || wallets[1] != (KeosWallet{"cold", false}) {
+		t.Error("unexpected wallets", wallets)
+	}
+	if unlocked, _ := k.IsUnlocked(ctx, "cold"); unlocked {
+		t.Error("cold should be locked")
+	}
+	if err = k.Unlock(ctx, "PW5", "default"); err != nil {
+		t.Error(err)
+	}
+	if _, ok := requests["/v1/wallet/unlock"]; ok {
+		t.Error("unlock should be skipped for an unlocked wallet")
+	}
+}
+
+func TestKeosSignTransaction(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sig, err := hd.SignMessageAt(0, []byte("not really a transaction"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/sign_transaction": map[string]interface{}{"signatures": []string{sig}},
+	}, requests)
+	defer server.Close()
+
+	pub, _ := hd.PubKeyAt(0)
+	sigs, err := NewKeosClient(server.URL, "").SignTransaction(context.Background(), json.RawMessage(`{"actions":[]}`),
+		[]string{pub.String()}, FioTestnetChainID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(sigs) != 1 || sigs[0].String() != sig {
+		t.Error("unexpected signatures", sigs)
+	}
+	if requests["/v1/wallet/sign_transaction"] != `[{"actions":[]},["`+pub.String()+`"],"`+FioTestnetChainID+`"]` {
+		t.Error("unexpected request", requests["/v1/wallet/sign_transaction"])
+	}
+
+	// a signature already on the transaction is not returned again
+	earlier, err := hd.SignMessageAt(1, []byte("not really a transaction"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	resigned := keosdServer(map[string]interface{}{
+		"/v1/wallet/sign_transaction": map[string]interface{}{"signatures": []string{earlier, sig}},
+	}, nil)
+	defer resigned.Close()
+	sigs, err = NewKeosClient(resigned.URL, "").SignTransaction(context.Background(), json.RawMessage(`{"actions":[],"signatures":["`+earlier+`"]}`),
+		[]string{pub.String()}, FioTestnetChainID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(sigs) != 1 || sigs[0].String() != sig {
+		t.Error("unexpected signatures", sigs)
+	}
+}
+
+func TestKeosGetPublicKeysOnly(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pub, _ := hd.PubKeyAt(0)
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/get_public_keys": []string{pub.String()},
+	}, requests)
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	if err = k.GetPublicKeysOnly(context.Background(), &fio.API{API: eos.API{}}); err != nil {
+		t.Error(err)
+		return
+	}
+	actor, _ := hd.ActorAt(0)
+	key, ok := k.KeyByActor(string(actor))
+	if !ok || key.PublicKey != pub.String() || key.PrivateKey != "" {
+		t.Error("unexpected keys", k.KeyList())
+	}
+	if _, ok = requests["/v1/wallet/list_keys"]; ok {
+		t.Error("list_keys should not be called")
+	}
+}
+
+func TestKeosGetKeysLookups(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := hd.PubKeys(12)
+	pairs := make([][]string, 0)
+	for _, pub := range pubs {
+		// every key is listed twice, as though it were in two wallets
+		pairs = append(pairs, []string{pub.String(), ""}, []string{pub.String(), ""})
+	}
+	keosd := keosdServer(map[string]interface{}{"/v1/wallet/list_keys": pairs}, nil)
+	defer keosd.Close()
+
+	var lookups, active, maxActive int32
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), pubs[0].String()) {
+			_, _ = w.Write([]byte(`{"fio_domains":[],"fio_addresses":[{"fio_address":"first@fiotestnet"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer nodeos.Close()
+
+	k := NewKeosClient(keosd.URL, "", WithLookupWorkers(3))
+	if err = k.GetKeys(context.Background(), &fio.API{API: eos.API{BaseURL: nodeos.URL}}); err != nil {
+		t.Error(err)
+		return
+	}
+	if atomic.LoadInt32(&lookups) != 12 || atomic.LoadInt32(&maxActive) > 3 {
+		t.Error("expected 12 lookups with at most 3 at a time", lookups, maxActive)
+	}
+	if key, ok := k.KeyByAddress("first@fiotestnet"); !ok || key.PublicKey != pubs[0].String() {
+		t.Error("address was not found", k.KeyList())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = k.GetKeys(ctx, &fio.API{API: eos.API{BaseURL: nodeos.URL}}); !errors.Is(err, context.Canceled) {
+		t.Error("expected a cancelled error, got", err)
+	}
+}
+
+func TestKeosGetKeysOffline(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := hd.keyAt(0)
+	pub := key.PublicKey()
+	keosd := keosdServer(map[string]interface{}{"/v1/wallet/list_keys": [][]string{{pub.String(), key.String()}}}, nil)
+	defer keosd.Close()
+
+	k := NewKeosClient(keosd.URL, "")
+	if err = k.GetKeys(context.Background(), nil); err != nil {
+		t.Error(err)
+		return
+	}
+	actor, _ := hd.ActorAt(0)
+	if loaded, ok := k.KeyByActor(string(actor)); !ok || loaded.PrivateKey != "" || loaded.FioAddress != "" {
+		t.Error("unexpected keys", k.KeyList())
+	}
+	if revealed, err := k.RevealKey(string(actor)); err != nil || revealed != key.String() {
+		t.Error("private key was not revealed", err)
+	}
+}
+
+func TestKeosAccountDetails(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := hd.PubKeys(2)
+	keosd := keosdServer(map[string]interface{}{"/v1/wallet/get_public_keys": []string{pubs[0].String(), pubs[1].String()}}, nil)
+	defer keosd.Close()
+	active, _ := hd.ActorAt(0)
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path != "/v1/chain/get_account" || !strings.Contains(string(body), string(active)) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the owner permission has been changed to a different key
+		_, _ = w.Write([]byte(`{"account_name":"` + string(active) + `","ram_quota":5000,"ram_usage":3000,"permissions":[
+			{"perm_name":"active","parent":"owner","required_auth":{"threshold":1,"keys":[{"key":"` + pubs[0].String() + `","weight":1}],"accounts":[],"waits":[]}},
+			{"perm_name":"owner","parent":"","required_auth":{"threshold":1,"keys":[{"key":"` + pubs[1].String() + `","weight":1}],"accounts":[],"waits":[]}}]}`))
+	}))
+	defer nodeos.Close()
+
+	k := NewKeosClient(keosd.URL, "", WithAccountDetails())
+	if err = k.GetPublicKeysOnly(context.Background(), &fio.API{API: eos.API{BaseURL: nodeos.URL}}); err != nil {
+		t.Error(err)
+		return
+	}
+	key, ok := k.KeyByActor(string(active))
+	if !ok || key.Account == nil || key.Account.RamUsage != 3000 || strings.Join(key.Permissions, ",") != "active" {
+		t.Errorf("unexpected account details %+v", key)
+	}
+	other, _ := hd.ActorAt(1)
+	if key, ok = k.KeyByActor(string(other)); !ok || key.Account != nil {
+		t.Errorf("expected no account details for %s", other)
+	}
+	if !strings.Contains(k.PrintKeys(), "  active\n") || !strings.Contains(k.PrintKeys(), "FIO Address               Permissions\n") {
+		t.Error("permissions were not printed", k.PrintKeys())
+	}
+}
+
+func TestKeosHealth(t *testing.T) {
+	server := keosdServer(map[string]interface{}{"/v1/wallet/list_wallets": []string{"default *", "cold"}}, nil)
+	k := NewKeosClient(server.URL, "")
+	ctx := context.Background()
+	if err := k.Ping(ctx); err != nil {
+		t.Error(err)
+	}
+	h, err := k.Health(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(h.Wallets) != 2 || !h.Wallets[0].Unlocked || h.Latency <= 0 {
+		t.Errorf("unexpected health %+v", h)
+	}
+	// keosd is already running, so clio is not needed
+	if err = k.Start(ctx, false); err != nil {
+		t.Error(err)
+	}
+	server.Close()
+	if err = k.Ping(ctx); err == nil {
+		t.Error("expected ping to fail once keosd is gone")
+	}
+}
+
+func TestKeosResponseTooLarge(t *testing.T) {
+	server := keosdServer(map[string]interface{}{"/v1/wallet/list_wallets": []string{strings.Repeat("a", 1024)}}, nil)
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "", WithMaxResponseSize(512))
+	if _, err := k.ListWallets(context.Background()); !errors.Is(err, ErrResponseTooLarge) {
+		t.Error("expected ErrResponseTooLarge, got", err)
+	}
+	k = NewKeosClient(server.URL, "", WithMaxResponseSize(2048))
+	if _, err := k.ListWallets(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
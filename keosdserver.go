@@ -0,0 +1,472 @@
+package fiox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeosdServer speaks the keosd wallet API so clio and other SDKs can use this package's wallets. Wallets come from
+// a LocalWallet, and any Signer, such as one from Hd.Signer, can be added as a read-only wallet that is always
+// unlocked. Serve it on a unix socket with ListenAndServe, or use it as an http.Handler.
+type KeosdServer struct {
+	wallet *LocalWallet
+
+	mux     sync.RWMutex
+	signers map[string]Signer
+}
+
+// keosdExceptionCodes are the codes keosd uses with each exception name in keosExceptions
+var keosdExceptionCodes = map[string]int{
+	"wallet_exist_exception":            3120001,
+	"wallet_nonexistent_exception":      3120002,
+	"wallet_locked_exception":           3120003,
+	"wallet_missing_pub_key_exception":  3120004,
+	"wallet_invalid_password_exception": 3120005,
+	"wallet_unlocked_exception":         3120007,
+	"key_exist_exception":               3120008,
+	"key_nonexistent_exception":         3120009,
+}
+
+// NewKeosdServer serves the wallets in wallet, which may be nil when only signers are added
+func NewKeosdServer(wallet *LocalWallet) *KeosdServer {
+	return &KeosdServer{wallet: wallet, signers: make(map[string]Signer)}
+}
+
+// AddSigner adds signer as an unlocked wallet called name. Its keys can be listed and used for signing but not
+// exported, imported, or removed.
+func (ks *KeosdServer) AddSigner(name string, signer Signer) error {
+	if err := validWalletName(name); err != nil {
+		return err
+	}
+	if signer == nil {
+		return errors.New("signer cannot be nil")
+	}
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+	if ks.signers[name] != nil {
+		return ErrWalletExists
+	}
+	ks.signers[name] = signer
+	return nil
+}
+
+// ListenAndServe serves on a unix socket until ctx is done. A stale socket file left by a previous run is removed,
+// and the socket is only accessible to the current user.
+func (ks *KeosdServer) ListenAndServe(ctx context.Context, socket string) error {
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+			_ = conn.Close()
+			return fmt.Errorf("%s is already in use", socket)
+		}
+		if err = os.Remove(socket); err != nil {
+			return err
+		}
+	}
+	// the socket is created in a private directory and only moved into place once other users can't connect to it
+	private, err := ioutil.TempDir(filepath.Dir(socket), ".keosd")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(private)
+	l, err := net.Listen("unix", filepath.Join(private, "keosd.sock"))
+	if err != nil {
+		return err
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err = os.Chmod(filepath.Join(private, "keosd.sock"), 0600); err == nil {
+		err = os.Rename(filepath.Join(private, "keosd.sock"), socket)
+	}
+	if err != nil {
+		_ = l.Close()
+		return err
+	}
+	defer os.Remove(socket)
+	server := &http.Server{Handler: ks, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	if err = server.Serve(l); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP handles a keosd wallet API request
+func (ks *KeosdServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := make([]json.RawMessage, 0)
+	body, err := readLimited(r.Body, DefaultMaxResponseSize)
+	if err == nil && len(body) > 0 {
+		// most endpoints take an array, a few a single value
+		if err = json.Unmarshal(body, &params); err != nil {
+			params, err = []json.RawMessage{body}, nil
+		}
+	}
+	var result interface{}
+	if err == nil {
+		result, err = ks.handle(r.Context(), r.URL.Path, params)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		writeKeosdError(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (ks *KeosdServer) handle(ctx context.Context, path string, params []json.RawMessage) (interface{}, error) {
+	empty := struct{}{}
+	var wallet, password string
+	switch path {
+	case "/v1/wallet/create":
+		if err := decodeParams(params, &wallet); err != nil {
+			return nil, err
+		}
+		if ks.signer(wallet) != nil {
+			return nil, ErrWalletExists
+		}
+		lw, err := ks.local()
+		if err != nil {
+			return nil, err
+		}
+		return lw.Create(ctx, wallet)
+
+	case "/v1/wallet/open":
+		if err := decodeParams(params, &wallet); err != nil {
+			return nil, err
+		}
+		if ks.signer(wallet) != nil {
+			return empty, nil
+		}
+		wallets, err := ks.wallets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range wallets {
+			if w.Name == wallet {
+				return empty, nil
+			}
+		}
+		return nil, ErrWalletNotFound
+
+	case "/v1/wallet/list_wallets":
+		wallets, err := ks.wallets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, len(wallets))
+		for i, w := range wallets {
+			names[i] = w.Name
+			if w.Unlocked {
+				names[i] += " *"
+			}
+		}
+		return names, nil
+
+	case "/v1/wallet/unlock":
+		if err := decodeParams(params, &wallet, &password); err != nil {
+			return nil, err
+		}
+		if ks.signer(wallet) != nil {
+			return nil, ErrWalletUnlocked
+		}
+		lw, err := ks.local()
+		if err != nil {
+			return nil, err
+		}
+		return empty, lw.Unlock(ctx, password, wallet)
+
+	case "/v1/wallet/lock":
+		if err := decodeParams(params, &wallet); err != nil {
+			return nil, err
+		}
+		if ks.signer(wallet) != nil {
+			return empty, nil
+		}
+		lw, err := ks.local()
+		if err != nil {
+			return nil, err
+		}
+		return empty, lw.Lock(ctx, wallet)
+
+	case "/v1/wallet/lock_all":
+		if ks.wallet != nil {
+			return empty, ks.wallet.LockAll(ctx)
+		}
+		return empty, nil
+
+	case "/v1/wallet/set_timeout":
+		var seconds int64
+		if err := decodeParams(params, &seconds); err != nil {
+			return nil, err
+		}
+		if ks.wallet != nil {
+			ks.wallet.mux.Lock()
+			ks.wallet.UnlockTimeout = time.Duration(seconds) * time.Second
+			ks.wallet.mux.Unlock()
+		}
+		return empty, nil
+
+	case "/v1/wallet/list_keys":
+		if err := decodeParams(params, &wallet, &password); err != nil {
+			return nil, err
+		}
+		if ks.signer(wallet) != nil {
+			return nil, fmt.Errorf("the keys of %s cannot be exported", wallet)
+		}
+		lw, err := ks.local()
+		if err != nil {
+			return nil, err
+		}
+		return lw.ListKeys(ctx, wallet, password)
+
+	case "/v1/wallet/get_public_keys":
+		return ks.publicKeys(ctx)
+
+	case "/v1/wallet/import_key":
+		var wif string
+		if err := decodeParams(params, &wallet, &wif); err != nil {
+			return nil, err
+		}
+		lw, err := ks.writable(wallet)
+		if err != nil {
+			return nil, err
+		}
+		return empty, lw.ImportKey(ctx, wallet, wif)
+
+	case "/v1/wallet/create_key":
+		var keyType string
+		if err := decodeParams(params, &wallet, &keyType); err != nil {
+			return nil, err
+		}
+		if keyType != "" && keyType != "K1" {
+			return nil, fmt.Errorf("unsupported key type %q, only K1 keys can be created", keyType)
+		}
+		lw, err := ks.writable(wallet)
+		if err != nil {
+			return nil, err
+		}
+		return lw.CreateKey(ctx, wallet)
+
+	case "/v1/wallet/remove_key":
+		var pub string
+		if err := decodeParams(params, &wallet, &password, &pub); err != nil {
+			return nil, err
+		}
+		lw, err := ks.writable(wallet)
+		if err != nil {
+			return nil, err
+		}
+		return empty, lw.RemoveKey(ctx, wallet, password, pub)
+
+	case "/v1/wallet/sign_digest":
+		var digest, pub string
+		if err := decodeParams(params, &digest, &pub); err != nil {
+			return nil, err
+		}
+		hash, err := hex.DecodeString(digest)
+		if err != nil || len(hash) != 32 {
+			return nil, errors.New("digest must be 32 bytes of hex")
+		}
+		signer, err := ks.signerFor(ctx, pub)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := signer.SignDigest(ctx, hash, pub)
+		if err != nil {
+			return nil, err
+		}
+		return sig.String(), nil
+
+	case "/v1/wallet/sign_transaction":
+		var tx json.RawMessage
+		var pubs []string
+		var chainID string
+		if err := decodeParams(params, &tx, &pubs, &chainID); err != nil {
+			return nil, err
+		}
+		return ks.signTransaction(ctx, tx, pubs, chainID)
+	}
+	return nil, fmt.Errorf("unknown endpoint %s", path)
+}
+
+// signTransaction signs with each key using whichever wallet holds it, returning the transaction with the
+// signatures added as keosd does
+func (ks *KeosdServer) signTransaction(ctx context.Context, tx json.RawMessage, pubs []string, chainID string) (interface{}, error) {
+	signed := make(map[string]interface{})
+	if err := json.Unmarshal(tx, &signed); err != nil {
+		return nil, err
+	}
+	signatures := make([]interface{}, 0)
+	if existing, ok := signed["signatures"].([]interface{}); ok {
+		signatures = existing
+	}
+	for _, pub := range pubs {
+		signer, err := ks.signerFor(ctx, pub)
+		if err != nil {
+			return nil, err
+		}
+		sigs, err := signer.SignTransaction(ctx, tx, []string{pub}, chainID)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, sigs[0].String())
+	}
+	signed["signatures"] = signatures
+	return signed, nil
+}
+
+func (ks *KeosdServer) signer(name string) Signer {
+	ks.mux.RLock()
+	defer ks.mux.RUnlock()
+	return ks.signers[name]
+}
+
+func (ks *KeosdServer) local() (*LocalWallet, error) {
+	if ks.wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+	return ks.wallet, nil
+}
+
+// writable provides the LocalWallet for changing the keys of wallet, signer wallets cannot be changed
+func (ks *KeosdServer) writable(wallet string) (*LocalWallet, error) {
+	if ks.signer(wallet) != nil {
+		return nil, fmt.Errorf("the keys of %s cannot be changed", wallet)
+	}
+	return ks.local()
+}
+
+// wallets lists the LocalWallet wallets and the signers, sorted by name
+func (ks *KeosdServer) wallets(ctx context.Context) ([]KeosWallet, error) {
+	wallets := make([]KeosWallet, 0)
+	if ks.wallet != nil {
+		local, err := ks.wallet.ListWallets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, local...)
+	}
+	ks.mux.RLock()
+	for name := range ks.signers {
+		wallets = append(wallets, KeosWallet{Name: name, Unlocked: true})
+	}
+	ks.mux.RUnlock()
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i].Name < wallets[j].Name })
+	return wallets, nil
+}
+
+// publicKeys lists the keys of every unlocked wallet, ErrWalletLocked when there are none
+func (ks *KeosdServer) publicKeys(ctx context.Context) ([]string, error) {
+	pubs := make([]string, 0)
+	unlocked := false
+	if ks.wallet != nil {
+		local, err := ks.wallet.GetPublicKeys(ctx)
+		if err != nil && !errors.Is(err, ErrWalletLocked) {
+			return nil, err
+		}
+		unlocked = err == nil
+		pubs = append(pubs, local...)
+	}
+	ks.mux.RLock()
+	signers := make([]Signer, 0, len(ks.signers))
+	for _, s := range ks.signers {
+		signers = append(signers, s)
+	}
+	ks.mux.RUnlock()
+	for _, s := range signers {
+		keys, err := s.PublicKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		unlocked = true
+		pubs = append(pubs, keys...)
+	}
+	if !unlocked {
+		return nil, ErrWalletLocked
+	}
+	sort.Strings(pubs)
+	return pubs, nil
+}
+
+// signerFor finds the unlocked wallet or signer holding pub
+func (ks *KeosdServer) signerFor(ctx context.Context, pub string) (Signer, error) {
+	content := keyContent(pub)
+	locked := true
+	if ks.wallet != nil {
+		local, err := ks.wallet.GetPublicKeys(ctx)
+		if err == nil && containsKeyContent(local, content) {
+			return ks.wallet, nil
+		}
+		locked = errors.Is(err, ErrWalletLocked)
+	}
+	ks.mux.RLock()
+	defer ks.mux.RUnlock()
+	for _, s := range ks.signers {
+		locked = false
+		if keys, err := s.PublicKeys(ctx); err == nil && containsKeyContent(keys, content) {
+			return s, nil
+		}
+	}
+	if locked {
+		return nil, ErrWalletLocked
+	}
+	return nil, ErrWalletMissingKey
+}
+
+func containsKeyContent(pubs []string, content string) bool {
+	for _, p := range pubs {
+		if keyContent(p) == content {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeParams decodes the positional parameters of a keosd request, missing trailing parameters are left empty
+func decodeParams(params []json.RawMessage, dest ...interface{}) error {
+	if len(params) > len(dest) || (len(dest) > 0 && len(params) == 0) {
+		return errors.New("unexpected number of parameters")
+	}
+	for i := range params {
+		if err := json.Unmarshal(params[i], dest[i]); err != nil {
+			return fmt.Errorf("invalid parameter %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// writeKeosdError writes an error in the format keosd uses, so KeosClient and clio recognize the exceptions
+func writeKeosdError(w http.ResponseWriter, err error) {
+	code, name := 3200006, "invalid_http_request"
+	status := http.StatusBadRequest
+	for exception, sentinel := range keosExceptions {
+		if errors.Is(err, sentinel) {
+			code, name, status = keosdExceptionCodes[exception], exception, http.StatusInternalServerError
+			break
+		}
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    status,
+		"message": http.StatusText(status),
+		"error": map[string]interface{}{
+			"code":    code,
+			"name":    name,
+			"what":    strings.TrimSpace(err.Error()),
+			"details": []interface{}{},
+		},
+	})
+}
@@ -0,0 +1,126 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestKeosdServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keosd")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	lw, err := NewLocalWallet(filepath.Join(dir, "wallets"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	signer, _ := hd.Signer(1)
+	server := NewKeosdServer(lw)
+	if err = server.AddSigner("hd", signer); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = server.AddSigner("hd", signer); !errors.Is(err, ErrWalletExists) {
+		t.Error("expected ErrWalletExists, got", err)
+	}
+
+	socket := filepath.Join(dir, "keosd.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- server.ListenAndServe(ctx, socket) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		if _, err = os.Stat(socket); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if info, err := os.Stat(socket); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0600) {
+		t.Error("expected a socket only the owner can use", info, err)
+	}
+
+	client := NewKeosClient("", socket)
+	password, err := client.Create(ctx, "default")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = client.Create(ctx, "default"); !errors.Is(err, ErrWalletExists) {
+		t.Error("expected ErrWalletExists, got", err)
+	}
+	if err = client.Lock(ctx, "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = client.Unlock(ctx, "wrong", "default"); !errors.Is(err, ErrInvalidPassword) {
+		t.Error("expected ErrInvalidPassword, got", err)
+	}
+	if err = client.Unlock(ctx, password, "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	wif := "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
+	if err = client.ImportKey(ctx, "default", wif); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = client.ImportKey(ctx, "hd", wif); err == nil {
+		t.Error("expected importing into a signer wallet to fail")
+	}
+	wallets, err := client.ListWallets(ctx)
+	if err != nil || len(wallets) != 2 || !wallets[0].Unlocked || wallets[1].Name != "hd" || !wallets[1].Unlocked {
+		t.Error("unexpected wallets", wallets, err)
+	}
+	pairs, err := client.ListKeys(ctx, "default", password)
+	if err != nil || len(pairs) != 1 || pairs[0][1] != wif {
+		t.Error("unexpected keys", pairs, err)
+	}
+
+	hdKeys, _ := signer.PublicKeys(ctx)
+	pubs, err := client.GetPublicKeys(ctx)
+	if err != nil || len(pubs) != 2 {
+		t.Error("expected the keys of both wallets", pubs, err)
+		return
+	}
+	sigs, err := client.SignTransaction(ctx, transferTx(hdKeys[0], 1), []string{pairs[0][0], hdKeys[0]}, FioMainnetChainID)
+	if err != nil || len(sigs) != 2 {
+		t.Error("expected two signatures", sigs, err)
+	}
+	digest := bytes.Repeat([]byte{7}, 32)
+	sig, err := client.SignDigest(ctx, digest, hdKeys[0])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	derived, _ := hd.KeySet(0, 1)
+	if !sig.Verify(digest, *derived.Keys[0].PublicKey) {
+		t.Error("signature did not verify")
+	}
+
+	if err = client.LockAll(ctx); err != nil {
+		t.Error(err)
+	}
+	if _, err = client.SignDigest(ctx, digest, pairs[0][0]); !errors.Is(err, ErrWalletMissingKey) {
+		t.Error("expected ErrWalletMissingKey once locked, got", err)
+	}
+}
@@ -0,0 +1,87 @@
+package fiox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errors for common keosd exceptions, use errors.Is on errors returned by KeosClient
+var (
+	ErrWalletLocked     = errors.New("wallet is locked")
+	ErrWalletUnlocked   = errors.New("wallet is already unlocked")
+	ErrInvalidPassword  = errors.New("invalid wallet password")
+	ErrWalletNotFound   = errors.New("wallet does not exist")
+	ErrWalletExists     = errors.New("wallet already exists")
+	ErrWalletMissingKey = errors.New("wallet does not hold the requested public key")
+	ErrKeyExists        = errors.New("key already exists in the wallet")
+	ErrKeyNotFound      = errors.New("key not found in the wallet")
+)
+
+// ErrResponseTooLarge is returned when keosd or nodeos sends a response larger than the configured limit, see
+// WithMaxResponseSize and MaxChainResponseSize
+var ErrResponseTooLarge = errors.New("response is too large")
+
+// keosExceptions maps the exception names keosd uses to the sentinel errors
+var keosExceptions = map[string]error{
+	"wallet_locked_exception":           ErrWalletLocked,
+	"wallet_unlocked_exception":         ErrWalletUnlocked,
+	"wallet_invalid_password_exception": ErrInvalidPassword,
+	"wallet_nonexistent_exception":      ErrWalletNotFound,
+	"wallet_exist_exception":            ErrWalletExists,
+	"wallet_missing_pub_key_exception":  ErrWalletMissingKey,
+	"key_exist_exception":               ErrKeyExists,
+	"key_nonexistent_exception":         ErrKeyNotFound,
+}
+
+// KeosErrorDetail is one entry in the details of a keosd error
+type KeosErrorDetail struct {
+	Message    string `json:"message"`
+	File       string `json:"file"`
+	LineNumber int    `json:"line_number"`
+	Method     string `json:"method"`
+}
+
+// KeosError is the error envelope returned by keosd for a failed request
+type KeosError struct {
+	StatusCode int               `json:"-"`
+	Code       int               `json:"code"`
+	Name       string            `json:"name"`
+	What       string            `json:"what"`
+	Details    []KeosErrorDetail `json:"details"`
+}
+
+func newKeosError(status int, body []byte) *KeosError {
+	envelope := struct {
+		Error KeosError `json:"error"`
+	}{}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error.Name == "" {
+		// not from keosd, perhaps a proxy in the way
+		return &KeosError{StatusCode: status, What: strings.TrimSpace(string(body))}
+	}
+	envelope.Error.StatusCode = status
+	return &envelope.Error
+}
+
+func (e *KeosError) Error() string {
+	if e.Name == "" {
+		if e.What == "" {
+			return fmt.Sprintf("keosd returned status %d", e.StatusCode)
+		}
+		return fmt.Sprintf("keosd returned status %d: %s", e.StatusCode, e.What)
+	}
+	msg := fmt.Sprintf("keosd error %d %s: %s", e.Code, e.Name, e.What)
+	for _, d := range e.Details {
+		if d.Message != "" {
+			msg += ": " + d.Message
+		}
+	}
+	return msg
+}
+
+// Is matches the keosd exception name against the sentinel errors
+func (e *KeosError) Is(target error) bool {
+	sentinel, ok := keosExceptions[e.Name]
+	return ok && sentinel == target
+}
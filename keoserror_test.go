@@ -0,0 +1,84 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestKeosError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		switch r.URL.Path {
+		case "/v1/wallet/unlock":
+			_, _ = w.Write([]byte(`{"code":500,"message":"Internal Service Error","error":{"code":3120005,"name":"wallet_invalid_password_exception","what":"Invalid wallet password","details":[{"message":"Invalid password for wallet: \"/root/eosio-wallet/./default.wallet\"","file":"wallet.cpp","line_number":182,"method":"unlock"}]}}`))
+		case "/v1/wallet/sign_digest":
+			_, _ = w.Write([]byte(`{"code":500,"message":"Internal Service Error","error":{"code":3120003,"name":"wallet_locked_exception","what":"Locked wallet","details":[]}}`))
+		default:
+			_, _ = w.Write([]byte("bad gateway"))
+		}
+	}))
+	defer server.Close()
+
+	k := NewKeosClient(server.URL, "")
+	ctx := context.Background()
+	err := k.Unlock(ctx, "PW5", "default")
+	if !errors.Is(err, ErrInvalidPassword) {
+		t.Error("expected ErrInvalidPassword, got", err)
+	}
+	keosErr := &KeosError{}
+	if !errors.As(err, &keosErr) || keosErr.Code != 3120005 || len(keosErr.Details) != 1 || keosErr.Details[0].LineNumber != 182 {
+		t.Errorf("unexpected error %#v", keosErr)
+	}
+	if _, err = k.SignDigest(ctx, make([]byte, 32), "FIO6"); !errors.Is(err, ErrWalletLocked) {
+		t.Error("expected ErrWalletLocked, got", err)
+	}
+	if err = k.LockAll(ctx); !errors.As(err, &keosErr) || keosErr.StatusCode != 500 || keosErr.What != "bad gateway" {
+		t.Error("unexpected error for a non-keosd response", err)
+	}
+}
+
+func TestKeosAutoUnlock(t *testing.T) {
+	var locked, unlocks int32 = 1, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/wallet/list_wallets":
+			_, _ = w.Write([]byte(`[]`))
+		case "/v1/wallet/unlock":
+			atomic.AddInt32(&unlocks, 1)
+			atomic.StoreInt32(&locked, 0)
+			_, _ = w.Write([]byte(`{}`))
+		case "/v1/wallet/get_public_keys":
+			if atomic.LoadInt32(&locked) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"code":500,"error":{"code":3120003,"name":"wallet_locked_exception","what":"Locked wallet"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`["FIO6"]`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	k := NewKeosClient(server.URL, "")
+	if err := k.Unlock(ctx, "PW5", "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	atomic.StoreInt32(&locked, 1)
+	if _, err := k.GetPublicKeys(ctx); !errors.Is(err, ErrWalletLocked) {
+		t.Error("expected ErrWalletLocked without AutoUnlock, got", err)
+	}
+	k.AutoUnlock = true
+	keys, err := k.GetPublicKeys(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(keys) != 1 || atomic.LoadInt32(&unlocks) != 2 {
+		t.Error("expected a single re-unlock and retry", keys, unlocks)
+	}
+}
@@ -0,0 +1,228 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// KeosOption configures a KeosClient, see NewKeosClientOpts
+type KeosOption func(k *KeosClient)
+
+// KeosLogger receives a line for each request to keosd, *log.Logger satisfies it. Request bodies are never logged
+// since they contain passwords and keys.
+type KeosLogger interface {
+	Printf(format string, v ...interface{})
+}
+
+// DefaultLookupWorkers is the number of concurrent FIO name lookups made by GetKeys
+const DefaultLookupWorkers = 8
+
+// WithLookupWorkers limits the number of concurrent FIO name lookups made by GetKeys
+func WithLookupWorkers(n int) KeosOption {
+	return func(k *KeosClient) {
+		k.lookupWorkers = n
+	}
+}
+
+// WithAccountDetails makes GetKeys also query get_account for each actor, filling in KeosKeys.Account and
+// Permissions so it is clear whether a wallet key is the active key for its account
+func WithAccountDetails() KeosOption {
+	return func(k *KeosClient) {
+		k.accountDetails = true
+	}
+}
+
+// WithSocket connects to keosd using a unix socket
+func WithSocket(socket string) KeosOption {
+	return func(k *KeosClient) {
+		k.Socket = socket
+	}
+}
+
+// WithURL connects to keosd over HTTP, for example http://127.0.0.1:8900
+func WithURL(keosUrl string) KeosOption {
+	return func(k *KeosClient) {
+		k.BaseUrl = strings.TrimSuffix(keosUrl, "/")
+	}
+}
+
+// WithWalletURL connects to keosd using a clio style --wallet-url, either unix://path/to/keosd.sock or
+// http://host:port. An invalid URL is reported by NewKeosClientOpts.
+func WithWalletURL(walletUrl string) KeosOption {
+	return func(k *KeosClient) {
+		keosUrl, socket, err := ParseWalletURL(walletUrl)
+		if err != nil {
+			k.optErr = err
+			return
+		}
+		k.BaseUrl, k.Socket = keosUrl, socket
+	}
+}
+
+// ParseWalletURL splits a clio style --wallet-url into a keosd URL or a socket path, only one is returned. A leading
+// ~ in a socket path is expanded to the home directory.
+func ParseWalletURL(walletUrl string) (keosUrl string, socket string, err error) {
+	switch {
+	case strings.HasPrefix(walletUrl, "unix://"):
+		socket = strings.TrimPrefix(walletUrl, "unix://")
+		if socket == "" {
+			return "", "", errors.New("wallet url is missing the socket path")
+		}
+		if socket == "~" || strings.HasPrefix(socket, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", err
+			}
+			socket = filepath.Join(home, socket[1:])
+		}
+		return "", socket, nil
+	case strings.HasPrefix(walletUrl, "http://"), strings.HasPrefix(walletUrl, "https://"):
+		u, err := url.Parse(walletUrl)
+		if err != nil {
+			return "", "", err
+		}
+		if u.Host == "" {
+			return "", "", errors.New("wallet url is missing the host")
+		}
+		return strings.TrimSuffix(walletUrl, "/"), "", nil
+	}
+	return "", "", fmt.Errorf("unsupported wallet url %q, expected unix:// or http://", walletUrl)
+}
+
+// WithHTTPClient uses the provided http client instead of building one, it must be able to reach keosd
+func WithHTTPClient(client *http.Client) KeosOption {
+	return func(k *KeosClient) {
+		k.HttpClient = client
+	}
+}
+
+// DefaultMaxResponseSize is the largest response read from keosd or nodeos unless configured otherwise
+const DefaultMaxResponseSize = 16 << 20
+
+// WithMaxResponseSize limits the size of responses read from keosd, larger responses return ErrResponseTooLarge
+func WithMaxResponseSize(limit int64) KeosOption {
+	return func(k *KeosClient) {
+		k.maxResponseSize = limit
+	}
+}
+
+// WithLogger logs each request to keosd
+func WithLogger(logger KeosLogger) KeosOption {
+	return func(k *KeosClient) {
+		k.logger = logger
+	}
+}
+
+// WithTimeout limits the total time of a request including reading the response, see also WithRequestTimeout
+func WithTimeout(timeout time.Duration) KeosOption {
+	return func(k *KeosClient) {
+		k.timeout = timeout
+	}
+}
+
+// WithProxy sends requests to a keosd URL through an HTTP proxy, it does not apply to sockets or WithHTTPClient
+func WithProxy(proxy *url.URL) KeosOption {
+	return func(k *KeosClient) {
+		k.proxy = proxy
+	}
+}
+
+// RetryPolicy controls how failed keosd requests are retried. The delay starts at InitialBackoff and doubles after
+// each attempt up to MaxBackoff. Retryable decides which errors are retried, IsRetryable is used when it is nil.
+// Requests that create or change keys, such as create_key, are only retried when the connection failed, since a
+// lost response does not mean keosd did nothing.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Retryable      func(err error) bool
+}
+
+// DefaultRetryPolicy makes up to three attempts, suitable for riding out a keosd restart or socket hiccup
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+// WithRetry sets the retry policy, by default requests are not retried
+func WithRetry(policy RetryPolicy) KeosOption {
+	return func(k *KeosClient) {
+		k.retry = policy
+	}
+}
+
+// WithRequestTimeout limits how long each attempt of a request can take
+func WithRequestTimeout(timeout time.Duration) KeosOption {
+	return func(k *KeosClient) {
+		k.requestTimeout = timeout
+	}
+}
+
+// IsRetryable reports whether an error is likely transient: connection failures, timeouts of a single attempt, and
+// gateway errors from a proxy. Errors reported by keosd itself, and cancellation of the caller's context, are not
+// retried.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	keosErr := &KeosError{}
+	if errors.As(err, &keosErr) {
+		if keosErr.Name != "" {
+			return false
+		}
+		switch keosErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ENOENT) {
+		return true
+	}
+	netErr := net.Error(nil)
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (k *KeosClient) logf(format string, v ...interface{}) {
+	if k.logger != nil {
+		k.logger.Printf(format, v...)
+	}
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
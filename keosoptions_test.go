@@ -0,0 +1,166 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeosRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			// slower than the request timeout
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		default:
+			_, _ = w.Write([]byte(`["FIO6"]`))
+		}
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	k := NewKeosClient(server.URL, "", WithRetry(policy), WithRequestTimeout(50*time.Millisecond))
+	keys, err := k.GetPublicKeys(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(keys) != 1 || atomic.LoadInt32(&calls) != 3 {
+		t.Error("expected success on the third attempt", keys, calls)
+	}
+
+	// keosd exceptions are not retried
+	atomic.StoreInt32(&calls, 0)
+	locked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"code":500,"error":{"code":3120003,"name":"wallet_locked_exception","what":"Locked wallet"}}`))
+	}))
+	defer locked.Close()
+	k = NewKeosClient(locked.URL, "", WithRetry(policy))
+	if _, err = k.GetPublicKeys(context.Background()); !errors.Is(err, ErrWalletLocked) || atomic.LoadInt32(&calls) != 1 {
+		t.Error("expected a single attempt", err, calls)
+	}
+
+	// creating a key is not repeated once keosd may have seen the request
+	atomic.StoreInt32(&calls, 0)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer gateway.Close()
+	k = NewKeosClient(gateway.URL, "", WithRetry(policy))
+	if _, err = k.CreateKey(context.Background(), "default", "K1"); err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Error("expected create_key to be tried once", err, calls)
+	}
+	if _, err = k.GetPublicKeys(context.Background()); err == nil || atomic.LoadInt32(&calls) != 4 {
+		t.Error("expected get_public_keys to be retried", err, calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, expect := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 9: 300 * time.Millisecond} {
+		if d := p.backoff(attempt); d != expect {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, expect, d)
+		}
+	}
+}
+
+func TestNewKeosClientOpts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["default *"]`))
+	}))
+	defer server.Close()
+
+	home, restore := emptyHome(t)
+	defer restore()
+	defer os.RemoveAll(home)
+	if _, err := NewKeosClientOpts(); err == nil {
+		t.Error("expected an error without a socket or URL when keosd is not running")
+	}
+	if _, err := NewKeosClientOpts(WithSocket("/tmp/keosd.sock"), WithURL(server.URL)); err == nil {
+		t.Error("expected an error with both a socket and URL")
+	}
+
+	logged := &bytes.Buffer{}
+	httpClient := &http.Client{}
+	k, err := NewKeosClientOpts(WithURL(server.URL+"/"), WithHTTPClient(httpClient), WithTimeout(time.Second),
+		WithLogger(log.New(logged, "", 0)))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k.HttpClient.Timeout != time.Second || httpClient.Timeout != 0 {
+		t.Error("timeout should be applied to a copy of the http client")
+	}
+	if _, err = k.ListWallets(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if !strings.HasPrefix(logged.String(), "keosd /v1/wallet/list_wallets returned 200") {
+		t.Error("unexpected log output", logged.String())
+	}
+
+	k, err = NewKeosClientOpts(WithSocket("/tmp/keosd.sock"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k.BaseUrl != "http://unix" || k.Socket != "/tmp/keosd.sock" {
+		t.Error("unexpected socket client", k.BaseUrl, k.Socket)
+	}
+}
+
+func TestParseWalletURL(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for walletUrl, expect := range map[string][2]string{
+		"unix:///var/run/keosd.sock":       {"", "/var/run/keosd.sock"},
+		"unix://~/eosio-wallet/keosd.sock": {"", filepath.Join(home, "eosio-wallet", "keosd.sock")},
+		"http://127.0.0.1:8900/":           {"http://127.0.0.1:8900", ""},
+		"https://wallet.example.com:443":   {"https://wallet.example.com:443", ""},
+	} {
+		keosUrl, socket, err := ParseWalletURL(walletUrl)
+		if err != nil {
+			t.Error(walletUrl, err)
+			continue
+		}
+		if keosUrl != expect[0] || socket != expect[1] {
+			t.Errorf("%s: expected %v, got %s %s", walletUrl, expect, keosUrl, socket)
+		}
+	}
+	for _, bad := range []string{"", "unix://", "http://", "127.0.0.1:8900", "ftp://host"} {
+		if _, _, err = ParseWalletURL(bad); err == nil {
+			t.Error("expected an error for", bad)
+		}
+	}
+	if _, err = NewKeosClientOpts(WithWalletURL("tcp://127.0.0.1:8900")); err == nil {
+		t.Error("expected NewKeosClientOpts to report the invalid wallet url")
+	}
+	k, err := NewKeosClientOpts(WithWalletURL("unix:///tmp/keosd.sock"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k.Socket != "/tmp/keosd.sock" || k.BaseUrl != "http://unix" {
+		t.Error("wallet url was not applied", k.Socket, k.BaseUrl)
+	}
+}
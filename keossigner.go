@@ -0,0 +1,112 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// KeosSigner implements eos.Signer using keys held by keosd, transactions are signed with sign_digest so private
+// keys never leave the wallet. Set it as the signer on a fio.API to sign transactions with wallet keys.
+type KeosSigner struct {
+	client *KeosClient
+	ctx    context.Context
+	wallet string // when set only keys in this wallet are used
+}
+
+// NewKeosSigner creates a signer using an unlocked keosd wallet, ImportPrivateKey adds keys to client.Wallet
+func NewKeosSigner(client *KeosClient) (*KeosSigner, error) {
+	if client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	return &KeosSigner{client: client, ctx: context.Background()}, nil
+}
+
+// WithContext provides a copy of the signer that uses ctx for calls to keosd, since the eos.Signer methods do not
+// accept one
+func (ks KeosSigner) WithContext(ctx context.Context) *KeosSigner {
+	ks.ctx = ctx
+	return &ks
+}
+
+// ForWallet provides a copy of the signer that only signs with keys in one wallet, keosd itself signs with any
+// unlocked wallet. The wallet must have been unlocked by the client.
+func (ks KeosSigner) ForWallet(wallet string) *KeosSigner {
+	ks.wallet = wallet
+	return &ks
+}
+
+// AvailableKeys lists the public keys in the unlocked wallets, or only the signer's wallet if ForWallet was used
+func (ks KeosSigner) AvailableKeys() ([]ecc.PublicKey, error) {
+	keys, err := ks.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ecc.PublicKey, len(keys))
+	for i := range keys {
+		if out[i], err = ecc.NewPublicKey(keys[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Sign adds a signature from keosd for each of the required keys
+func (ks KeosSigner) Sign(tx *eos.SignedTransaction, chainID []byte, requiredKeys ...ecc.PublicKey) (*eos.SignedTransaction, error) {
+	if tx == nil {
+		return nil, errors.New("transaction cannot be nil")
+	}
+	if len(requiredKeys) == 0 {
+		return nil, errors.New("no keys were provided for signing")
+	}
+	packed, cfd, err := tx.PackedTransactionAndCFD()
+	if err != nil {
+		return nil, err
+	}
+	if ks.wallet != "" {
+		available, err := ks.AvailableKeys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range requiredKeys {
+			found := false
+			for _, k := range available {
+				found = found || samePublicKey(k, key)
+			}
+			if !found {
+				return nil, fmt.Errorf("%w: %s is not in wallet %s", ErrWalletMissingKey, key.String(), ks.wallet)
+			}
+		}
+	}
+	digest := TransactionDigest(chainID, packed, cfd)
+	for _, key := range requiredKeys {
+		sig, err := ks.client.SignDigest(ks.ctx, digest, key.String())
+		if err != nil {
+			return nil, err
+		}
+		tx.Signatures = append(tx.Signatures, sig)
+	}
+	return tx, nil
+}
+
+// ImportPrivateKey imports a key into the signer's wallet if ForWallet was used, otherwise client.Wallet. The wallet
+// must be unlocked.
+func (ks KeosSigner) ImportPrivateKey(wifPrivKey string) error {
+	wallet := ks.wallet
+	if wallet == "" {
+		wallet = ks.client.Wallet
+	}
+	if wallet == "" {
+		return errors.New("client does not have a wallet name set")
+	}
+	return ks.client.ImportKey(ks.ctx, wallet, wifPrivKey)
+}
+
+func (ks KeosSigner) publicKeys() ([]string, error) {
+	if ks.wallet != "" {
+		return ks.client.WalletPublicKeys(ks.ctx, ks.wallet)
+	}
+	return ks.client.GetPublicKeys(ks.ctx)
+}
@@ -0,0 +1,83 @@
+package fiox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestKeosSigner(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := hd.keyAt(0)
+	pub := key.PublicKey()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/wallet/get_public_keys":
+			_ = json.NewEncoder(w).Encode([]string{pub.String()})
+		case "/v1/wallet/sign_digest":
+			body, _ := ioutil.ReadAll(r.Body)
+			params := make([]string, 0)
+			_ = json.Unmarshal(body, &params)
+			digest, _ := hex.DecodeString(params[0])
+			sig, _ := key.Sign(digest)
+			_ = json.NewEncoder(w).Encode(sig.String())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	signer, err := NewKeosSigner(NewKeosClient(server.URL, ""))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var _ eos.Signer = signer
+	keys, err := signer.AvailableKeys()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(keys) != 1 || keys[0].String() != pub.String() {
+		t.Error("unexpected keys", keys)
+	}
+
+	tx := eos.NewSignedTransaction(&eos.Transaction{})
+	chainID, _ := hex.DecodeString(FioTestnetChainID)
+	if tx, err = signer.Sign(tx, chainID, pub); err != nil {
+		t.Error(err)
+		return
+	}
+	packed, cfd, _ := tx.PackedTransactionAndCFD()
+	if len(tx.Signatures) != 1 || !tx.Signatures[0].Verify(TransactionDigest(chainID, packed, cfd), pub) {
+		t.Error("transaction was not signed by the wallet key")
+	}
+	if err = signer.ImportPrivateKey(key.String()); err == nil {
+		t.Error("expected an error without a wallet name")
+	}
+}
+
+func TestKeosClientContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewKeosClient(server.URL, "").GetPublicKeys(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected the call to time out, got", err)
+	}
+}
@@ -0,0 +1,211 @@
+package fiox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+	"os"
+)
+
+// keosWalletFile is the JSON structure of a keosd .wallet file, cipher_keys is the hex encoded ciphertext
+type keosWalletFile struct {
+	CipherKeys string `json:"cipher_keys"`
+}
+
+// NewKeosPassword creates a random password in the same format keosd uses, "PW" followed by a WIF private key
+func NewKeosPassword() (string, error) {
+	k, err := ecc.NewRandomPrivateKey()
+	if err != nil {
+		return "", err
+	}
+	return "PW" + k.String(), nil
+}
+
+// EncryptKeosWallet builds the contents of a keosd compatible .wallet file holding keys, encrypted with password.
+// The result can be placed in the keosd wallet directory (for example ~/fio-wallet/default.wallet) and opened with
+// "clio wallet unlock".
+func EncryptKeosWallet(password string, keys []*ecc.PrivateKey) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("password cannot be empty")
+	}
+	checksum := sha512.Sum512([]byte(password))
+
+	// fc::raw::pack of plain_keys{checksum, map<public_key_type, private_key_type>}
+	plain := bytes.NewBuffer(nil)
+	plain.Write(checksum[:])
+	writeVarUint(plain, uint64(len(keys)))
+	for _, k := range keys {
+		if k == nil {
+			return nil, errors.New("keys cannot contain a nil key")
+		}
+		pub := k.PublicKey()
+		if pub.Curve != ecc.CurveK1 {
+			return nil, errors.New("only K1 keys can be written to a keosd wallet")
+		}
+		raw, err := rawPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		plain.WriteByte(0) // static_variant index for K1
+		plain.Write(pub.Content)
+		plain.WriteByte(0)
+		plain.Write(raw)
+		wipe(raw)
+	}
+	defer wipe(plain.Bytes())
+
+	mode, err := keosCipher(checksum, true)
+	if err != nil {
+		return nil, err
+	}
+	padded := pkcs7Pad(plain.Bytes(), aes.BlockSize)
+	defer wipe(padded)
+	ciphertext := make([]byte, len(padded))
+	mode.CryptBlocks(ciphertext, padded)
+	return json.MarshalIndent(keosWalletFile{CipherKeys: hex.EncodeToString(ciphertext)}, "", "  ")
+}
+
+// DecryptKeosWallet opens the contents of a keosd .wallet file, returning the private keys it holds
+func DecryptKeosWallet(data []byte, password string) ([]*ecc.PrivateKey, error) {
+	wf := keosWalletFile{}
+	if err := json.Unmarshal(data, &wf); err != nil {
+		return nil, err
+	}
+	ciphertext, err := hex.DecodeString(wf.CipherKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid wallet ciphertext length")
+	}
+	checksum := sha512.Sum512([]byte(password))
+	mode, err := keosCipher(checksum, false)
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(ciphertext))
+	defer wipe(plain)
+	mode.CryptBlocks(plain, ciphertext)
+	plain, err = pkcs7Unpad(plain, aes.BlockSize)
+	if err != nil || len(plain) < sha512.Size || !bytes.Equal(plain[:sha512.Size], checksum[:]) {
+		return nil, ErrInvalidPassword
+	}
+
+	r := bytes.NewReader(plain[sha512.Size:])
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*ecc.PrivateKey, 0)
+	for i := uint64(0); i < count; i++ {
+		// public key: variant index + 33 bytes, private key: variant index + 32 bytes
+		entry := make([]byte, 1+33+1+32)
+		if _, err = io.ReadFull(r, entry); err != nil {
+			return nil, err
+		}
+		if entry[0] != 0 || entry[34] != 0 {
+			return nil, fmt.Errorf("unsupported key type in wallet entry %d", i)
+		}
+		priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), entry[35:])
+		wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, false)
+		wipe(entry)
+		if err != nil {
+			return nil, err
+		}
+		k, err := ecc.NewPrivateKey(wif.String())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// KeosWallet provides the contents of a keosd .wallet file holding the first count keys of the Hd
+func (hd Hd) KeosWallet(password string, count int) ([]byte, error) {
+	if count < 1 {
+		return nil, errors.New("cannot derive 0 keys")
+	}
+	keys := make([]*ecc.PrivateKey, count)
+	for i := range keys {
+		k, err := hd.keyAt(i)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = k
+	}
+	return EncryptKeosWallet(password, keys)
+}
+
+// WriteKeosWallet writes the first count keys of the Hd to a new keosd .wallet file, it will not overwrite an
+// existing file.
+func (hd Hd) WriteKeosWallet(filename string, password string, count int) error {
+	data, err := hd.KeosWallet(password, count)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// keosCipher uses the sha512 of the password as fc::aes_encrypt does: the first 32 bytes are the key, the next 16
+// the IV.
+func keosCipher(checksum [sha512.Size]byte, encrypt bool) (cipher.BlockMode, error) {
+	block, err := aes.NewCipher(checksum[:32])
+	if err != nil {
+		return nil, err
+	}
+	if encrypt {
+		return cipher.NewCBCEncrypter(block, checksum[32:48]), nil
+	}
+	return cipher.NewCBCDecrypter(block, checksum[32:48]), nil
+}
+
+func writeVarUint(w *bytes.Buffer, v uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	w.Write(buf[:binary.PutUvarint(buf, v)])
+}
+
+func pkcs7Pad(b []byte, size int) []byte {
+	n := size - len(b)%size
+	padded := make([]byte, len(b)+n)
+	copy(padded, b)
+	for i := len(b); i < len(padded); i++ {
+		padded[i] = byte(n)
+	}
+	return padded
+}
+
+func pkcs7Unpad(b []byte, size int) ([]byte, error) {
+	if len(b) == 0 || len(b)%size != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	n := int(b[len(b)-1])
+	if n == 0 || n > size {
+		return nil, errors.New("invalid padding")
+	}
+	for _, p := range b[len(b)-n:] {
+		if int(p) != n {
+			return nil, errors.New("invalid padding")
+		}
+	}
+	return b[:len(b)-n], nil
+}
@@ -0,0 +1,45 @@
+package fiox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHd_KeosWallet(t *testing.T) {
+	hd, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	password, err := NewKeosPassword()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.HasPrefix(password, "PW5") {
+		t.Error("password is not in the keosd format", password)
+	}
+	data, err := hd.KeosWallet(password, 3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	keys, err := DecryptKeosWallet(data, password)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(keys) != 3 {
+		t.Error("expected 3 keys, got", len(keys))
+		return
+	}
+	for i := range keys {
+		k, _ := hd.keyAt(i)
+		if keys[i].String() != k.String() {
+			t.Error("key did not round trip at index", i)
+		}
+	}
+	if _, err = DecryptKeosWallet(data, "PW"+password[2:len(password)-1]); err == nil {
+		t.Error("opened wallet with the wrong password")
+	}
+}
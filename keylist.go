@@ -0,0 +1,133 @@
+package fiox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KeyFormat is an output format for ExportKeys
+type KeyFormat string
+
+const (
+	KeyFormatTable KeyFormat = "table"
+	KeyFormatJSON  KeyFormat = "json"
+	KeyFormatCSV   KeyFormat = "csv"
+)
+
+// KeyListOptions controls WriteKeys
+type KeyListOptions struct {
+	Format KeyFormat // defaults to KeyFormatTable
+
+	// RevealPrivate includes the private keys loaded by GetKeys, they are left out by default
+	RevealPrivate bool
+}
+
+// PrintKeys provides a human readable list of keys in a wallet
+func (k *KeosClient) PrintKeys() string {
+	out, _ := k.ExportKeys(KeyFormatTable)
+	return string(out)
+}
+
+// ExportKeys provides the loaded keys sorted by actor as a table, JSON, or CSV. Private keys are never included, use
+// WriteKeys with RevealPrivate if they really are needed.
+func (k *KeosClient) ExportKeys(format KeyFormat) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := k.WriteKeys(buf, KeyListOptions{Format: format}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteKeys writes the loaded keys sorted by actor to w one row at a time, rather than building the whole listing
+// in memory as ExportKeys does
+func (k *KeosClient) WriteKeys(w io.Writer, opts KeyListOptions) error {
+	if opts.Format == "" {
+		opts.Format = KeyFormatTable
+	}
+	var write func(KeosKeys) error
+	finish := func() error { return nil }
+	bw := bufio.NewWriter(w)
+	switch opts.Format {
+	case KeyFormatTable:
+		bw.WriteString(keyTableHeader(opts.RevealPrivate, k.accountDetails))
+		write = func(v KeosKeys) error {
+			_, err := bw.WriteString(keyTableRow(v, opts.RevealPrivate))
+			return err
+		}
+	case KeyFormatJSON:
+		bw.WriteString("[")
+		sep := "\n  "
+		write = func(v KeosKeys) error {
+			j, err := json.MarshalIndent(v, "  ", "  ")
+			if err != nil {
+				return err
+			}
+			bw.WriteString(sep)
+			sep = ",\n  "
+			_, err = bw.Write(j)
+			return err
+		}
+		finish = func() error {
+			_, err := bw.WriteString("\n]\n")
+			return err
+		}
+	case KeyFormatCSV:
+		cw := csv.NewWriter(bw)
+		_ = cw.Write([]string{"actor", "public_key", "private_key", "fio_address", "permissions"})
+		write = func(v KeosKeys) error {
+			return cw.Write([]string{v.Actor, v.PublicKey, v.PrivateKey, v.FioAddress, strings.Join(v.Permissions, " ")})
+		}
+		finish = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return fmt.Errorf("unknown key format %q", opts.Format)
+	}
+	for _, v := range k.keys.list() {
+		if !opts.RevealPrivate {
+			v.PrivateKey = ""
+		}
+		if err := write(v); err != nil {
+			return err
+		}
+	}
+	if err := finish(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// keyTableHeader matches keyTableRow, details adds the permissions column shown when account details are loaded
+func keyTableHeader(private bool, details bool) string {
+	names := fmt.Sprintf("%-12s  %-53s  ", "Account", "Public Key")
+	lines := fmt.Sprintf("%-12s  %-53s  ", "⎺⎺⎺⎺⎺⎺⎺", "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺")
+	if private {
+		names += fmt.Sprintf("%-51s  ", "Private Key")
+		lines += fmt.Sprintf("%-51s  ", "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺")
+	}
+	if details {
+		names += fmt.Sprintf("%-24s  %s", "FIO Address", "Permissions")
+		lines += fmt.Sprintf("%-24s  %s", "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺", "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺")
+	} else {
+		names += "FIO Address"
+		lines += "⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺⎺"
+	}
+	return "\n" + names + "\n" + lines + "\n"
+}
+
+func keyTableRow(v KeosKeys, private bool) string {
+	row := fmt.Sprintf("%12s  %53s  ", v.Actor, v.PublicKey)
+	if private {
+		row += fmt.Sprintf("%-51s  ", v.PrivateKey)
+	}
+	if v.Account != nil {
+		return row + fmt.Sprintf("%-24s  %s\n", v.FioAddress, strings.Join(v.Permissions, ","))
+	}
+	return row + v.FioAddress + "\n"
+}
@@ -0,0 +1,119 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExportKeys(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k := NewKeosClient("http://127.0.0.1:8900", "")
+	for i := 0; i < 5; i++ {
+		key, _ := hd.keyAt(i)
+		actor, _ := hd.ActorAt(i)
+		k.keys.set(KeosKeys{Actor: string(actor), PublicKey: key.PublicKey().String(), PrivateKey: key.String()})
+	}
+	first, _ := hd.ActorAt(0)
+	secret, _ := k.RevealKey(string(first))
+	if secret == "" {
+		t.Error("private key was not revealed")
+		return
+	}
+
+	table, err := k.ExportKeys(KeyFormatTable)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(table) != k.PrintKeys() || strings.Contains(string(table), secret) {
+		t.Error("unexpected table", string(table))
+	}
+	again := &bytes.Buffer{}
+	_ = k.WriteKeys(again, KeyListOptions{Format: KeyFormatTable, RevealPrivate: true})
+	if !strings.Contains(again.String(), secret) {
+		t.Error("private keys should be included with RevealPrivate")
+	}
+	for _, key := range k.KeyList() {
+		if key.PrivateKey != "" {
+			t.Error("KeyList should not include private keys")
+		}
+	}
+
+	j, err := k.ExportKeys(KeyFormatJSON)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	decoded := make([]KeosKeys, 0)
+	if err = json.Unmarshal(j, &decoded); err != nil || len(decoded) != 5 || decoded[0].PrivateKey != "" {
+		t.Error("unexpected json", string(j), err)
+	}
+
+	c := &bytes.Buffer{}
+	if err = k.WriteKeys(c, KeyListOptions{Format: KeyFormatCSV, RevealPrivate: true}); err != nil {
+		t.Error(err)
+		return
+	}
+	rows, err := csv.NewReader(c).ReadAll()
+	if err != nil || len(rows) != 6 || !containsRow(rows, secret) {
+		t.Error("unexpected csv", rows, err)
+	}
+	for i := 2; i < len(rows); i++ {
+		if rows[i-1][0] >= rows[i][0] {
+			t.Error("rows are not sorted by actor")
+		}
+	}
+
+	if _, err = k.ExportKeys("yaml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteKeys(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k := NewKeosClient("http://127.0.0.1:8900", "")
+	pubs, _ := hd.PubKeys(500)
+	for i, pub := range pubs {
+		actor, _ := hd.ActorAt(i)
+		k.keys.set(KeosKeys{Actor: string(actor), PublicKey: pub.String()})
+	}
+	buf := &bytes.Buffer{}
+	if err = k.WriteKeys(buf, KeyListOptions{Format: KeyFormatJSON}); err != nil {
+		t.Error(err)
+		return
+	}
+	decoded := make([]KeosKeys, 0)
+	if err = json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 500 {
+		t.Error("unexpected json", err, len(decoded))
+	}
+	if err = k.WriteKeys(failingWriter{}, KeyListOptions{Format: KeyFormatCSV}); err == nil {
+		t.Error("expected the write error to be returned")
+	}
+}
+
+func containsRow(rows [][]string, privateKey string) bool {
+	for _, row := range rows {
+		if row[2] == privateKey {
+			return true
+		}
+	}
+	return false
+}
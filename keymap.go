@@ -0,0 +1,133 @@
+package fiox
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// keyMap holds the keys loaded by GetKeys indexed by actor, it is safe for concurrent use
+type keyMap struct {
+	sync.RWMutex
+	byActor map[string]KeosKeys
+}
+
+func newKeyMap() *keyMap {
+	return &keyMap{byActor: make(map[string]KeosKeys)}
+}
+
+func (m *keyMap) get(actor string) (KeosKeys, bool) {
+	if m == nil {
+		return KeosKeys{}, false
+	}
+	m.RLock()
+	defer m.RUnlock()
+	key, ok := m.byActor[actor]
+	return key, ok
+}
+
+func (m *keyMap) set(key KeosKeys) {
+	m.Lock()
+	m.byActor[key.Actor] = key
+	m.Unlock()
+}
+
+// find returns the first key matching f, in actor order
+func (m *keyMap) find(f func(KeosKeys) bool) (KeosKeys, bool) {
+	for _, key := range m.list() {
+		if f(key) {
+			return key, true
+		}
+	}
+	return KeosKeys{}, false
+}
+
+func (m *keyMap) list() []KeosKeys {
+	if m == nil {
+		return nil
+	}
+	m.RLock()
+	keys := make([]KeosKeys, 0, len(m.byActor))
+	for _, key := range m.byActor {
+		keys = append(keys, key)
+	}
+	m.RUnlock()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Actor < keys[j].Actor
+	})
+	return keys
+}
+
+// KeyByActor finds a loaded key by its actor (account name). Like the other accessors the private key is not
+// included, see RevealKey.
+func (k *KeosClient) KeyByActor(actor string) (KeosKeys, bool) {
+	key, ok := k.keys.get(actor)
+	key.PrivateKey = ""
+	return key, ok
+}
+
+// RevealKey provides the private key for an actor, if GetKeys loaded it. Avoid this unless the key is really needed
+// in the process, KeosSigner can sign without it.
+func (k *KeosClient) RevealKey(actor string) (string, error) {
+	key, ok := k.keys.get(actor)
+	if !ok {
+		return "", fmt.Errorf("no key loaded for %s", actor)
+	}
+	if key.PrivateKey == "" {
+		return "", fmt.Errorf("the private key for %s was not loaded, use GetKeys rather than GetPublicKeysOnly", actor)
+	}
+	return key.PrivateKey, nil
+}
+
+// KeyByPub finds a loaded key by its public key, either the FIO or PUB_K1_ format is accepted
+func (k *KeosClient) KeyByPub(pub string) (KeosKeys, bool) {
+	target, err := publicKeyContent(pub)
+	if err != nil {
+		return KeosKeys{}, false
+	}
+	key, ok := k.keys.find(func(key KeosKeys) bool {
+		loaded, err := publicKeyContent(key.PublicKey)
+		return err == nil && bytes.Equal(loaded, target)
+	})
+	key.PrivateKey = ""
+	return key, ok
+}
+
+// KeyByAddress finds a loaded key by its FIO address, only the first address for each key is known
+func (k *KeosClient) KeyByAddress(fioAddress string) (KeosKeys, bool) {
+	key, ok := k.keys.find(func(key KeosKeys) bool {
+		return key.FioAddress != "" && strings.EqualFold(key.FioAddress, fioAddress)
+	})
+	key.PrivateKey = ""
+	return key, ok
+}
+
+// KeyList provides the loaded keys sorted by actor, without the private keys
+func (k *KeosClient) KeyList() []KeosKeys {
+	keys := k.keys.list()
+	for i := range keys {
+		keys[i].PrivateKey = ""
+	}
+	return keys
+}
+
+// RangeKeys calls f for each loaded key in actor order until it returns false, the private keys are not included
+func (k *KeosClient) RangeKeys(f func(key KeosKeys) bool) {
+	for _, key := range k.KeyList() {
+		if !f(key) {
+			return
+		}
+	}
+}
+
+// KeyCount is the number of loaded keys
+func (k *KeosClient) KeyCount() int {
+	if k.keys == nil {
+		return 0
+	}
+	k.keys.RLock()
+	defer k.keys.RUnlock()
+	return len(k.keys.byActor)
+}
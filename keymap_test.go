@@ -0,0 +1,52 @@
+package fiox
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestKeyMap(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	k := NewKeosClient("http://127.0.0.1:8900", "")
+	pubs, _ := hd.PubKeys(20)
+	wg := sync.WaitGroup{}
+	for i, pub := range pubs {
+		wg.Add(2)
+		go func(i int, pub string) {
+			defer wg.Done()
+			actor, _ := hd.ActorAt(i)
+			k.keys.set(KeosKeys{Actor: string(actor), PublicKey: pub, FioAddress: "key" + strconv.Itoa(i) + "@fiotestnet"})
+		}(i, pub.String())
+		go func() {
+			defer wg.Done()
+			_ = k.KeyList()
+		}()
+	}
+	wg.Wait()
+
+	if k.KeyCount() != 20 {
+		t.Error("expected 20 keys, got", k.KeyCount())
+	}
+	actor, _ := hd.ActorAt(3)
+	if key, ok := k.KeyByActor(string(actor)); !ok || key.PublicKey != pubs[3].String() {
+		t.Error("key not found by actor")
+	}
+	k1, _ := PublicKeyK1(pubs[5])
+	if key, ok := k.KeyByPub(k1); !ok || key.FioAddress != "key5@fiotestnet" {
+		t.Error("key not found by PUB_K1_ key")
+	}
+	if key, ok := k.KeyByAddress("KEY7@fiotestnet"); !ok || key.PublicKey != pubs[7].String() {
+		t.Error("key not found by address")
+	}
+	list := k.KeyList()
+	for i := 1; i < len(list); i++ {
+		if list[i-1].Actor >= list[i].Actor {
+			t.Error("keys are not sorted by actor")
+		}
+	}
+}
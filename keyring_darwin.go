@@ -0,0 +1,57 @@
+//go:build darwin
+// +build darwin
+
+package fiox
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// the security tool exits with errSecItemNotFound when there is no matching item
+const securityNotFound = 44
+
+// Password reads a wallet password from the login keychain
+func (kr *Keyring) Password(wallet string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", kr.service(), "-a", wallet, "-w").Output()
+	if err != nil {
+		return "", keychainError(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// SetPassword saves a wallet password in the login keychain, replacing any existing one. The password is passed
+// on stdin so it does not show up in the process list.
+func (kr *Keyring) SetPassword(wallet string, password string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		quoteSecurity(kr.service()), quoteSecurity(wallet), quoteSecurity(password)))
+	if out, err := cmd.CombinedOutput(); err != nil || len(out) > 0 {
+		// security -i reports errors on stdout and still exits 0
+		return fmt.Errorf("could not save the password in the keychain: %v %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DeletePassword removes a wallet password from the login keychain
+func (kr *Keyring) DeletePassword(wallet string) error {
+	if err := exec.Command("security", "delete-generic-password", "-s", kr.service(), "-a", wallet).Run(); err != nil {
+		return keychainError(err)
+	}
+	return nil
+}
+
+func keychainError(err error) error {
+	exitErr := &exec.ExitError{}
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound {
+		return ErrCredentialNotFound
+	}
+	return err
+}
+
+// quoteSecurity quotes an argument for security -i, which splits its input like a shell
+func quoteSecurity(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,41 @@
+//go:build !darwin && !windows
+// +build !darwin,!windows
+
+package fiox
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Password reads a wallet password from the Secret Service using secret-tool
+func (kr *Keyring) Password(wallet string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", kr.service(), "account", wallet).Output()
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(exitErr.Stderr) == 0 {
+			// secret-tool exits 1 without a message when nothing matches
+			return "", ErrCredentialNotFound
+		}
+		return "", err
+	}
+	if len(out) == 0 {
+		return "", ErrCredentialNotFound
+	}
+	return string(out), nil
+}
+
+// SetPassword saves a wallet password in the Secret Service, replacing any existing one. The password is passed
+// on stdin so it does not show up in the process list.
+func (kr *Keyring) SetPassword(wallet string, password string) error {
+	cmd := exec.Command("secret-tool", "store", "--label="+kr.service()+" "+wallet,
+		"service", kr.service(), "account", wallet)
+	cmd.Stdin = strings.NewReader(password)
+	return cmd.Run()
+}
+
+// DeletePassword removes a wallet password from the Secret Service
+func (kr *Keyring) DeletePassword(wallet string) error {
+	return exec.Command("secret-tool", "clear", "service", kr.service(), "account", wallet).Run()
+}
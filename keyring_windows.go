@@ -0,0 +1,107 @@
+//go:build windows
+// +build windows
+
+package fiox
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential is the CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// Password reads a wallet password from the Windows Credential Manager
+func (kr *Keyring) Password(wallet string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(kr.target(wallet))
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", credError(err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	blob := make([]byte, cred.CredentialBlobSize)
+	copy(blob, (*[1 << 20]byte)(unsafe.Pointer(cred.CredentialBlob))[:cred.CredentialBlobSize:cred.CredentialBlobSize])
+	return string(blob), nil
+}
+
+// SetPassword saves a wallet password in the Windows Credential Manager, replacing any existing one
+func (kr *Keyring) SetPassword(wallet string, password string) error {
+	target, err := syscall.UTF16PtrFromString(kr.target(wallet))
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(wallet)
+	if err != nil {
+		return err
+	}
+	blob := []byte(password)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// DeletePassword removes a wallet password from the Windows Credential Manager
+func (kr *Keyring) DeletePassword(wallet string) error {
+	target, err := syscall.UTF16PtrFromString(kr.target(wallet))
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		return credError(err)
+	}
+	return nil
+}
+
+// target is the credential name, Windows has no separate account attribute to look up by
+func (kr *Keyring) target(wallet string) string {
+	return kr.service() + ":" + wallet
+}
+
+func credError(err error) error {
+	if err == errorNotFound {
+		return ErrCredentialNotFound
+	}
+	return err
+}
@@ -0,0 +1,249 @@
+package fiox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// KeystoreStandardScryptN is the scrypt cost used by geth for keystore files, it uses 256MB of memory
+	KeystoreStandardScryptN = 1 << 18
+	// KeystoreLightScryptN is a cheaper cost suitable for mobile devices, or tests
+	KeystoreLightScryptN = 1 << 12
+)
+
+// ErrKeystoreMac is returned when a keystore can't be decrypted because the password is wrong
+var ErrKeystoreMac = errors.New("could not decrypt keystore, invalid password")
+
+type keystoreJSON struct {
+	Address string         `json:"address"`
+	Crypto  keystoreCrypto `json:"crypto"`
+	ID      string         `json:"id"`
+	Version int            `json:"version"`
+}
+
+type keystoreCrypto struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams keystoreCipherParams   `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type keystoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+// EncryptKeystore exports a private key as a version 3 (Ethereum style) keystore JSON, encrypted with AES-128-CTR
+// and a scrypt derived key. scryptN is normally KeystoreStandardScryptN.
+func EncryptKeystore(key *ecc.PrivateKey, password string, scryptN int) ([]byte, error) {
+	raw, err := rawPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(raw)
+	random := make([]byte, 32+aes.BlockSize+16)
+	if _, err = rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, iv, id := random[:32], random[32:48], random[48:]
+
+	derived, err := scrypt.Key([]byte(password), salt, scryptN, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(derived)
+	ciphertext, err := aesCTR(derived[:16], iv, raw)
+	if err != nil {
+		return nil, err
+	}
+	address, err := ethAddress(key.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	// uuid version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return json.Marshal(keystoreJSON{
+		Address: address,
+		Crypto: keystoreCrypto{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(ciphertext),
+			CipherParams: keystoreCipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: map[string]interface{}{
+				"dklen": 32,
+				"n":     scryptN,
+				"p":     1,
+				"r":     8,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(keccak256(derived[16:32], ciphertext)),
+		},
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: 3,
+	})
+}
+
+// DecryptKeystore imports a private key from a version 3 keystore JSON using either the scrypt or pbkdf2 KDF
+func DecryptKeystore(data []byte, password string) (*ecc.PrivateKey, error) {
+	ks := keystoreJSON{}
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, err
+	}
+	if ks.Version != 3 {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+	if ks.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported keystore cipher %q", ks.Crypto.Cipher)
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := keystoreKDF(ks.Crypto.KDF, ks.Crypto.KDFParams, password)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(derived)
+	if !hmac.Equal(keccak256(derived[16:32], ciphertext), mac) {
+		return nil, ErrKeystoreMac
+	}
+	raw, err := aesCTR(derived[:16], iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(raw)
+	if len(raw) != 32 {
+		return nil, errors.New("keystore does not hold a 32 byte private key")
+	}
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), raw)
+	wif, err := btcutil.NewWIF(priv, &chaincfg.MainNetParams, false)
+	if err != nil {
+		return nil, err
+	}
+	return ecc.NewPrivateKey(wif.String())
+}
+
+// KeystoreAt exports the key at index as a version 3 keystore JSON, using KeystoreStandardScryptN
+func (hd Hd) KeystoreAt(index int, password string) ([]byte, error) {
+	key, err := hd.keyAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptKeystore(key, password, KeystoreStandardScryptN)
+}
+
+// limits on the kdf parameters of a keystore file, which is untrusted, so one can't be crafted to use up all memory
+// or CPU. They are well above what geth and other wallets write.
+const (
+	keystoreMaxScryptN = 1 << 20
+	keystoreMaxScryptR = 8
+	keystoreMaxScryptP = 16
+	keystoreMaxPbkdf2C = 10_000_000
+)
+
+func keystoreKDF(kdf string, params map[string]interface{}, password string) ([]byte, error) {
+	getInt := func(name string, max int) (int, error) {
+		v, ok := params[name].(float64)
+		if !ok {
+			return 0, fmt.Errorf("keystore kdfparams is missing %q", name)
+		}
+		if v < 1 || v > float64(max) {
+			return 0, fmt.Errorf("keystore kdfparams %q must be between 1 and %d", name, max)
+		}
+		return int(v), nil
+	}
+	saltHex, _ := params["salt"].(string)
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, err
+	}
+	dklen, err := getInt("dklen", 32)
+	if err != nil {
+		return nil, err
+	}
+	if dklen != 32 {
+		return nil, errors.New("keystore dklen must be 32")
+	}
+	switch kdf {
+	case "scrypt":
+		n, err := getInt("n", keystoreMaxScryptN)
+		if err != nil {
+			return nil, err
+		}
+		r, err := getInt("r", keystoreMaxScryptR)
+		if err != nil {
+			return nil, err
+		}
+		p, err := getInt("p", keystoreMaxScryptP)
+		if err != nil {
+			return nil, err
+		}
+		return scrypt.Key([]byte(password), salt, n, r, p, dklen)
+	case "pbkdf2":
+		if prf, _ := params["prf"].(string); prf != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf %q", prf)
+		}
+		c, err := getInt("c", keystoreMaxPbkdf2C)
+		if err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key([]byte(password), salt, c, dklen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("unsupported keystore kdf %q", kdf)
+}
+
+// ethAddress is the lowercase hex Ethereum address for a public key, without the 0x prefix
+func ethAddress(pub ecc.PublicKey) (string, error) {
+	point, err := btcec.ParsePubKey(pub.Content, btcec.S256())
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(keccak256(point.SerializeUncompressed()[1:])[12:]), nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func aesCTR(key []byte, iv []byte, in []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid iv length")
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package fiox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecryptKeystore(t *testing.T) {
+	// test vector from the Web3 Secret Storage Definition
+	vector := `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	key, err := DecryptKeystore([]byte(vector), "testpassword")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	h, _ := PrivateKeyHex(key)
+	if h != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Error("decrypted key mismatch", h)
+	}
+	if _, err = DecryptKeystore([]byte(vector), "wrong"); !errors.Is(err, ErrKeystoreMac) {
+		t.Error("expected ErrKeystoreMac, got", err)
+	}
+
+	// kdf parameters that would take too much memory or time are refused before deriving anything
+	for _, oversized := range []string{
+		strings.Replace(vector, `"c":262144`, `"c":1000000000`, 1),
+		strings.Replace(vector, `"dklen":32`, `"dklen":4096`, 1),
+		strings.Replace(vector, `"kdf":"pbkdf2","kdfparams":{"c":262144,`, `"kdf":"scrypt","kdfparams":{"n":1073741824,"r":8,"p":1,`, 1),
+		strings.Replace(vector, `"kdf":"pbkdf2","kdfparams":{"c":262144,`, `"kdf":"scrypt","kdfparams":{"n":1024,"r":1024,"p":1,`, 1),
+	} {
+		start := time.Now()
+		if _, err = DecryptKeystore([]byte(oversized), "testpassword"); err == nil || time.Since(start) > time.Second {
+			t.Error("expected an oversized keystore to be refused quickly", err)
+		}
+	}
+}
+
+func TestEncryptKeystore(t *testing.T) {
+	hd, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := hd.keyAt(2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	data, err := EncryptKeystore(key, "secret", KeystoreLightScryptN)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restored, err := DecryptKeystore(data, "secret")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if restored.String() != key.String() {
+		t.Error("key did not round trip")
+	}
+}
@@ -0,0 +1,232 @@
+package fiox
+
+import (
+	"context"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"math/big"
+)
+
+// KMS is the part of a cloud key management service needed to sign with a secp256k1 key that never leaves it. AWS
+// KMS (ECC_SECG_P256K1) and GCP KMS (EC_SIGN_SECP256K1_SHA256) both fit with a few lines around their SDKs, which
+// keeps those SDKs out of this package.
+type KMS interface {
+	// PublicKey provides the DER (or PEM) encoded SubjectPublicKeyInfo of a key
+	PublicKey(ctx context.Context, keyID string) ([]byte, error)
+	// Sign signs a 32 byte digest as-is, without hashing it again, and provides a DER encoded ECDSA signature
+	Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+}
+
+// KMSDecrypter decrypts data that was encrypted by a KMS key, see NewKMSWrappedSigner
+type KMSDecrypter interface {
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KMSSignAttempts is how many signatures are requested from the KMS before giving up on a canonical one. About a
+// quarter of ECDSA signatures are canonical by the EOS rules and a KMS cannot be asked for the next nonce the way
+// a local key can, so each attempt is a fresh signing request.
+var KMSSignAttempts = 25
+
+// KMSSigner is a Signer for keys held in a cloud KMS
+type KMSSigner struct {
+	kms  KMS
+	keys remoteKeys
+}
+
+// remoteKey is a key held outside this process, by a KMS or a hardware token
+type remoteKey struct {
+	id  string
+	pub ecc.PublicKey
+}
+
+// remoteKeys are the keys of a KMSSigner or TokenSigner
+type remoteKeys []remoteKey
+
+func (keys remoteKeys) publicKeys() []string {
+	pubs := make([]string, len(keys))
+	for i, k := range keys {
+		pubs[i] = k.pub.String()
+	}
+	return pubs
+}
+
+func (keys remoteKeys) key(pub string) (remoteKey, error) {
+	target, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return remoteKey{}, err
+	}
+	for _, k := range keys {
+		if samePublicKey(k.pub, target) {
+			return k, nil
+		}
+	}
+	return remoteKey{}, ErrWalletMissingKey
+}
+
+// NewKMSSigner creates a Signer for the KMS keys, fetching each public key up front. Keys that are not secp256k1
+// are rejected since FIO cannot use them.
+func NewKMSSigner(ctx context.Context, kms KMS, keyIDs ...string) (*KMSSigner, error) {
+	if kms == nil {
+		return nil, errors.New("kms cannot be nil")
+	}
+	if len(keyIDs) == 0 {
+		return nil, errors.New("at least one key id is required")
+	}
+	s := &KMSSigner{kms: kms}
+	for _, id := range keyIDs {
+		der, err := kms.PublicKey(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the public key for %s: %w", id, err)
+		}
+		pub, err := parseKMSPublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		s.keys = append(s.keys, remoteKey{id: id, pub: pub})
+	}
+	return s, nil
+}
+
+// PublicKeys lists the FIO public keys of the KMS keys
+func (s *KMSSigner) PublicKeys(ctx context.Context) ([]string, error) {
+	return s.keys.publicKeys(), nil
+}
+
+// KeyID provides the KMS key id holding pub
+func (s *KMSSigner) KeyID(pub string) (string, error) {
+	k, err := s.keys.key(pub)
+	if err != nil {
+		return "", err
+	}
+	return k.id, nil
+}
+
+// SignDigest has the KMS sign a 32 byte digest with the key for pub, converting the result to a canonical EOS
+// signature
+func (s *KMSSigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	if len(digest) != 32 {
+		return ecc.Signature{}, errors.New("digest must be 32 bytes")
+	}
+	k, err := s.keys.key(pub)
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	return signCanonical(digest, k.pub, KMSSignAttempts, func() (*big.Int, *big.Int, error) {
+		der, err := s.kms.Sign(ctx, k.id, digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		sig, err := btcec.ParseDERSignature(der, btcec.S256())
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid signature from kms: %w", err)
+		}
+		return sig.R, sig.S, nil
+	})
+}
+
+// SignTransaction signs a transaction with each of pubkeys, accepting the same transactions as
+// KeosClient.SignTransaction
+func (s *KMSSigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	return signTransaction(ctx, s, tx, pubkeys, chainID)
+}
+
+// NewKMSWrappedSigner is for keys stored encrypted by a KMS key rather than held in it: each ciphertext is decrypted
+// to a WIF private key and kept only in memory. The decrypted copies are cleared once the keys are loaded.
+func NewKMSWrappedSigner(ctx context.Context, kms KMSDecrypter, keyID string, ciphertexts ...[]byte) (*KeyBagSigner, error) {
+	if kms == nil {
+		return nil, errors.New("kms cannot be nil")
+	}
+	bag := eos.NewKeyBag()
+	for i, ciphertext := range ciphertexts {
+		plain, err := kms.Decrypt(ctx, keyID, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("could not decrypt key %d: %w", i, err)
+		}
+		err = bag.Add(string(plain))
+		for j := range plain {
+			plain[j] = 0
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %d is not a valid private key: %w", i, err)
+		}
+	}
+	return NewKeyBagSigner(bag)
+}
+
+// parseKMSPublicKey decodes a secp256k1 SubjectPublicKeyInfo as returned by a KMS
+func parseKMSPublicKey(b []byte) (ecc.PublicKey, error) {
+	if block, _ := pem.Decode(b); block != nil {
+		b = block.Bytes
+	}
+	spki := pkixPublicKey{}
+	if _, err := asn1.Unmarshal(b, &spki); err != nil {
+		return ecc.PublicKey{}, fmt.Errorf("invalid public key: %w", err)
+	}
+	if !spki.Algorithm.Parameters.Equal(oidSecp256k1) {
+		return ecc.PublicKey{}, errors.New("public key is not secp256k1")
+	}
+	point, err := btcec.ParsePubKey(spki.PublicKey.Bytes, btcec.S256())
+	if err != nil {
+		return ecc.PublicKey{}, err
+	}
+	pub, err := fioPubKey(point)
+	if err != nil {
+		return ecc.PublicKey{}, err
+	}
+	return *pub, nil
+}
+
+// signCanonical asks sign for signatures until one is canonical by the EOS rules, converting it to the compact form
+// with the recovery id that leads back to pub
+func signCanonical(digest []byte, pub ecc.PublicKey, attempts int, sign func() (r *big.Int, s *big.Int, err error)) (ecc.Signature, error) {
+	for i := 0; i < attempts; i++ {
+		r, s, err := sign()
+		if err != nil {
+			return ecc.Signature{}, err
+		}
+		compact, err := compactSignature(r, s, digest, pub)
+		if err != nil {
+			return ecc.Signature{}, err
+		}
+		if isCanonical(compact) {
+			return ecc.NewSignatureFromData(append([]byte{byte(ecc.CurveK1)}, compact...))
+		}
+	}
+	return ecc.Signature{}, fmt.Errorf("no canonical signature after %d attempts", attempts)
+}
+
+// compactSignature converts an ECDSA signature to the 65 byte compact form EOS uses, with the low S value and the
+// recovery id that leads back to pub
+func compactSignature(r *big.Int, s *big.Int, digest []byte, pub ecc.PublicKey) ([]byte, error) {
+	n := btcec.S256().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return nil, errors.New("signature is out of range")
+	}
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s = new(big.Int).Sub(n, s)
+	}
+	compact := make([]byte, 65)
+	rb, sb := r.Bytes(), s.Bytes()
+	copy(compact[33-len(rb):33], rb)
+	copy(compact[65-len(sb):], sb)
+	for recovery := byte(0); recovery < 4; recovery++ {
+		// 27 + 4 marks a compressed key, as in btcec.SignCompact
+		compact[0] = 27 + 4 + recovery
+		key, _, err := btcec.RecoverCompact(btcec.S256(), compact, digest)
+		if err == nil && samePublicKey(ecc.PublicKey{Curve: ecc.CurveK1, Content: key.SerializeCompressed()}, pub) {
+			return compact, nil
+		}
+	}
+	return nil, errors.New("signature does not match the public key")
+}
+
+// isCanonical applies the EOS rule that neither R nor S may need a leading zero or have the high bit set
+func isCanonical(compact []byte) bool {
+	return compact[1]&0x80 == 0 && !(compact[1] == 0 && compact[2]&0x80 == 0) &&
+		compact[33]&0x80 == 0 && !(compact[33] == 0 && compact[34]&0x80 == 0)
+}
@@ -0,0 +1,110 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+// fakeKMS signs with local keys and random nonces, returning DER like a cloud KMS does
+type fakeKMS struct {
+	keys map[string]*btcec.PrivateKey
+	pem  bool
+}
+
+func (f *fakeKMS) PublicKey(ctx context.Context, keyID string) ([]byte, error) {
+	key, ok := f.keys[keyID]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	pub, err := fioPubKey(key.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	if f.pem {
+		return PublicKeyPEM(pub)
+	}
+	return PublicKeyDER(pub)
+}
+
+func (f *fakeKMS) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, f.keys[keyID].ToECDSA(), digest)
+	if err != nil {
+		return nil, err
+	}
+	return (&btcec.Signature{R: r, S: s}).Serialize(), nil
+}
+
+func (f *fakeKMS) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	plain := make([]byte, len(ciphertext))
+	for i := range ciphertext {
+		plain[i] = ciphertext[i] ^ 0x5a
+	}
+	return plain, nil
+}
+
+func TestKMSSigner(t *testing.T) {
+	priv, err := ecc.NewPrivateKey("5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	raw, err := rawPrivateKey(priv)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), raw)
+	kms := &fakeKMS{keys: map[string]*btcec.PrivateKey{"alias/fio": key}, pem: true}
+
+	ctx := context.Background()
+	if _, err = NewKMSSigner(ctx, kms, "alias/missing"); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+	s, err := NewKMSSigner(ctx, kms, "alias/fio")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := s.PublicKeys(ctx)
+	if len(pubs) != 1 || pubs[0] != priv.PublicKey().String() {
+		t.Error("unexpected public keys", pubs, priv.PublicKey().String())
+		return
+	}
+	if id, _ := s.KeyID(pubs[0]); id != "alias/fio" {
+		t.Error("unexpected key id", id)
+	}
+
+	digest := bytes.Repeat([]byte{7}, 32)
+	for i := 0; i < 10; i++ {
+		sig, err := s.SignDigest(ctx, digest, pubs[0])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !sig.Verify(digest, priv.PublicKey()) || !isCanonical(sig.Content) {
+			t.Error("signature did not verify or is not canonical", sig.String())
+		}
+	}
+	if _, err = s.SignDigest(ctx, digest, "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"); !errors.Is(err, ErrWalletMissingKey) {
+		t.Error("expected ErrWalletMissingKey", err)
+	}
+
+	ciphertext := []byte(priv.String())
+	for i := range ciphertext {
+		ciphertext[i] ^= 0x5a
+	}
+	wrapped, err := NewKMSWrappedSigner(ctx, kms, "alias/wrap", ciphertext)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if wrappedPubs, _ := wrapped.PublicKeys(ctx); len(wrappedPubs) != 1 || wrappedPubs[0] != pubs[0] {
+		t.Error("unexpected wrapped public keys", wrappedPubs)
+	}
+}
@@ -0,0 +1,371 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	hdwallet "github.com/blockpane/fio-extras/internal/go-ethereum-hdwallet"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+)
+
+// LedgerTransport exchanges an APDU with a Ledger device, returning the response including the two byte status
+// word. NewLedgerHID provides one over an opened HID device, or use any library that speaks to the device directly.
+type LedgerTransport interface {
+	Exchange(apdu []byte) ([]byte, error)
+}
+
+// LedgerEOSPath is the first key of the EOS Ledger app, the app only derives keys below m/44'/194'. The keys work on
+// FIO like any other K1 key, the FIO Ledger app uses a different protocol that is not supported here.
+const LedgerEOSPath = "m/44'/194'/0'/0/0"
+
+// errors returned by the Ledger device
+var (
+	ErrLedgerRejected  = errors.New("rejected on the ledger device")
+	ErrLedgerAppClosed = errors.New("the EOS app is not open on the ledger device")
+)
+
+// APDU instructions of the EOS Ledger app
+const (
+	ledgerCLA          = 0xd4
+	ledgerInsPublicKey = 0x02
+	ledgerInsSign      = 0x04
+	ledgerChunkSize    = 150
+)
+
+// LedgerSigner is a Signer for keys on a Ledger device running the EOS app. The app only signs transactions it can
+// show to the user, so SignDigest is not available and each signature must be approved on the device.
+type LedgerSigner struct {
+	transport LedgerTransport
+	keys      remoteKeys // id is the derivation path
+}
+
+// NewLedgerSigner creates a Signer for the keys at the derivation paths, LedgerEOSPath when none are given
+func NewLedgerSigner(ctx context.Context, transport LedgerTransport, paths ...string) (*LedgerSigner, error) {
+	if transport == nil {
+		return nil, errors.New("transport cannot be nil")
+	}
+	if len(paths) == 0 {
+		paths = []string{LedgerEOSPath}
+	}
+	l := &LedgerSigner{transport: transport}
+	for _, path := range paths {
+		pub, err := LedgerPublicKey(ctx, transport, path, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		l.keys = append(l.keys, remoteKey{id: path, pub: *pub})
+	}
+	return l, nil
+}
+
+// LedgerPublicKey reads the public key at a derivation path. With confirm the key is shown on the device and must be
+// approved, which proves the key really belongs to it.
+func LedgerPublicKey(ctx context.Context, transport LedgerTransport, path string, confirm bool) (*ecc.PublicKey, error) {
+	encoded, err := ledgerPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var p1 byte
+	if confirm {
+		p1 = 1
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp, err := ledgerExchange(transport, ledgerInsPublicKey, p1, encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 1 || len(resp) < 1+int(resp[0]) {
+		return nil, errors.New("short public key response from the ledger")
+	}
+	point, err := btcec.ParsePubKey(resp[1:1+int(resp[0])], btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	return fioPubKey(point)
+}
+
+// PublicKeys lists the FIO public keys of the Ledger keys
+func (l *LedgerSigner) PublicKeys(ctx context.Context) ([]string, error) {
+	return l.keys.publicKeys(), nil
+}
+
+// Path provides the derivation path of pub
+func (l *LedgerSigner) Path(pub string) (string, error) {
+	k, err := l.keys.key(pub)
+	if err != nil {
+		return "", err
+	}
+	return k.id, nil
+}
+
+// SignDigest is not possible, the EOS app will not sign anything it cannot display
+func (l *LedgerSigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	return ecc.Signature{}, errors.New("the ledger only signs complete transactions")
+}
+
+// SignTransaction sends a transaction to the device to be reviewed and signed with each of pubkeys, accepting the
+// same transactions as KeosClient.SignTransaction. The user approves each signature on the device.
+func (l *LedgerSigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	if tx == nil {
+		return nil, errors.New("transaction cannot be nil")
+	}
+	if len(pubkeys) == 0 {
+		return nil, errors.New("at least one public key is required")
+	}
+	id, err := hex.DecodeString(chainID)
+	if err != nil || len(id) != 32 {
+		return nil, errors.New("chain ID must be 32 bytes of hex")
+	}
+	trx, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := PackTransactionJSON(trx)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := ledgerTransaction(id, packed)
+	if err != nil {
+		return nil, err
+	}
+	digest := TransactionDigest(id, packed, nil)
+	sigs := make([]ecc.Signature, len(pubkeys))
+	for i, pub := range pubkeys {
+		k, err := l.keys.key(pub)
+		if err != nil {
+			return nil, err
+		}
+		if sigs[i], err = l.sign(ctx, k, encoded, digest); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
+
+func (l *LedgerSigner) sign(ctx context.Context, k remoteKey, encoded []byte, digest []byte) (ecc.Signature, error) {
+	path, err := ledgerPath(k.id)
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	data := append(path, encoded...)
+	var resp []byte
+	for offset := 0; offset < len(data); offset += ledgerChunkSize {
+		if err = ctx.Err(); err != nil {
+			return ecc.Signature{}, err
+		}
+		end := offset + ledgerChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		var p1 byte
+		if offset > 0 {
+			p1 = 0x80
+		}
+		if resp, err = ledgerExchange(l.transport, ledgerInsSign, p1, data[offset:end]); err != nil {
+			return ecc.Signature{}, err
+		}
+	}
+	if len(resp) != 65 {
+		return ecc.Signature{}, fmt.Errorf("unexpected signature length %d from the ledger", len(resp))
+	}
+	sig, err := ecc.NewSignatureFromData(append([]byte{byte(ecc.CurveK1)}, resp...))
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	if !sig.Verify(digest, k.pub) {
+		return ecc.Signature{}, errors.New("the ledger signature does not match the transaction")
+	}
+	return sig, nil
+}
+
+// ledgerExchange sends one APDU to the EOS app and checks the status word
+func ledgerExchange(transport LedgerTransport, ins byte, p1 byte, data []byte) ([]byte, error) {
+	if len(data) > 255 {
+		return nil, errors.New("apdu data is too long")
+	}
+	apdu := append([]byte{ledgerCLA, ins, p1, 0, byte(len(data))}, data...)
+	resp, err := transport.Exchange(apdu)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 {
+		return nil, errors.New("ledger response is missing the status word")
+	}
+	switch sw := binary.BigEndian.Uint16(resp[len(resp)-2:]); sw {
+	case 0x9000:
+		return resp[:len(resp)-2], nil
+	case 0x6985:
+		return nil, ErrLedgerRejected
+	case 0x6d00, 0x6e00:
+		return nil, ErrLedgerAppClosed
+	default:
+		return nil, fmt.Errorf("ledger returned status %04x", sw)
+	}
+}
+
+// ledgerPath encodes a derivation path as a count followed by each big endian element
+func ledgerPath(path string) ([]byte, error) {
+	segments, err := hdwallet.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 || len(segments) > 10 {
+		return nil, errors.New("invalid derivation path length")
+	}
+	encoded := []byte{byte(len(segments))}
+	for _, s := range segments {
+		encoded = append(encoded, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(encoded[len(encoded)-4:], s)
+	}
+	return encoded, nil
+}
+
+// ledgerTransaction re-encodes a packed transaction the way the EOS app parses it: each field of the transaction
+// as its own ASN.1 octet string, starting with the chain ID and ending with the context free data hash
+func ledgerTransaction(chainID []byte, packed []byte) ([]byte, error) {
+	r := bytes.NewReader(packed)
+	out := &bytes.Buffer{}
+	fixed := func(n int) error {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return err
+		}
+		ledgerField(out, b)
+		return nil
+	}
+	varUint := func() (uint64, error) {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, err
+		}
+		b := &bytes.Buffer{}
+		writeVarUint(b, v)
+		ledgerField(out, b.Bytes())
+		return v, nil
+	}
+
+	ledgerField(out, chainID)
+	// expiration, ref_block_num, ref_block_prefix, max_net_usage_words, max_cpu_usage_ms, delay_sec
+	for _, n := range []int{4, 2, 4, 0, 1, 0} {
+		var err error
+		if n == 0 {
+			_, err = varUint()
+		} else {
+			err = fixed(n)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction header: %w", err)
+		}
+	}
+	if cfa, err := varUint(); err != nil || cfa != 0 {
+		return nil, errors.New("the ledger does not sign context free actions")
+	}
+	actions, err := varUint()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < actions; i++ {
+		// account and name
+		if err = fixed(8); err == nil {
+			err = fixed(8)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("action %d: %w", i, err)
+		}
+		auths, err := varUint()
+		if err != nil {
+			return nil, fmt.Errorf("action %d: %w", i, err)
+		}
+		for j := uint64(0); j < auths*2; j++ {
+			if err = fixed(8); err != nil {
+				return nil, fmt.Errorf("action %d: %w", i, err)
+			}
+		}
+		size, err := varUint()
+		if err != nil || size > uint64(r.Len()) {
+			return nil, fmt.Errorf("action %d has invalid data", i)
+		}
+		if err = fixed(int(size)); err != nil {
+			return nil, fmt.Errorf("action %d: %w", i, err)
+		}
+	}
+	if ext, err := varUint(); err != nil || ext != 0 {
+		return nil, errors.New("the ledger does not sign transaction extensions")
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("unexpected data after the transaction")
+	}
+	ledgerField(out, make([]byte, 32))
+	return out.Bytes(), nil
+}
+
+// ledgerField writes b as an ASN.1 octet string
+func ledgerField(w *bytes.Buffer, b []byte) {
+	w.WriteByte(0x04)
+	switch {
+	case len(b) < 0x80:
+		w.WriteByte(byte(len(b)))
+	case len(b) <= 0xff:
+		w.Write([]byte{0x81, byte(len(b))})
+	default:
+		w.Write([]byte{0x82, byte(len(b) >> 8), byte(len(b))})
+	}
+	w.Write(b)
+}
+
+// ledgerHID frames APDUs into the 64 byte HID reports a Ledger device uses
+type ledgerHID struct {
+	dev io.ReadWriter
+}
+
+// NewLedgerHID provides a LedgerTransport over an opened Ledger HID device, such as one from karalabe/hid. Each
+// Read and Write must be a single 64 byte report.
+func NewLedgerHID(dev io.ReadWriter) LedgerTransport {
+	return &ledgerHID{dev: dev}
+}
+
+// Exchange writes the APDU as numbered reports on channel 0x0101 and reads the response the same way
+func (h *ledgerHID) Exchange(apdu []byte) ([]byte, error) {
+	if len(apdu) > 0xffff {
+		return nil, errors.New("apdu is too long")
+	}
+	data := append([]byte{byte(len(apdu) >> 8), byte(len(apdu))}, apdu...)
+	for seq := 0; len(data) > 0; seq++ {
+		report := make([]byte, 64)
+		copy(report, []byte{0x01, 0x01, 0x05, byte(seq >> 8), byte(seq)})
+		n := copy(report[5:], data)
+		data = data[n:]
+		if _, err := h.dev.Write(report); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp []byte
+	total := -1
+	for seq := 0; total < 0 || len(resp) < total; seq++ {
+		report := make([]byte, 64)
+		n, err := h.dev.Read(report)
+		if err != nil {
+			return nil, err
+		}
+		if n < 7 || report[0] != 0x01 || report[1] != 0x01 || report[2] != 0x05 ||
+			int(binary.BigEndian.Uint16(report[3:5])) != seq {
+			return nil, errors.New("unexpected hid report from the ledger")
+		}
+		payload := report[5:n]
+		if seq == 0 {
+			total = int(binary.BigEndian.Uint16(payload))
+			payload = payload[2:]
+		}
+		resp = append(resp, payload...)
+	}
+	return resp[:total], nil
+}
@@ -0,0 +1,172 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+// fakeLedgerApp answers APDUs like the EOS app, signing whatever it is sent once the last chunk arrives
+type fakeLedgerApp struct {
+	key    *btcec.PrivateKey
+	reject bool
+	signed []byte
+}
+
+func (f *fakeLedgerApp) Exchange(apdu []byte) ([]byte, error) {
+	if len(apdu) < 5 || apdu[0] != ledgerCLA || int(apdu[4]) != len(apdu)-5 {
+		return []byte{0x6e, 0x00}, nil
+	}
+	data := apdu[5:]
+	switch apdu[1] {
+	case ledgerInsPublicKey:
+		pub := f.key.PubKey().SerializeUncompressed()
+		resp := append([]byte{byte(len(pub))}, pub...)
+		return append(resp, 0x90, 0x00), nil
+	case ledgerInsSign:
+		if f.reject {
+			return []byte{0x69, 0x85}, nil
+		}
+		if apdu[2] == 0 {
+			// skip the derivation path
+			f.signed = append([]byte{}, data[1+4*int(data[0]):]...)
+		} else {
+			f.signed = append(f.signed, data...)
+		}
+		// the digest is the hash of every field value
+		h := sha256.New()
+		for rest := f.signed; len(rest) > 0; {
+			field := asn1.RawValue{}
+			var err error
+			if rest, err = asn1.Unmarshal(rest, &field); err != nil {
+				// more chunks to come
+				return []byte{0x90, 0x00}, nil
+			}
+			_, _ = h.Write(field.Bytes)
+		}
+		sig, err := btcec.SignCompact(btcec.S256(), f.key, h.Sum(nil), true)
+		if err != nil {
+			return nil, err
+		}
+		return append(sig, 0x90, 0x00), nil
+	}
+	return []byte{0x6d, 0x00}, nil
+}
+
+// fakeHID is a loopback HID device, it reassembles the reports written into an APDU for the app and splits the
+// response into reports to be read
+type fakeHID struct {
+	app     LedgerTransport
+	written []byte
+	reports [][]byte
+}
+
+func (f *fakeHID) Write(report []byte) (int, error) {
+	f.written = append(f.written, report[5:]...)
+	size := int(binary.BigEndian.Uint16(f.written))
+	if len(f.written)-2 < size {
+		return len(report), nil
+	}
+	resp, err := f.app.Exchange(f.written[2 : 2+size])
+	f.written = nil
+	if err != nil {
+		return 0, err
+	}
+	data := append([]byte{byte(len(resp) >> 8), byte(len(resp))}, resp...)
+	for seq := 0; len(data) > 0; seq++ {
+		r := make([]byte, 64)
+		copy(r, []byte{0x01, 0x01, 0x05, byte(seq >> 8), byte(seq)})
+		data = data[copy(r[5:], data):]
+		f.reports = append(f.reports, r)
+	}
+	return len(report), nil
+}
+
+func (f *fakeHID) Read(report []byte) (int, error) {
+	if len(f.reports) == 0 {
+		return 0, errors.New("nothing to read")
+	}
+	n := copy(report, f.reports[0])
+	f.reports = f.reports[1:]
+	return n, nil
+}
+
+func TestLedgerSigner(t *testing.T) {
+	priv, err := ecc.NewPrivateKey("5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	raw, err := rawPrivateKey(priv)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), raw)
+	app := &fakeLedgerApp{key: key}
+
+	ctx := context.Background()
+	l, err := NewLedgerSigner(ctx, NewLedgerHID(&fakeHID{app: app}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := l.PublicKeys(ctx)
+	if len(pubs) != 1 || pubs[0] != priv.PublicKey().String() {
+		t.Error("unexpected public keys", pubs)
+		return
+	}
+	if path, _ := l.Path(pubs[0]); path != LedgerEOSPath {
+		t.Error("unexpected path", path)
+	}
+
+	// long enough action data to need several chunks
+	tx := map[string]interface{}{
+		"expiration":       "2020-07-01T00:00:00",
+		"ref_block_num":    1234,
+		"ref_block_prefix": 56789,
+		"actions": []map[string]interface{}{{
+			"account":       "fio.token",
+			"name":          "trnsfiopubky",
+			"authorization": []map[string]string{{"actor": "aftyershcu22", "permission": "active"}},
+			"hex_data":      string(bytes.Repeat([]byte("ab"), 300)),
+		}},
+	}
+	sigs, err := l.SignTransaction(ctx, tx, pubs, FioMainnetChainID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(sigs) != 1 || !sigs[0].Verify(digestFor(t, tx), priv.PublicKey()) {
+		t.Error("signature did not verify")
+	}
+
+	app.reject = true
+	if _, err = l.SignTransaction(ctx, tx, pubs, FioMainnetChainID); !errors.Is(err, ErrLedgerRejected) {
+		t.Error("expected ErrLedgerRejected", err)
+	}
+	if _, err = l.SignDigest(ctx, make([]byte, 32), pubs[0]); err == nil {
+		t.Error("expected digest signing to be refused")
+	}
+}
+
+func digestFor(t *testing.T, tx interface{}) []byte {
+	b, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := PackTransactionJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := hex.DecodeString(FioMainnetChainID)
+	return TransactionDigest(id, packed, nil)
+}
@@ -0,0 +1,381 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Wallet is the set of wallet operations shared by KeosClient and LocalWallet, so code can use either keosd or a
+// local wallet directory
+type Wallet interface {
+	Create(ctx context.Context, wallet string) (string, error)
+	Unlock(ctx context.Context, password string, wallet string) error
+	Lock(ctx context.Context, wallet string) error
+	LockAll(ctx context.Context) error
+	ListWallets(ctx context.Context) ([]KeosWallet, error)
+	ImportKey(ctx context.Context, wallet string, wif string) error
+	RemoveKey(ctx context.Context, wallet string, password string, pub string) error
+	GetPublicKeys(ctx context.Context) ([]string, error)
+	SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error)
+	SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error)
+}
+
+// LocalWallet manages keosd compatible .wallet files in a directory without running keosd, the same files can be
+// opened by keosd and clio. Unlocked keys are held in memory until Lock, LockAll, or the UnlockTimeout passes.
+type LocalWallet struct {
+	dir string
+
+	// UnlockTimeout locks the wallets after a period without use, as keosd's --unlock-timeout does. Zero leaves
+	// them unlocked until Lock is called.
+	UnlockTimeout time.Duration
+
+	mux      sync.Mutex
+	unlocked map[string]*localSession
+	lastUsed time.Time
+}
+
+type localSession struct {
+	password string
+	keys     []*ecc.PrivateKey
+}
+
+// NewLocalWallet uses dir for wallet files, it is created if it does not exist
+func NewLocalWallet(dir string) (*LocalWallet, error) {
+	if dir == "" {
+		return nil, errors.New("a wallet directory is required")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &LocalWallet{dir: dir, unlocked: make(map[string]*localSession)}, nil
+}
+
+// Create creates a new wallet file with a random password, returning the password. The wallet is left unlocked.
+func (lw *LocalWallet) Create(ctx context.Context, wallet string) (string, error) {
+	if err := validWalletName(wallet); err != nil {
+		return "", err
+	}
+	password, err := NewKeosPassword()
+	if err != nil {
+		return "", err
+	}
+	data, err := EncryptKeosWallet(password, nil)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(lw.path(wallet), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return "", ErrWalletExists
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	lw.touch()
+	lw.unlocked[wallet] = &localSession{password: password}
+	return password, nil
+}
+
+// Unlock decrypts a wallet file and holds its keys, it does not return an error if already unlocked
+func (lw *LocalWallet) Unlock(ctx context.Context, password string, wallet string) error {
+	if err := validWalletName(wallet); err != nil {
+		return err
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	lw.expire()
+	lw.touch()
+	if _, ok := lw.unlocked[wallet]; ok {
+		return nil
+	}
+	keys, err := lw.read(wallet, password)
+	if err != nil {
+		return err
+	}
+	lw.unlocked[wallet] = &localSession{password: password, keys: keys}
+	return nil
+}
+
+// Lock forgets the keys of a wallet
+func (lw *LocalWallet) Lock(ctx context.Context, wallet string) error {
+	if err := validWalletName(wallet); err != nil {
+		return err
+	}
+	if _, err := os.Stat(lw.path(wallet)); err != nil {
+		return ErrWalletNotFound
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	delete(lw.unlocked, wallet)
+	return nil
+}
+
+// LockAll forgets the keys of every wallet
+func (lw *LocalWallet) LockAll(ctx context.Context) error {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	lw.unlocked = make(map[string]*localSession)
+	return nil
+}
+
+// ListWallets lists the wallet files in the directory and whether they are unlocked
+func (lw *LocalWallet) ListWallets(ctx context.Context) ([]KeosWallet, error) {
+	files, err := filepath.Glob(filepath.Join(lw.dir, "*.wallet"))
+	if err != nil {
+		return nil, err
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	lw.expire()
+	lw.touch()
+	wallets := make([]KeosWallet, len(files))
+	for i, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".wallet")
+		_, unlocked := lw.unlocked[name]
+		wallets[i] = KeosWallet{Name: name, Unlocked: unlocked}
+	}
+	return wallets, nil
+}
+
+// ImportKey adds a WIF key to an unlocked wallet and rewrites its file
+func (lw *LocalWallet) ImportKey(ctx context.Context, wallet string, wif string) error {
+	key, err := ecc.NewPrivateKey(wif)
+	if err != nil {
+		return err
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	session, err := lw.session(wallet)
+	if err != nil {
+		return err
+	}
+	pub := key.PublicKey()
+	for _, k := range session.keys {
+		if samePublicKey(k.PublicKey(), pub) {
+			return ErrKeyExists
+		}
+	}
+	keys := append(append([]*ecc.PrivateKey{}, session.keys...), key)
+	if err = lw.write(wallet, session.password, keys); err != nil {
+		return err
+	}
+	session.keys = keys
+	return nil
+}
+
+// CreateKey creates a random K1 key in an unlocked wallet and returns the public key
+func (lw *LocalWallet) CreateKey(ctx context.Context, wallet string) (string, error) {
+	key, err := ecc.NewRandomPrivateKey()
+	if err != nil {
+		return "", err
+	}
+	if err = lw.ImportKey(ctx, wallet, key.String()); err != nil {
+		return "", err
+	}
+	return key.PublicKey().String(), nil
+}
+
+// RemoveKey removes a key from a wallet and rewrites its file, the password is required as it is by keosd
+func (lw *LocalWallet) RemoveKey(ctx context.Context, wallet string, password string, pub string) error {
+	target, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	session, err := lw.session(wallet)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(session.password), []byte(password)) != 1 {
+		return ErrInvalidPassword
+	}
+	keys := make([]*ecc.PrivateKey, 0, len(session.keys))
+	for _, k := range session.keys {
+		if !samePublicKey(k.PublicKey(), target) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == len(session.keys) {
+		return ErrKeyNotFound
+	}
+	if err = lw.write(wallet, password, keys); err != nil {
+		return err
+	}
+	session.keys = keys
+	return nil
+}
+
+// ListKeys provides the [public, private] key pairs of a wallet, the same as keosd's list_keys
+func (lw *LocalWallet) ListKeys(ctx context.Context, wallet string, password string) ([][]string, error) {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	session, err := lw.session(wallet)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(session.password), []byte(password)) != 1 {
+		return nil, ErrInvalidPassword
+	}
+	pairs := make([][]string, len(session.keys))
+	for i, k := range session.keys {
+		pairs[i] = []string{k.PublicKey().String(), k.String()}
+	}
+	return pairs, nil
+}
+
+// GetPublicKeys lists the public keys in all unlocked wallets
+func (lw *LocalWallet) GetPublicKeys(ctx context.Context) ([]string, error) {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	lw.expire()
+	if len(lw.unlocked) == 0 {
+		return nil, ErrWalletLocked
+	}
+	lw.touch()
+	pubs := make([]string, 0)
+	for _, session := range lw.unlocked {
+		for _, k := range session.keys {
+			pubs = append(pubs, k.PublicKey().String())
+		}
+	}
+	sort.Strings(pubs)
+	return pubs, nil
+}
+
+// SignDigest signs a 32 byte digest with the key for pub, which must be in an unlocked wallet
+func (lw *LocalWallet) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	if len(digest) != 32 {
+		return ecc.Signature{}, errors.New("digest must be 32 bytes")
+	}
+	key, err := lw.key(pub)
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	return key.Sign(digest)
+}
+
+// SignTransaction signs a transaction with each of pubkeys, accepting the same transactions as
+// KeosClient.SignTransaction
+func (lw *LocalWallet) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	return signTransaction(ctx, lw, tx, pubkeys, chainID)
+}
+
+// key finds the private key for pub in the unlocked wallets
+func (lw *LocalWallet) key(pub string) (*ecc.PrivateKey, error) {
+	target, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	lw.expire()
+	lw.touch()
+	for _, session := range lw.unlocked {
+		for _, k := range session.keys {
+			if samePublicKey(k.PublicKey(), target) {
+				return k, nil
+			}
+		}
+	}
+	return nil, ErrWalletMissingKey
+}
+
+// session provides an unlocked wallet, the lock must be held
+func (lw *LocalWallet) session(wallet string) (*localSession, error) {
+	lw.expire()
+	lw.touch()
+	if session, ok := lw.unlocked[wallet]; ok {
+		return session, nil
+	}
+	if _, err := os.Stat(lw.path(wallet)); err != nil {
+		return nil, ErrWalletNotFound
+	}
+	return nil, ErrWalletLocked
+}
+
+// expire locks everything if the unlock timeout has passed, the lock must be held
+func (lw *LocalWallet) expire() {
+	if lw.UnlockTimeout > 0 && !lw.lastUsed.IsZero() && time.Since(lw.lastUsed) > lw.UnlockTimeout {
+		lw.unlocked = make(map[string]*localSession)
+	}
+}
+
+func (lw *LocalWallet) touch() {
+	lw.lastUsed = time.Now()
+}
+
+func (lw *LocalWallet) path(wallet string) string {
+	return filepath.Join(lw.dir, wallet+".wallet")
+}
+
+func (lw *LocalWallet) read(wallet string, password string) ([]*ecc.PrivateKey, error) {
+	data, err := ioutil.ReadFile(lw.path(wallet))
+	if os.IsNotExist(err) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKeosWallet(data, password)
+}
+
+// write replaces a wallet file, writing to a temporary file first so a failure cannot leave it truncated
+func (lw *LocalWallet) write(wallet string, password string, keys []*ecc.PrivateKey) error {
+	data, err := EncryptKeosWallet(password, keys)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(lw.dir, "."+wallet+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), lw.path(wallet))
+}
+
+// validWalletName rejects names that keosd would not accept, which also keeps them inside the directory
+func validWalletName(wallet string) error {
+	if wallet == "" {
+		return errors.New("wallet name cannot be empty")
+	}
+	for _, r := range wallet {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return fmt.Errorf("wallet name %q may only contain letters, numbers, '.', '_', and '-'", wallet)
+		}
+	}
+	if strings.HasPrefix(wallet, ".") {
+		return fmt.Errorf("wallet name %q cannot start with '.'", wallet)
+	}
+	return nil
+}
+
+func samePublicKey(a ecc.PublicKey, b ecc.PublicKey) bool {
+	return a.Curve == b.Curve && bytes.Equal(a.Content, b.Content)
+}
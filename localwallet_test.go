@@ -0,0 +1,124 @@
+package fiox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// both backends implement Wallet
+var (
+	_ Wallet = &KeosClient{}
+	_ Wallet = &LocalWallet{}
+)
+
+func TestLocalWallet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallets")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	lw, err := NewLocalWallet(dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	password, err := lw.Create(ctx, "default")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = lw.Create(ctx, "default"); !errors.Is(err, ErrWalletExists) {
+		t.Error("expected ErrWalletExists, got", err)
+	}
+	if _, err = lw.Create(ctx, "../escape"); err == nil {
+		t.Error("expected an invalid wallet name")
+	}
+	if err = lw.Lock(ctx, "../escape"); err == nil || errors.Is(err, ErrWalletNotFound) {
+		t.Error("expected an invalid wallet name, got", err)
+	}
+	wif := "5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"
+	if err = lw.ImportKey(ctx, "default", wif); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = lw.ImportKey(ctx, "default", wif); !errors.Is(err, ErrKeyExists) {
+		t.Error("expected ErrKeyExists, got", err)
+	}
+	created, err := lw.CreateKey(ctx, "default")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// the file is readable by keosd, and by a new LocalWallet after locking
+	data, err := ioutil.ReadFile(filepath.Join(dir, "default.wallet"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if keys, err := DecryptKeosWallet(data, password); err != nil || len(keys) != 2 {
+		t.Error("wallet file does not hold both keys", err)
+	}
+	if err = lw.Lock(ctx, "default"); err != nil {
+		t.Error(err)
+	}
+	if _, err = lw.GetPublicKeys(ctx); !errors.Is(err, ErrWalletLocked) {
+		t.Error("expected ErrWalletLocked, got", err)
+	}
+	if err = lw.Unlock(ctx, "wrong", "default"); !errors.Is(err, ErrInvalidPassword) {
+		t.Error("expected ErrInvalidPassword, got", err)
+	}
+	if err = lw.Unlock(ctx, password, "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, err := lw.GetPublicKeys(ctx)
+	if err != nil || len(pubs) != 2 {
+		t.Error("expected 2 keys", pubs, err)
+	}
+
+	key, _ := ecc.NewPrivateKey(wif)
+	digest := make([]byte, 32)
+	sig, err := lw.SignDigest(ctx, digest, key.PublicKey().String())
+	if err != nil || !sig.Verify(digest, key.PublicKey()) {
+		t.Error("digest signature did not verify", err)
+	}
+	trx := json.RawMessage(`{"expiration":"2020-10-01T00:00:00","ref_block_num":1,"ref_block_prefix":2,"actions":[{"account":"eosio","name":"regproducer","authorization":[],"data":"0102ff"}]}`)
+	sigs, err := lw.SignTransaction(ctx, trx, []string{key.PublicKey().String()}, FioTestnetChainID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	packed, _ := PackTransactionJSON(trx)
+	chainID, _ := hex.DecodeString(FioTestnetChainID)
+	if !sigs[0].Verify(TransactionDigest(chainID, packed, nil), key.PublicKey()) {
+		t.Error("transaction signature did not verify")
+	}
+
+	if err = lw.RemoveKey(ctx, "default", password, created); err != nil {
+		t.Error(err)
+	}
+	if err = lw.RemoveKey(ctx, "default", password, created); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("expected ErrKeyNotFound, got", err)
+	}
+
+	lw.UnlockTimeout = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	if _, err = lw.SignDigest(ctx, digest, key.PublicKey().String()); !errors.Is(err, ErrWalletMissingKey) {
+		t.Error("expected the wallet to lock after the timeout, got", err)
+	}
+	wallets, err := lw.ListWallets(ctx)
+	if err != nil || len(wallets) != 1 || wallets[0] != (KeosWallet{"default", false}) {
+		t.Error("unexpected wallets", wallets, err)
+	}
+}
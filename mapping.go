@@ -0,0 +1,87 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// MaxAddressesPerAction is the most public addresses one addaddress or remaddress action may hold
+const MaxAddressesPerAction = 5
+
+// MapAddresses maps public addresses on other chains to a FIO address owned by the paying key, such as those from
+// Hd.BtcAddressAt and Hd.EthAddressAt. The addresses are sent MaxAddressesPerAction at a time, each in its own
+// transaction using a bundled transaction when one is left. The results of the transactions already sent are
+// returned with any error.
+func (r *Registrar) MapAddresses(ctx context.Context, fioAddress string, addresses ...ChainAddress) ([]*PushResult, error) {
+	return r.mapAddresses(ctx, "addaddress", "add_pub_address", fioAddress, addresses)
+}
+
+// UnmapAddresses removes public addresses from a FIO address, only the chain, token, and address must match
+func (r *Registrar) UnmapAddresses(ctx context.Context, fioAddress string, addresses ...ChainAddress) ([]*PushResult, error) {
+	return r.mapAddresses(ctx, "remaddress", "remove_pub_address", fioAddress, addresses)
+}
+
+func (r *Registrar) mapAddresses(ctx context.Context, action string, endpoint string, fioAddress string, addresses []ChainAddress) ([]*PushResult, error) {
+	if fioAddress == "" || len(addresses) == 0 {
+		return nil, errors.New("a fio address and at least one public address are required")
+	}
+	for _, a := range addresses {
+		if err := validChainAddress(a); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]*PushResult, 0, (len(addresses)+MaxAddressesPerAction-1)/MaxAddressesPerAction)
+	for start := 0; start < len(addresses); start += MaxAddressesPerAction {
+		end := start + MaxAddressesPerAction
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		bd, err := r.fees.BundledFee(ctx, endpoint, fioAddress)
+		if err != nil {
+			return results, err
+		}
+		data, err := addressMappingData(fioAddress, addresses[start:end], bd.MaxFee, string(r.actor), r.TPID)
+		if err != nil {
+			return results, err
+		}
+		result, err := sendActions(ctx, r.api, r.signer, r.pub, newAction("fio.address", action, r.actor, data))
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// addressMappingData serializes addaddress and remaddress, which share a layout
+func addressMappingData(fioAddress string, addresses []ChainAddress, maxFee uint64, actor string, tpid string) ([]byte, error) {
+	if len(addresses) > MaxAddressesPerAction {
+		return nil, fmt.Errorf("at most %d addresses can be sent in one action", MaxAddressesPerAction)
+	}
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, fioAddress)
+	writeVarUint(buf, uint64(len(addresses)))
+	for _, a := range addresses {
+		writeAbiStrings(buf, a.TokenCode, a.ChainCode, a.PublicAddress)
+	}
+	_ = binary.Write(buf, binary.LittleEndian, maxFee)
+	if err := writeAbiName(buf, actor); err != nil {
+		return nil, err
+	}
+	writeAbiStrings(buf, tpid)
+	return buf.Bytes(), nil
+}
+
+// validChainAddress applies the length limits of the fio.address contract
+func validChainAddress(a ChainAddress) error {
+	if len(a.ChainCode) < 1 || len(a.ChainCode) > 10 || len(a.TokenCode) < 1 || len(a.TokenCode) > 10 {
+		return fmt.Errorf("invalid chain or token code %q/%q", a.ChainCode, a.TokenCode)
+	}
+	if len(a.PublicAddress) < 1 || len(a.PublicAddress) > 128 {
+		return fmt.Errorf("invalid public address for %s", a.ChainCode)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestRegistrar_MapAddresses(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pub, _ := hd.KeySet(0, 1)
+	nodeos := newTestNodeos(map[string]interface{}{
+		"/v1/chain/get_pub_address": map[string]string{"public_address": pub.Keys[0].PublicKey.String()},
+		"/v1/chain/get_fio_names": map[string]interface{}{"fio_addresses": []map[string]interface{}{
+			{"fio_address": "alice@fiotestnet", "remaining_bundled_tx": 10},
+		}},
+	})
+	defer nodeos.Close()
+	r, err := hd.Registrar(nodeos.api(), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	addresses := make([]ChainAddress, 0)
+	btc, _ := hd.BtcAddressAt(0, true)
+	eth, _ := hd.EthAddressAt(0)
+	addresses = append(addresses, *btc, *eth)
+	for i := 0; i < 5; i++ {
+		addresses = append(addresses, ChainAddress{ChainCode: "ETH", TokenCode: fmt.Sprintf("TKN%d", i), PublicAddress: eth.PublicAddress})
+	}
+	results, err := r.MapAddresses(context.Background(), "alice@fiotestnet", addresses...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(results) != 2 || len(nodeos.pushed) != 2 {
+		t.Error("expected the addresses to be sent in two transactions", len(results))
+		return
+	}
+	first := nodeos.checkPushed(t, 0, r.pub)
+	expect, _ := addressMappingData("alice@fiotestnet", addresses[:5], 0, string(r.actor), "")
+	if !bytes.Equal(first[0], expect) {
+		t.Errorf("unexpected addaddress data %x", first[0])
+	}
+	second := nodeos.checkPushed(t, 1, r.pub)
+	expect, _ = addressMappingData("alice@fiotestnet", addresses[5:], 0, string(r.actor), "")
+	if !bytes.Equal(second[0], expect) {
+		t.Errorf("unexpected addaddress data %x", second[0])
+	}
+
+	if _, err = r.UnmapAddresses(context.Background(), "alice@fiotestnet", ChainAddress{ChainCode: "BTC"}); err == nil {
+		t.Error("expected an invalid address to be rejected")
+	}
+	if _, err = addressMappingData("alice@fiotestnet", addresses, 0, "", ""); err == nil {
+		t.Error("expected too many addresses to be rejected")
+	}
+}
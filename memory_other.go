@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package fiox
+
+// lockMemory is not supported on this platform
+func lockMemory(b []byte) bool {
+	return false
+}
+
+func unlockMemory(b []byte) {}
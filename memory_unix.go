@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package fiox
+
+import "syscall"
+
+// lockMemory attempts to prevent b from being swapped to disk, it is best effort and may fail due to RLIMIT_MEMLOCK
+func lockMemory(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	return syscall.Mlock(b) == nil
+}
+
+func unlockMemory(b []byte) {
+	if len(b) > 0 {
+		_ = syscall.Munlock(b)
+	}
+}
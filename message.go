@@ -0,0 +1,81 @@
+package fiox
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"strconv"
+)
+
+// MessagePrefix is prepended to messages before signing, it keeps a signed message from ever being a valid
+// transaction signature.
+const MessagePrefix = "\x19FIO Signed Message:\n"
+
+// ErrBadSignature is returned by VerifyMessage when the signature was not made by the expected key
+var ErrBadSignature = errors.New("signature does not match the public key")
+
+// MessageDigest is the sha256 hash that is signed for a message: MessagePrefix, the length of the message in
+// decimal, then the message.
+func MessageDigest(message []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte(MessagePrefix + strconv.Itoa(len(message))))
+	_, _ = h.Write(message)
+	return h.Sum(nil)
+}
+
+// SignMessage signs an arbitrary message, returning a SIG_K1_ signature
+func SignMessage(key *ecc.PrivateKey, message []byte) (string, error) {
+	if key == nil {
+		return "", errors.New("key cannot be nil")
+	}
+	sig, err := key.Sign(MessageDigest(message))
+	if err != nil {
+		return "", err
+	}
+	return sig.String(), nil
+}
+
+// VerifyMessage checks that a signature from SignMessage was made by pub, returning ErrBadSignature if not
+func VerifyMessage(pub *ecc.PublicKey, message []byte, signature string) error {
+	signer, err := RecoverMessageKey(message, signature)
+	if err != nil {
+		return err
+	}
+	if pub == nil || pub.Curve != signer.Curve || string(pub.Content) != string(signer.Content) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// RecoverMessageKey provides the public key that signed a message
+func RecoverMessageKey(message []byte, signature string) (*ecc.PublicKey, error) {
+	sig, err := ecc.NewSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := sig.PublicKey(MessageDigest(message))
+	if err != nil {
+		return nil, err
+	}
+	return &pub, nil
+}
+
+// SignMessageAt signs a message with the key at index
+func (hd Hd) SignMessageAt(index int, message []byte) (string, error) {
+	key, err := hd.keyAt(index)
+	if err != nil {
+		return "", err
+	}
+	return SignMessage(key, message)
+}
+
+// SignMessage signs a message using a key held by keosd, the private key is never exported. The wallet must be
+// unlocked.
+func (k *KeosClient) SignMessage(ctx context.Context, pub string, message []byte) (string, error) {
+	sig, err := k.SignDigest(ctx, MessageDigest(message), pub)
+	if err != nil {
+		return "", err
+	}
+	return sig.String(), nil
+}
@@ -0,0 +1,76 @@
+package fiox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignMessage(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	message := []byte("I own alice@fiotestnet")
+	sig, err := hd.SignMessageAt(0, message)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.HasPrefix(sig, "SIG_K1_") {
+		t.Error("expected a SIG_K1_ signature, got", sig)
+	}
+	pub, _ := hd.PubKeyAt(0)
+	if err = VerifyMessage(pub, message, sig); err != nil {
+		t.Error(err)
+	}
+	if err = VerifyMessage(pub, []byte("I own bob@fiotestnet"), sig); err != ErrBadSignature {
+		t.Error("expected a bad signature for a different message, got", err)
+	}
+	other, _ := hd.PubKeyAt(1)
+	if err = VerifyMessage(other, message, sig); err != ErrBadSignature {
+		t.Error("expected a bad signature for a different key, got", err)
+	}
+}
+
+func TestKeosSignMessage(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := hd.keyAt(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		params := make([]string, 0)
+		if r.URL.Path != "/v1/wallet/sign_digest" || json.Unmarshal(body, &params) != nil || len(params) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		digest, _ := hex.DecodeString(params[0])
+		sig, err := key.Sign(digest)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(sig.String())
+	}))
+	defer server.Close()
+
+	pub := key.PublicKey()
+	message := []byte("hello")
+	sig, err := NewKeosClient(server.URL, "").SignMessage(context.Background(), pub.String(), message)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err = VerifyMessage(&pub, message, sig); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,166 @@
+package fiox
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives the outcome of every request, client is "keosd" or "nodeos" and endpoint is the request path.
+// RequestStats is a ready made implementation, or it can be adapted to an existing metrics library.
+type Metrics interface {
+	ObserveRequest(client string, endpoint string, duration time.Duration, err error)
+}
+
+// WithMetrics reports each request to keosd to m, every retry attempt is counted
+func WithMetrics(m Metrics) KeosOption {
+	return func(k *KeosClient) {
+		k.metrics = m
+	}
+}
+
+// MetricsTransport wraps an http.RoundTripper so requests made with it are reported to m, it can be used for the
+// nodeos client: api.HttpClient.Transport = MetricsTransport(api.HttpClient.Transport, m, "nodeos"). A nil next
+// uses http.DefaultTransport. Responses other than 200 are counted as errors.
+func MetricsTransport(next http.RoundTripper, m Metrics, client string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &metricsTransport{next: next, metrics: m, client: client}
+}
+
+type metricsTransport struct {
+	next    http.RoundTripper
+	metrics Metrics
+	client  string
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	observed := err
+	if err == nil && resp.StatusCode != http.StatusOK {
+		observed = fmt.Errorf("%s returned %d", req.URL.Path, resp.StatusCode)
+	}
+	t.metrics.ObserveRequest(t.client, req.URL.Path, time.Since(start), observed)
+	return resp, err
+}
+
+// DefaultLatencyBuckets are the upper bounds in seconds used by NewRequestStats when none are given
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// RequestStats counts requests, errors, and latency per endpoint. It is an http.Handler serving the Prometheus text
+// format, so it can be scraped without pulling in the Prometheus client library.
+type RequestStats struct {
+	mux     sync.Mutex
+	buckets []float64
+	series  map[statsKey]*RequestSeries
+}
+
+type statsKey struct {
+	client   string
+	endpoint string
+}
+
+// RequestSeries holds the counters for one client and endpoint, BucketCounts are cumulative like Prometheus
+type RequestSeries struct {
+	Client       string
+	Endpoint     string
+	Requests     uint64
+	Errors       uint64
+	Seconds      float64
+	BucketCounts []uint64
+}
+
+// NewRequestStats provides an empty RequestStats, buckets are latency upper bounds in seconds
+func NewRequestStats(buckets ...float64) *RequestStats {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64{}, buckets...)
+	sort.Float64s(b)
+	return &RequestStats{buckets: b, series: make(map[statsKey]*RequestSeries)}
+}
+
+// ObserveRequest records a request
+func (s *RequestStats) ObserveRequest(client string, endpoint string, duration time.Duration, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := statsKey{client: client, endpoint: endpoint}
+	series := s.series[key]
+	if series == nil {
+		series = &RequestSeries{Client: client, Endpoint: endpoint, BucketCounts: make([]uint64, len(s.buckets))}
+		s.series[key] = series
+	}
+	series.Requests++
+	if err != nil {
+		series.Errors++
+	}
+	seconds := duration.Seconds()
+	series.Seconds += seconds
+	for i, le := range s.buckets {
+		if seconds <= le {
+			series.BucketCounts[i]++
+		}
+	}
+}
+
+// Series provides a copy of the counters sorted by client and endpoint
+func (s *RequestStats) Series() []RequestSeries {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	list := make([]RequestSeries, 0, len(s.series))
+	for _, series := range s.series {
+		c := *series
+		c.BucketCounts = append([]uint64{}, series.BucketCounts...)
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Client != list[j].Client {
+			return list[i].Client < list[j].Client
+		}
+		return list[i].Endpoint < list[j].Endpoint
+	})
+	return list
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format
+func (s *RequestStats) WritePrometheus(w io.Writer) error {
+	series := s.Series()
+	b := &strings.Builder{}
+	b.WriteString("# HELP fiox_requests_total Requests made to keosd or nodeos.\n# TYPE fiox_requests_total counter\n")
+	for _, r := range series {
+		fmt.Fprintf(b, "fiox_requests_total{%s} %d\n", r.labels(), r.Requests)
+	}
+	b.WriteString("# HELP fiox_request_errors_total Requests that failed or returned an error.\n# TYPE fiox_request_errors_total counter\n")
+	for _, r := range series {
+		fmt.Fprintf(b, "fiox_request_errors_total{%s} %d\n", r.labels(), r.Errors)
+	}
+	b.WriteString("# HELP fiox_request_duration_seconds Request latency.\n# TYPE fiox_request_duration_seconds histogram\n")
+	for _, r := range series {
+		for i, le := range s.buckets {
+			fmt.Fprintf(b, "fiox_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", r.labels(),
+				strconv.FormatFloat(le, 'g', -1, 64), r.BucketCounts[i])
+		}
+		fmt.Fprintf(b, "fiox_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", r.labels(), r.Requests)
+		fmt.Fprintf(b, "fiox_request_duration_seconds_sum{%s} %g\n", r.labels(), r.Seconds)
+		fmt.Fprintf(b, "fiox_request_duration_seconds_count{%s} %d\n", r.labels(), r.Requests)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the counters for a Prometheus scrape
+func (s *RequestStats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_ = s.WritePrometheus(w)
+}
+
+func (r RequestSeries) labels() string {
+	return fmt.Sprintf("client=%s,endpoint=%s", strconv.Quote(r.Client), strconv.Quote(r.Endpoint))
+}
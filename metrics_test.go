@@ -0,0 +1,82 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestStats(t *testing.T) {
+	server := keosdServer(map[string]interface{}{"/v1/wallet/list_wallets": []string{"default *"}}, nil)
+	defer server.Close()
+
+	stats := NewRequestStats()
+	k := NewKeosClient(server.URL, "", WithMetrics(stats))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := k.ListWallets(ctx); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	if err := k.Lock(ctx, "default"); err == nil {
+		t.Error("expected lock to fail")
+	}
+
+	series := stats.Series()
+	if len(series) != 2 {
+		t.Error("expected 2 series, got", len(series))
+		return
+	}
+	if series[0].Endpoint != "/v1/wallet/list_wallets" || series[0].Requests != 3 || series[0].Errors != 0 {
+		t.Errorf("unexpected series %+v", series[0])
+	}
+	if series[1].Endpoint != "/v1/wallet/lock" || series[1].Requests != 1 || series[1].Errors != 1 {
+		t.Errorf("unexpected series %+v", series[1])
+	}
+
+	buf := &bytes.Buffer{}
+	if err := stats.WritePrometheus(buf); err != nil {
+		t.Error(err)
+		return
+	}
+	for _, line := range []string{
+		`fiox_requests_total{client="keosd",endpoint="/v1/wallet/list_wallets"} 3`,
+		`fiox_request_errors_total{client="keosd",endpoint="/v1/wallet/lock"} 1`,
+		`fiox_request_duration_seconds_bucket{client="keosd",endpoint="/v1/wallet/list_wallets",le="+Inf"} 3`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Error("missing", line)
+		}
+	}
+}
+
+func TestMetricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chain/get_info" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	stats := NewRequestStats(0.5, 0.1)
+	client := &http.Client{Transport: MetricsTransport(nil, stats, "nodeos")}
+	for _, path := range []string{"/v1/chain/get_info", "/v1/chain/missing"} {
+		resp, err := client.Post(server.URL+path, "application/json", nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = resp.Body.Close()
+	}
+	series := stats.Series()
+	if len(series) != 2 || series[0].Client != "nodeos" || series[0].Errors != 0 || series[1].Errors != 1 {
+		t.Errorf("unexpected series %+v", series)
+	}
+	if len(series[0].BucketCounts) != 2 || series[0].BucketCounts[1] != 1 {
+		t.Errorf("expected sorted cumulative buckets, got %v", series[0].BucketCounts)
+	}
+}
@@ -0,0 +1,135 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// ErrMigrationVerify is returned when a migrated key cannot be found in the destination afterward
+var ErrMigrationVerify = errors.New("migrated keys did not verify")
+
+// MigrationBackend is a wallet that can export its private keys, both KeosClient and LocalWallet qualify
+type MigrationBackend interface {
+	Wallet
+	ListKeys(ctx context.Context, wallet string, password string) ([][]string, error)
+}
+
+// MigrationWallet names a wallet in a backend, the password is needed to unlock it and list its keys
+type MigrationWallet struct {
+	Backend  MigrationBackend
+	Name     string
+	Password string
+}
+
+// MigrationReport lists what a migration did, or would do when it is a dry run. Keys are listed by public key.
+type MigrationReport struct {
+	DryRun   bool
+	Imported []string // copied to the destination
+	Skipped  []string // already in the destination, or repeated in the source
+	Invalid  []string // the private key does not match the public key keosd listed for it
+}
+
+func (r MigrationReport) String() string {
+	verb := "imported"
+	if r.DryRun {
+		verb = "would import"
+	}
+	return fmt.Sprintf("%s %d keys, skipped %d already present, %d invalid", verb, len(r.Imported), len(r.Skipped), len(r.Invalid))
+}
+
+// MigrateKeys copies the keys of one wallet into another, for example from keosd into a LocalWallet or back. Both
+// wallets are unlocked, keys the destination already holds are skipped, and after importing the destination is
+// listed again to verify every key arrived intact. With dryRun nothing is imported and the report describes what
+// would be done.
+func MigrateKeys(ctx context.Context, from MigrationWallet, to MigrationWallet, dryRun bool) (*MigrationReport, error) {
+	if from.Backend == nil || to.Backend == nil {
+		return nil, errors.New("both wallets need a backend")
+	}
+	for _, w := range []MigrationWallet{from, to} {
+		if err := w.Backend.Unlock(ctx, w.Password, w.Name); err != nil {
+			return nil, fmt.Errorf("could not unlock %s: %w", w.Name, err)
+		}
+	}
+	source, err := from.Backend.ListKeys(ctx, from.Name, from.Password)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := to.Backend.ListKeys(ctx, to.Name, to.Password)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool)
+	for _, pair := range existing {
+		present[keyContent(pair[0])] = true
+	}
+
+	report := &MigrationReport{DryRun: dryRun}
+	for _, pair := range source {
+		if len(pair) != 2 {
+			return nil, errors.New("unexpected key listing from the source wallet")
+		}
+		pub, wif := pair[0], pair[1]
+		if !keyPairMatches(pub, wif) {
+			report.Invalid = append(report.Invalid, pub)
+			continue
+		}
+		if present[keyContent(pub)] {
+			report.Skipped = append(report.Skipped, pub)
+			continue
+		}
+		present[keyContent(pub)] = true
+		report.Imported = append(report.Imported, pub)
+		if dryRun {
+			continue
+		}
+		if err = to.Backend.ImportKey(ctx, to.Name, wif); err != nil && !errors.Is(err, ErrKeyExists) {
+			return report, fmt.Errorf("could not import %s: %w", pub, err)
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	// verify the keys round trip, the destination must list the same key pairs
+	migrated, err := to.Backend.ListKeys(ctx, to.Name, to.Password)
+	if err != nil {
+		return report, err
+	}
+	found := make(map[string]bool)
+	for _, pair := range migrated {
+		if len(pair) == 2 && keyPairMatches(pair[0], pair[1]) {
+			found[keyContent(pair[0])] = true
+		}
+	}
+	for _, pub := range report.Imported {
+		if !found[keyContent(pub)] {
+			return report, fmt.Errorf("%w: %s is missing from %s", ErrMigrationVerify, pub, to.Name)
+		}
+	}
+	return report, nil
+}
+
+// keyContent is a comparable form of a public key in either the FIO or PUB_K1_ format
+func keyContent(pub string) string {
+	key, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return pub
+	}
+	return string(key.Content)
+}
+
+// keyPairMatches checks that a WIF private key belongs to the public key
+func keyPairMatches(pub string, wif string) bool {
+	priv, err := ecc.NewPrivateKey(wif)
+	if err != nil {
+		return false
+	}
+	key, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(priv.PublicKey().Content, key.Content)
+}
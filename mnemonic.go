@@ -0,0 +1,264 @@
+package fiox
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// Language identifies a BIP39 wordlist
+type Language string
+
+const (
+	LangEnglish            Language = "english"
+	LangJapanese           Language = "japanese"
+	LangKorean             Language = "korean"
+	LangSpanish            Language = "spanish"
+	LangChineseSimplified  Language = "chinese_simplified"
+	LangChineseTraditional Language = "chinese_traditional"
+	LangFrench             Language = "french"
+	LangItalian            Language = "italian"
+	LangCzech              Language = "czech"
+	// LangPortuguese is not bundled, the official list must be added with RegisterWordList before it can be used
+	LangPortuguese Language = "portuguese"
+)
+
+// Errors returned (possibly wrapped) when a mnemonic can't be used, check them with errors.Is
+var (
+	ErrMnemonicTooShort = errors.New("mnemonic is too short, should be 12, 15, 18, 21, or 24 words")
+	ErrMnemonicTooLong  = errors.New("mnemonic is too long, should be 12, 15, 18, 21, or 24 words")
+	ErrMnemonicLength   = errors.New("mnemonic length should be 12, 15, 18, 21, or 24 words")
+	ErrEmptyWord        = errors.New("malformed mnemonic, had empty word")
+	ErrUnknownWord      = errors.New("word is not in the wordlist")
+	ErrInvalidChecksum  = errors.New("mnemonic checksum is invalid")
+	ErrInvalidEntropy   = errors.New("entropy must be 128, 160, 192, 224, or 256 bits")
+	ErrUnknownLanguage  = errors.New("no wordlist available for language")
+	ErrInvalidWordList  = errors.New("wordlist must have 2048 unique, non-empty words")
+)
+
+// mnemonicLengthError picks the sentinel error for an invalid number of words
+func mnemonicLengthError(words int) error {
+	switch {
+	case words < 12:
+		return ErrMnemonicTooShort
+	case words > 24:
+		return ErrMnemonicTooLong
+	}
+	return ErrMnemonicLength
+}
+
+// languageOrder is the order wordlists are tried during detection, some lists share words so English is first.
+var languageOrder = []Language{LangEnglish, LangSpanish, LangFrench, LangItalian, LangCzech, LangPortuguese,
+	LangJapanese, LangKorean, LangChineseSimplified, LangChineseTraditional}
+
+type wordList struct {
+	words []string
+	index map[string]int // NFKD normalized word -> position
+}
+
+var (
+	wordListsMux sync.RWMutex
+	wordListsMap = map[Language]*wordList{}
+	bundledLists = map[Language][]string{
+		LangEnglish:            wordlists.English,
+		LangJapanese:           wordlists.Japanese,
+		LangKorean:             wordlists.Korean,
+		LangSpanish:            wordlists.Spanish,
+		LangChineseSimplified:  wordlists.ChineseSimplified,
+		LangChineseTraditional: wordlists.ChineseTraditional,
+		LangFrench:             wordlists.French,
+		LangItalian:            wordlists.Italian,
+		LangCzech:              wordlists.Czech,
+	}
+)
+
+// RegisterWordList adds (or replaces) the wordlist for a language, allowing lists that are not bundled, such as
+// Portuguese. The list must contain 2048 unique words.
+func RegisterWordList(language Language, words []string) error {
+	wl, err := newWordList(words)
+	if err != nil {
+		return err
+	}
+	wordListsMux.Lock()
+	wordListsMap[language] = wl
+	wordListsMux.Unlock()
+	return nil
+}
+
+func newWordList(words []string) (*wordList, error) {
+	if len(words) != 2048 {
+		return nil, fmt.Errorf("%w, got %d words", ErrInvalidWordList, len(words))
+	}
+	wl := &wordList{
+		words: make([]string, len(words)),
+		index: make(map[string]int, len(words)),
+	}
+	for i, w := range words {
+		n := norm.NFKD.String(strings.TrimSpace(w))
+		if _, dup := wl.index[n]; dup || n == "" {
+			return nil, fmt.Errorf("%w, empty or duplicate word at position %d", ErrInvalidWordList, i)
+		}
+		wl.words[i] = w
+		wl.index[n] = i
+	}
+	return wl, nil
+}
+
+// getWordList provides the wordlist for a language, bundled lists are indexed the first time they are used.
+func getWordList(language Language) (*wordList, error) {
+	wordListsMux.RLock()
+	wl := wordListsMap[language]
+	wordListsMux.RUnlock()
+	if wl != nil {
+		return wl, nil
+	}
+	words, ok := bundledLists[language]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownLanguage, language)
+	}
+	wl, err := newWordList(words)
+	if err != nil {
+		return nil, err
+	}
+	wordListsMux.Lock()
+	wordListsMap[language] = wl
+	wordListsMux.Unlock()
+	return wl, nil
+}
+
+// DetectLanguage finds the language of a mnemonic phrase, the phrase must have a valid checksum. If it can't be
+// detected, the error is a *MnemonicError as returned by ValidateMnemonic.
+func DetectLanguage(mnemonic string) (Language, error) {
+	language, _, _, err := detectWordList(strings.Fields(norm.NFKD.String(mnemonic)))
+	if err != nil {
+		if verr := ValidateMnemonic(mnemonic); verr != nil {
+			return "", verr
+		}
+	}
+	return language, err
+}
+
+// detectWordList returns the first wordlist that decodes the words with a valid checksum, and the entropy
+func detectWordList(words []string) (Language, *wordList, []byte, error) {
+	for _, language := range languageOrder {
+		wl, err := getWordList(language)
+		if err != nil {
+			continue
+		}
+		if entropy, err := mnemonicToEntropy(words, wl); err == nil {
+			return language, wl, entropy, nil
+		}
+	}
+	return "", nil, nil, errors.New("could not find a wordlist matching the mnemonic")
+}
+
+// mnemonicToEntropy decodes the words, and validates the checksum
+func mnemonicToEntropy(words []string, wl *wordList) ([]byte, error) {
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, mnemonicLengthError(len(words))
+	}
+	b := new(big.Int)
+	for _, w := range words {
+		i, ok := wl.index[norm.NFKD.String(w)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownWord, w)
+		}
+		b.Lsh(b, 11)
+		b.Or(b, big.NewInt(int64(i)))
+	}
+	checksumBits := uint(len(words) * 11 / 33)
+	checksum := new(big.Int).And(b, big.NewInt(1<<checksumBits-1))
+	b.Rsh(b, checksumBits)
+	entropy := make([]byte, len(words)*11*32/33/8)
+	raw := b.Bytes()
+	copy(entropy[len(entropy)-len(raw):], raw)
+	h := sha256.Sum256(entropy)
+	if uint64(h[0]>>(8-checksumBits)) != checksum.Uint64() {
+		return nil, ErrInvalidChecksum
+	}
+	return entropy, nil
+}
+
+// entropyToMnemonic encodes entropy (with checksum) as words from the list
+func entropyToMnemonic(entropy []byte, wl *wordList) ([]string, error) {
+	switch len(entropy) {
+	case 16, 20, 24, 28, 32:
+	default:
+		return nil, ErrInvalidEntropy
+	}
+	checksumBits := uint(len(entropy) / 4)
+	h := sha256.Sum256(entropy)
+	b := new(big.Int).SetBytes(entropy)
+	b.Lsh(b, checksumBits)
+	b.Or(b, big.NewInt(int64(h[0]>>(8-checksumBits))))
+	words := make([]string, (len(entropy)*8+int(checksumBits))/11)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = wl.words[new(big.Int).And(b, mask).Int64()]
+		b.Rsh(b, 11)
+	}
+	return words, nil
+}
+
+// mnemonicSeed derives the BIP39 seed, both the phrase and passphrase are NFKD normalized
+func mnemonicSeed(words []string, passphrase string) []byte {
+	phrase := norm.NFKD.String(strings.Join(words, " "))
+	return pbkdf2.Key([]byte(phrase), []byte(norm.NFKD.String("mnemonic"+passphrase)), 2048, 64, sha512.New)
+}
+
+// joinWords uses an ideographic space for Japanese phrases as recommended by BIP39
+func joinWords(words []string, language Language) string {
+	if language == LangJapanese {
+		return strings.Join(words, "　")
+	}
+	return strings.Join(words, " ")
+}
+
+// WordComplete provides the English BIP39 words beginning with prefix, for implementing type-ahead entry
+func WordComplete(prefix string) []string {
+	words, _ := WordCompleteInLanguage(prefix, LangEnglish)
+	return words
+}
+
+// WordCompleteInLanguage provides the words in a language's list beginning with prefix, in wordlist order
+func WordCompleteInLanguage(prefix string, language Language) ([]string, error) {
+	wl, err := getWordList(language)
+	if err != nil {
+		return nil, err
+	}
+	prefix = norm.NFKD.String(strings.TrimSpace(prefix))
+	words := make([]string, 0)
+	if prefix == "" {
+		return words, nil
+	}
+	for _, w := range wl.words {
+		if strings.HasPrefix(norm.NFKD.String(w), prefix) {
+			words = append(words, w)
+		}
+	}
+	return words, nil
+}
+
+// IsWord is true if word is in the English BIP39 wordlist
+func IsWord(word string) bool {
+	return IsWordInLanguage(word, LangEnglish)
+}
+
+// IsWordInLanguage is true if word is in the wordlist for language
+func IsWordInLanguage(word string, language Language) bool {
+	wl, err := getWordList(language)
+	if err != nil {
+		return false
+	}
+	_, ok := wl.index[norm.NFKD.String(strings.TrimSpace(word))]
+	return ok
+}
@@ -0,0 +1,115 @@
+package fiox
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	lang, err := DetectLanguage("dream knife language movie cannon remove width like wedding gate help patient ocean usage system steak screen summer subway field venture")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if lang != LangEnglish {
+		t.Error("expected english, got", lang)
+	}
+	for _, l := range []Language{LangJapanese, LangKorean, LangSpanish, LangChineseSimplified, LangChineseTraditional, LangFrench, LangItalian, LangCzech} {
+		hd, err := NewRandomHdInLanguage(15, l, "")
+		if err != nil {
+			t.Error(l, err)
+			continue
+		}
+		if hd.Language() != l {
+			t.Error("random mnemonic had wrong language, expected", l, "got", hd.Language())
+		}
+		restored, err := NewHdFromString(hd.String())
+		if err != nil {
+			t.Error(l, err)
+			continue
+		}
+		if restored.Language() != l || restored.String() != hd.String() {
+			t.Error("could not restore mnemonic for", l)
+		}
+	}
+	if _, err = NewRandomHdInLanguage(12, LangPortuguese, ""); err == nil {
+		t.Error("portuguese should require a registered wordlist")
+	}
+	if _, err = DetectLanguage("not a real mnemonic phrase at all but it does have twelve words"); err == nil {
+		t.Error("detected a language for an invalid mnemonic")
+	}
+}
+
+func TestJapaneseMnemonic(t *testing.T) {
+	// from the BIP39 Japanese test vectors, the passphrase is intentionally not normalized
+	hd, err := NewHdFromStringWithPassphrase(
+		"あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら",
+		"㍍ガバヴァぱばぐゞちぢ十人十色",
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if hd.Language() != LangJapanese {
+		t.Error("expected japanese, got", hd.Language())
+	}
+	if hex.EncodeToString(hd.seed) != "a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55" {
+		t.Error("japanese seed did not match test vector")
+	}
+}
+
+func TestRegisterWordList(t *testing.T) {
+	if err := RegisterWordList("short", []string{"a", "b"}); err == nil {
+		t.Error("allowed a short wordlist")
+	}
+	dup := make([]string, 2048)
+	for i := range dup {
+		dup[i] = "same"
+	}
+	if err := RegisterWordList("dup", dup); err == nil {
+		t.Error("allowed duplicate words")
+	}
+}
+
+func TestWordComplete(t *testing.T) {
+	words := WordComplete("aba")
+	if len(words) != 1 || words[0] != "abandon" {
+		t.Error("unexpected completion", words)
+	}
+	if len(WordComplete("ab")) < 5 {
+		t.Error("expected several completions for ab")
+	}
+	if len(WordComplete("")) != 0 || len(WordComplete("xyz")) != 0 {
+		t.Error("expected no completions")
+	}
+	if _, err := WordCompleteInLanguage("a", "klingon"); err == nil {
+		t.Error("allowed an unknown language")
+	}
+	if !IsWord("zoo") || IsWord("zooo") || !IsWordInLanguage("ábaco", LangSpanish) {
+		t.Error("IsWord mismatch")
+	}
+}
+
+func TestMnemonicErrors(t *testing.T) {
+	tests := map[string]error{
+		"crater husband angle":         ErrMnemonicTooShort,
+		strings.Repeat("abandon ", 25): ErrMnemonicTooLong,
+		strings.Repeat("abandon ", 13): ErrMnemonicLength,
+		"crater  husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic":        ErrEmptyWord,
+		"crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wagon":   ErrInvalidChecksum,
+		"crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wagonnn": ErrUnknownWord,
+	}
+	for mnemonic, expected := range tests {
+		if _, err := NewHdFromString(strings.TrimSpace(mnemonic)); !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+	if _, err := NewHdFromEntropy(make([]byte, 15), LangEnglish, ""); !errors.Is(err, ErrInvalidEntropy) {
+		t.Error("expected ErrInvalidEntropy, got", err)
+	}
+	if _, err := NewRandomHdInLanguage(12, "klingon", ""); !errors.Is(err, ErrUnknownLanguage) {
+		t.Error("expected ErrUnknownLanguage, got", err)
+	}
+}
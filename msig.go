@@ -0,0 +1,261 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"strings"
+)
+
+// ErrProposalNotFound is returned when an eosio.msig proposal does not exist, it may have been executed or cancelled
+var ErrProposalNotFound = errors.New("proposal not found")
+
+// Msig proposes, approves, and executes eosio.msig proposals, paying the fees with one key of a Signer
+type Msig struct {
+	payer
+}
+
+// ProposalReview is what a proposal will do, fetched by ReviewProposal so it can be checked before approving.
+// TransactionHash is the sha256 of the packed transaction, Approve includes it so the approval only counts for the
+// transaction that was reviewed.
+type ProposalReview struct {
+	Proposer        string            `json:"proposer"`
+	ProposalName    string            `json:"proposal_name"`
+	TransactionHash string            `json:"transaction_hash"`
+	Expiration      string            `json:"expiration"`
+	Actions         []PolicyAction    `json:"actions"`
+	Requested       []PermissionLevel `json:"requested_approvals"`
+	Provided        []PermissionLevel `json:"provided_approvals"`
+}
+
+// NewMsig pays with the key pub held by signer, fees is optional and lets several helpers share a FeeCache
+func NewMsig(api *fio.API, signer Signer, pub string, fees *FeeCache) (*Msig, error) {
+	p, err := newPayer(api, signer, pub, fees)
+	if err != nil {
+		return nil, err
+	}
+	return &Msig{payer: p}, nil
+}
+
+// Msig creates a Msig for the key at index
+func (hd Hd) Msig(api *fio.API, index int) (*Msig, error) {
+	p, err := hdPayer(hd, api, index)
+	if err != nil {
+		return nil, err
+	}
+	return &Msig{payer: p}, nil
+}
+
+// Propose proposes trx, a transaction in the JSON form PackTransactionJSON accepts, asking for the approval of
+// each of requested. Its expiration is when the proposal can no longer be executed.
+func (m *Msig) Propose(ctx context.Context, proposalName string, requested []PermissionLevel, trx interface{}) (*PushResult, error) {
+	if len(requested) == 0 {
+		return nil, errors.New("at least one approval must be requested")
+	}
+	b, err := json.Marshal(trx)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := PackTransactionJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := m.fees.FeeFor(ctx, "msig_propose", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, string(m.actor), proposalName); err != nil {
+		return nil, err
+	}
+	writeVarUint(buf, uint64(len(requested)))
+	for _, p := range requested {
+		if err = writeAbiNames(buf, p.Actor, p.Permission); err != nil {
+			return nil, err
+		}
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	buf.Write(packed)
+	return m.push(ctx, "propose", buf.Bytes())
+}
+
+// Approve approves a reviewed proposal with level, which must be one of the requested approvals and held by this
+// Msig's key
+func (m *Msig) Approve(ctx context.Context, review *ProposalReview, level PermissionLevel) (*PushResult, error) {
+	if review == nil {
+		return nil, errors.New("review the proposal with ReviewProposal first")
+	}
+	hash, err := hex.DecodeString(review.TransactionHash)
+	if err != nil || len(hash) != 32 {
+		return nil, errors.New("invalid transaction hash")
+	}
+	fee, err := m.fees.FeeFor(ctx, "msig_approve", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, review.Proposer, review.ProposalName, level.Actor, level.Permission); err != nil {
+		return nil, err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	buf.Write(hash)
+	return m.push(ctx, "approve", buf.Bytes())
+}
+
+// Unapprove withdraws an approval
+func (m *Msig) Unapprove(ctx context.Context, proposer string, proposalName string, level PermissionLevel) (*PushResult, error) {
+	fee, err := m.fees.FeeFor(ctx, "msig_unapprove", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, proposer, proposalName, level.Actor, level.Permission); err != nil {
+		return nil, err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	return m.push(ctx, "unapprove", buf.Bytes())
+}
+
+// Cancel cancels a proposal made by this Msig's account, others can only cancel it after it expires
+func (m *Msig) Cancel(ctx context.Context, proposer string, proposalName string) (*PushResult, error) {
+	fee, err := m.fees.FeeFor(ctx, "msig_cancel", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, proposer, proposalName, string(m.actor)); err != nil {
+		return nil, err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	return m.push(ctx, "cancel", buf.Bytes())
+}
+
+// Exec executes a proposal once it has enough approvals
+func (m *Msig) Exec(ctx context.Context, proposer string, proposalName string) (*PushResult, error) {
+	fee, err := m.fees.FeeFor(ctx, "msig_exec", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, proposer, proposalName); err != nil {
+		return nil, err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	if err = writeAbiName(buf, string(m.actor)); err != nil {
+		return nil, err
+	}
+	return m.push(ctx, "exec", buf.Bytes())
+}
+
+func (m *Msig) push(ctx context.Context, action string, data []byte) (*PushResult, error) {
+	return sendActions(ctx, m.api, m.signer, m.pub, newAction("eosio.msig", action, m.actor, data))
+}
+
+// ReviewProposal fetches a pending proposal and decodes its transaction, print it to see what approving it means
+func ReviewProposal(ctx context.Context, api *fio.API, proposer string, proposalName string) (*ProposalReview, error) {
+	proposals := struct {
+		Rows []struct {
+			ProposalName      string `json:"proposal_name"`
+			PackedTransaction string `json:"packed_transaction"`
+		} `json:"rows"`
+	}{}
+	if err := msigTableRow(ctx, api, proposer, "proposal", proposalName, &proposals); err != nil {
+		return nil, err
+	}
+	if len(proposals.Rows) == 0 || proposals.Rows[0].ProposalName != proposalName {
+		return nil, ErrProposalNotFound
+	}
+	packed, err := hex.DecodeString(proposals.Rows[0].PackedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	trx, err := unpackTransaction(packed)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode the proposed transaction: %w", err)
+	}
+	hash := sha256.Sum256(packed)
+	review := &ProposalReview{
+		Proposer:        proposer,
+		ProposalName:    proposalName,
+		TransactionHash: hex.EncodeToString(hash[:]),
+		Expiration:      trx.Expiration,
+	}
+	if review.Actions, err = policyActions(trx); err != nil {
+		return nil, err
+	}
+
+	approvals := struct {
+		Rows []struct {
+			ProposalName string `json:"proposal_name"`
+			Requested    []struct {
+				Level PermissionLevel `json:"level"`
+			} `json:"requested_approvals"`
+			Provided []struct {
+				Level PermissionLevel `json:"level"`
+			} `json:"provided_approvals"`
+		} `json:"rows"`
+	}{}
+	if err = msigTableRow(ctx, api, proposer, "approvals2", proposalName, &approvals); err != nil {
+		return nil, err
+	}
+	if len(approvals.Rows) > 0 && approvals.Rows[0].ProposalName == proposalName {
+		for _, a := range approvals.Rows[0].Requested {
+			review.Requested = append(review.Requested, a.Level)
+		}
+		for _, a := range approvals.Rows[0].Provided {
+			review.Provided = append(review.Provided, a.Level)
+		}
+	}
+	return review, nil
+}
+
+// String summarizes the proposal for a person deciding whether to approve it
+func (pr ProposalReview) String() string {
+	s := &strings.Builder{}
+	fmt.Fprintf(s, "Proposal %s by %s, expires %s\nTransaction hash %s\n", pr.ProposalName, pr.Proposer, pr.Expiration, pr.TransactionHash)
+	fmt.Fprintf(s, "Actions:\n")
+	for _, a := range pr.Actions {
+		fmt.Fprintf(s, "  %s::%s authorized by %s", a.Contract, a.Action, strings.Join(a.Actors, ", "))
+		if a.Payee != "" {
+			fmt.Fprintf(s, ", pays %s up to %s", a.Payee, FioAmount(a.Amount))
+		}
+		s.WriteString("\n")
+	}
+	fmt.Fprintf(s, "Approvals: %d provided, %d still requested\n", len(pr.Provided), len(pr.Requested))
+	for _, p := range pr.Provided {
+		fmt.Fprintf(s, "  [x] %s\n", p)
+	}
+	for _, p := range pr.Requested {
+		fmt.Fprintf(s, "  [ ] %s\n", p)
+	}
+	return s.String()
+}
+
+func msigTableRow(ctx context.Context, api *fio.API, proposer string, table string, proposalName string, result interface{}) error {
+	_, err := chainPost(ctx, api, "/v1/chain/get_table_rows", map[string]interface{}{
+		"code":        "eosio.msig",
+		"scope":       proposer,
+		"table":       table,
+		"lower_bound": proposalName,
+		"upper_bound": proposalName,
+		"limit":       1,
+		"json":        true,
+	}, result)
+	return err
+}
+
+// writeAbiNames writes several names, see writeAbiName
+func writeAbiNames(buf *bytes.Buffer, names ...string) error {
+	for _, n := range names {
+		if err := writeAbiName(buf, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
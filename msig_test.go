@@ -0,0 +1,98 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMsig(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	nodeos := newTestNodeos(nil)
+	defer nodeos.Close()
+	m, err := hd.Msig(nodeos.api(), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	payee := "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"
+	requested := []PermissionLevel{{Actor: "bob", Permission: "active"}, {Actor: "carol", Permission: "active"}}
+	if _, err = m.Propose(ctx, "payroll", requested, transferTx(payee, 1_000_000_000)); err != nil {
+		t.Error(err)
+		return
+	}
+	data := nodeos.checkPushed(t, 0, m.pub)
+	expect := &bytes.Buffer{}
+	_ = writeAbiNames(expect, string(m.actor), "payroll")
+	writeVarUint(expect, 2)
+	_ = writeAbiNames(expect, "bob", "active", "carol", "active")
+	_ = binary.Write(expect, binary.LittleEndian, uint64(40_000_000_000))
+	if !bytes.HasPrefix(data[0], expect.Bytes()) {
+		t.Errorf("unexpected propose data %x", data[0])
+		return
+	}
+	packed := data[0][expect.Len():]
+
+	if _, err = ReviewProposal(ctx, nodeos.api(), string(m.actor), "payroll"); !errors.Is(err, ErrProposalNotFound) {
+		t.Error("expected a missing proposal", err)
+	}
+	// both tables are answered with the same row, each read ignores the other's fields
+	nodeos.responses["/v1/chain/get_table_rows"] = map[string]interface{}{"rows": []map[string]interface{}{{
+		"proposal_name":       "payroll",
+		"packed_transaction":  hex.EncodeToString(packed),
+		"requested_approvals": []map[string]interface{}{{"level": requested[1], "time": "1970-01-01T00:00:00.000"}},
+		"provided_approvals":  []map[string]interface{}{{"level": requested[0], "time": "2020-06-30T00:00:00.000"}},
+	}}}
+	review, err := ReviewProposal(ctx, nodeos.api(), string(m.actor), "payroll")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(review.Actions) != 1 || review.Actions[0].Payee != payee || review.Actions[0].Amount != 3_000_000_000 {
+		t.Error("unexpected actions", review.Actions)
+	}
+	if len(review.Provided) != 1 || len(review.Requested) != 1 || review.Requested[0].String() != "carol@active" {
+		t.Error("unexpected approvals", review.Provided, review.Requested)
+	}
+	summary := review.String()
+	for _, s := range []string{"fio.token::trnsfiopubky authorized by aftyershcu22", "[x] bob@active", "[ ] carol@active", "2020-07-01T00:00:00"} {
+		if !strings.Contains(summary, s) {
+			t.Errorf("summary is missing %q:\n%s", s, summary)
+		}
+	}
+
+	if _, err = m.Approve(ctx, review, requested[1]); err != nil {
+		t.Error(err)
+		return
+	}
+	data = nodeos.checkPushed(t, 1, m.pub)
+	hash, _ := hex.DecodeString(review.TransactionHash)
+	if !bytes.HasSuffix(data[0], hash) || len(data[0]) != 4*8+8+32 {
+		t.Errorf("unexpected approve data %x", data[0])
+	}
+	if _, err = m.Approve(ctx, nil, requested[1]); err == nil {
+		t.Error("expected approving without a review to fail")
+	}
+
+	if _, err = m.Exec(ctx, string(m.actor), "payroll"); err != nil {
+		t.Error(err)
+		return
+	}
+	data = nodeos.checkPushed(t, 2, m.pub)
+	expect.Reset()
+	_ = writeAbiNames(expect, string(m.actor), "payroll")
+	_ = binary.Write(expect, binary.LittleEndian, uint64(40_000_000_000))
+	_ = writeAbiName(expect, string(m.actor))
+	if !bytes.Equal(data[0], expect.Bytes()) {
+		t.Errorf("unexpected exec data %x", data[0])
+	}
+}
@@ -0,0 +1,69 @@
+package fiox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// length limits enforced by the fio.address contract
+const (
+	MaxFioDomainLength  = 62
+	MinFioAddressLength = 3
+	MaxFioAddressLength = 64
+)
+
+// ErrInvalidFioName is returned for a FIO address or domain the fio.address contract would reject
+var ErrInvalidFioName = errors.New("invalid fio name")
+
+// IsValidFioAddress checks a FIO address such as "alice@fiotestnet" using the rules of the fio.address contract
+func IsValidFioAddress(fioAddress string) bool {
+	_, _, err := SplitAddress(fioAddress)
+	return err == nil
+}
+
+// IsValidFioDomain checks a FIO domain using the rules of the fio.address contract
+func IsValidFioDomain(domain string) bool {
+	return validFioLabel(domain, MaxFioDomainLength) == nil
+}
+
+// SplitAddress splits a FIO address into its name and domain, returning ErrInvalidFioName with the reason when it
+// is not valid. The whole address is 3 to 64 characters, and each part uses only letters, digits, and hyphens,
+// without a hyphen at the start or end or two in a row. FIO names are not case sensitive, the parts are returned in
+// lower case.
+func SplitAddress(fioAddress string) (name string, domain string, err error) {
+	if len(fioAddress) < MinFioAddressLength || len(fioAddress) > MaxFioAddressLength {
+		return "", "", fmt.Errorf("%w: a fio address must be %d to %d characters", ErrInvalidFioName, MinFioAddressLength, MaxFioAddressLength)
+	}
+	parts := strings.Split(fioAddress, "@")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("%w: a fio address must have one @", ErrInvalidFioName)
+	}
+	for _, part := range parts {
+		if err = validFioLabel(part, MaxFioAddressLength); err != nil {
+			return "", "", err
+		}
+	}
+	return strings.ToLower(parts[0]), strings.ToLower(parts[1]), nil
+}
+
+func validFioLabel(label string, max int) error {
+	if len(label) < 1 || len(label) > max {
+		return fmt.Errorf("%w: %q must be 1 to %d characters", ErrInvalidFioName, label, max)
+	}
+	for i, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-':
+			if i == 0 || i == len(label)-1 {
+				return fmt.Errorf("%w: %q cannot start or end with a hyphen", ErrInvalidFioName, label)
+			}
+			if label[i-1] == '-' {
+				return fmt.Errorf("%w: %q cannot have consecutive hyphens", ErrInvalidFioName, label)
+			}
+		default:
+			return fmt.Errorf("%w: %q can only hold letters, digits, and hyphens", ErrInvalidFioName, label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package fiox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsValidFioAddress(t *testing.T) {
+	for address, valid := range map[string]bool{
+		"alice@fiotestnet":                 true,
+		"a@b":                              true,
+		"Alice-1@Fio-Testnet":              true,
+		"alice@fiotestnet@fio":             false,
+		"alice":                            false,
+		"@fiotestnet":                      false,
+		"alice@":                           false,
+		"-alice@fiotestnet":                false,
+		"alice-@fiotestnet":                false,
+		"al--ice@fiotestnet":               false,
+		"alice@fio-":                       false,
+		"al_ice@fiotestnet":                false,
+		"alice.bob@fiotestnet":             false,
+		"alicé@fiotestnet":                 false,
+		"alice:fiotestnet":                 false,
+		strings.Repeat("a", 60) + "@fio":   true,
+		strings.Repeat("a", 61) + "@fio":   false,
+		"alice@" + strings.Repeat("b", 58): true,
+		"alice@" + strings.Repeat("b", 59): false,
+	} {
+		if IsValidFioAddress(address) != valid {
+			t.Errorf("expected %q valid to be %v", address, valid)
+		}
+	}
+}
+
+func TestIsValidFioDomain(t *testing.T) {
+	for domain, valid := range map[string]bool{
+		"fiotestnet":            true,
+		"a":                     true,
+		"fio-testnet":           true,
+		"":                      false,
+		"-fio":                  false,
+		"fio-":                  false,
+		"fio--testnet":          false,
+		"fio@testnet":           false,
+		strings.Repeat("d", 62): true,
+		strings.Repeat("d", 63): false,
+	} {
+		if IsValidFioDomain(domain) != valid {
+			t.Errorf("expected %q valid to be %v", domain, valid)
+		}
+	}
+}
+
+func TestSplitAddress(t *testing.T) {
+	name, domain, err := SplitAddress("Alice@FioTestnet")
+	if err != nil || name != "alice" || domain != "fiotestnet" {
+		t.Error("unexpected split", name, domain, err)
+	}
+	if _, _, err = SplitAddress("alice--@fiotestnet"); !errors.Is(err, ErrInvalidFioName) {
+		t.Error("expected ErrInvalidFioName, got", err)
+	}
+}
@@ -0,0 +1,117 @@
+package fiox
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+	"strconv"
+)
+
+// obtPageSize is how many records are fetched per call to get_obt_data
+const obtPageSize = 100
+
+// PaymentRecord is an OBT record (record_obt_data) to or from a key, with its content decrypted. If the content
+// could not be decrypted Content is nil and DecryptError says why. FioRequestID is zero when the payment did not
+// answer a FIO Request.
+type PaymentRecord struct {
+	FioRequestID      uint64      `json:"fio_request_id"`
+	PayerFioAddress   string      `json:"payer_fio_address"`
+	PayeeFioAddress   string      `json:"payee_fio_address"`
+	PayerFioPublicKey string      `json:"payer_fio_public_key"`
+	PayeeFioPublicKey string      `json:"payee_fio_public_key"`
+	Status            string      `json:"status"`
+	TimeStamp         string      `json:"time_stamp"`
+	Content           *ObtContent `json:"content,omitempty"`
+	DecryptError      string      `json:"decrypt_error,omitempty"`
+}
+
+// PaymentHistory pages through get_obt_data for the key priv and decrypts each record
+func PaymentHistory(ctx context.Context, api *fio.API, priv *ecc.PrivateKey) ([]PaymentRecord, error) {
+	if api == nil || priv == nil {
+		return nil, errors.New("api and key are required")
+	}
+	pub := priv.PublicKey()
+	records := make([]PaymentRecord, 0)
+	for {
+		page := struct {
+			Records []struct {
+				PaymentRecord
+				Content string `json:"content"`
+			} `json:"obt_data_records"`
+			More int `json:"more"`
+		}{}
+		found, err := chainPost(ctx, api, "/v1/chain/get_obt_data", map[string]interface{}{
+			"fio_public_key": pub.String(),
+			"limit":          obtPageSize,
+			"offset":         len(records),
+		}, &page)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return records, nil
+		}
+		for _, row := range page.Records {
+			record := row.PaymentRecord
+			// the content is encrypted with the other party's key
+			counterparty := record.PayerFioPublicKey
+			if samePublicKeyString(counterparty, pub) {
+				counterparty = record.PayeeFioPublicKey
+			}
+			other, err := ecc.NewPublicKey(counterparty)
+			if err == nil {
+				record.Content, err = DecryptObtContent(priv, &other, row.Content)
+			}
+			if err != nil {
+				record.DecryptError = err.Error()
+			}
+			records = append(records, record)
+		}
+		if page.More == 0 || len(page.Records) == 0 {
+			return records, nil
+		}
+	}
+}
+
+// PaymentHistory provides the OBT records of the key at index, see PaymentHistory
+func (hd Hd) PaymentHistory(ctx context.Context, api *fio.API, index int) ([]PaymentRecord, error) {
+	priv, err := hd.keyAt(index)
+	if err != nil {
+		return nil, err
+	}
+	return PaymentHistory(ctx, api, priv)
+}
+
+// WritePaymentsCSV writes payment records as CSV for accounting software, one row per record. Records that could
+// not be decrypted have empty payment columns.
+func WritePaymentsCSV(w io.Writer, records []PaymentRecord) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"time_stamp", "payer_fio_address", "payee_fio_address", "amount", "chain_code", "token_code",
+		"obt_id", "status", "memo", "fio_request_id"})
+	for _, r := range records {
+		c := r.Content
+		if c == nil {
+			c = &ObtContent{}
+		}
+		requestID := ""
+		if r.FioRequestID != 0 {
+			requestID = strconv.FormatUint(r.FioRequestID, 10)
+		}
+		err := cw.Write([]string{r.TimeStamp, r.PayerFioAddress, r.PayeeFioAddress, c.Amount, c.ChainCode, c.TokenCode,
+			c.ObtId, r.Status, c.Memo, requestID})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// samePublicKeyString compares a public key string with pub, ignoring the prefix
+func samePublicKeyString(s string, pub ecc.PublicKey) bool {
+	key, err := ecc.NewPublicKey(s)
+	return err == nil && samePublicKey(key, pub)
+}
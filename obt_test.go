@@ -0,0 +1,83 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPaymentHistory(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	me, _ := hd.keyAt(0)
+	other, _ := hd.keyAt(1)
+	mePub, otherPub := me.PublicKey(), other.PublicKey()
+	content := &ObtContent{PayerPublicAddress: "0xabc", PayeePublicAddress: "0xdef", Amount: "1.5", ChainCode: "ETH",
+		TokenCode: "ETH", Status: "sent_to_blockchain", ObtId: "0x123", Memo: "rent, march"}
+	paid, _ := EncryptObtContent(me, &otherPub, content)
+	received, _ := EncryptObtContent(other, &mePub, content)
+
+	record := func(id int, payer string, payee string, content string) map[string]interface{} {
+		return map[string]interface{}{
+			"fio_request_id": id, "payer_fio_address": "payer@fiotestnet", "payee_fio_address": "payee@fiotestnet",
+			"payer_fio_public_key": payer, "payee_fio_public_key": payee, "content": content,
+			"status": "sent_to_blockchain", "time_stamp": "2021-03-01T00:00:00",
+		}
+	}
+	records := []interface{}{
+		record(9, mePub.String(), otherPub.String(), paid),
+		record(0, otherPub.String(), mePub.String(), received),
+		record(0, otherPub.String(), mePub.String(), paid[:20]),
+	}
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Offset int `json:"offset"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Offset == 0 {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"obt_data_records": records[:1], "more": 2})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"obt_data_records": records[req.Offset:], "more": 0})
+	}))
+	defer nodeos.Close()
+
+	history, err := hd.PaymentHistory(context.Background(), &fio.API{API: eos.API{BaseURL: nodeos.URL}}, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(history) != 3 {
+		t.Fatal("expected three records, got", len(history))
+	}
+	for _, r := range history[:2] {
+		if r.Content == nil || r.Content.ObtId != "0x123" {
+			t.Error("content was not decrypted", r)
+		}
+	}
+	if history[2].Content != nil || history[2].DecryptError == "" {
+		t.Error("expected a decrypt error", history[2])
+	}
+
+	buf := &bytes.Buffer{}
+	if err = WritePaymentsCSV(buf, history); err != nil {
+		t.Error(err)
+		return
+	}
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil || len(rows) != 4 {
+		t.Fatal("unexpected csv", rows, err)
+	}
+	if rows[1][3] != "1.5" || rows[1][8] != "rent, march" || rows[1][9] != "9" || rows[2][9] != "" || rows[3][3] != "" {
+		t.Error("unexpected csv rows", rows)
+	}
+}
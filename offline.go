@@ -0,0 +1,305 @@
+package fiox
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"strings"
+	"time"
+)
+
+// chain IDs for the public FIO networks
+const (
+	FioMainnetChainID = "21dcae42c0182200e93f954a074011f9048a7624c6fe81d3c9541a614a88bd1c"
+	FioTestnetChainID = "b20901380af44ef59c5918439a1f9a41d83669020319a80574b804a5f95cbd7e"
+)
+
+// OfflineSigner signs transactions without any network access, for air-gapped machines. The transaction must
+// already be complete (TAPOS fields and serialized action data), usually built on an online machine.
+type OfflineSigner struct {
+	chainID []byte
+	keys    []*ecc.PrivateKey
+}
+
+// SignedPackedTransaction is the signed result, it is the body expected by /v1/chain/push_transaction
+type SignedPackedTransaction struct {
+	Signatures            []string `json:"signatures"`
+	Compression           string   `json:"compression"`
+	PackedContextFreeData string   `json:"packed_context_free_data"`
+	PackedTrx             string   `json:"packed_trx"`
+}
+
+// NewOfflineSigner creates a signer for a chain ID (hex) using one or more keys, every key signs each transaction
+func NewOfflineSigner(chainID string, keys ...*ecc.PrivateKey) (*OfflineSigner, error) {
+	id, err := hex.DecodeString(chainID)
+	if err != nil || len(id) != 32 {
+		return nil, errors.New("chain ID must be 32 bytes of hex")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+	for _, k := range keys {
+		if k == nil {
+			return nil, errors.New("key cannot be nil")
+		}
+	}
+	return &OfflineSigner{chainID: id, keys: keys}, nil
+}
+
+// OfflineSigner creates an OfflineSigner using the keys at the provided indexes
+func (hd Hd) OfflineSigner(chainID string, indexes ...int) (*OfflineSigner, error) {
+	if len(indexes) == 0 {
+		return nil, errors.New("at least one index is required")
+	}
+	keys := make([]*ecc.PrivateKey, len(indexes))
+	for i, index := range indexes {
+		key, err := hd.keyAt(index)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return NewOfflineSigner(chainID, keys...)
+}
+
+// SignPacked signs a binary serialized (uncompressed) transaction
+func (s OfflineSigner) SignPacked(packedTrx []byte) (*SignedPackedTransaction, error) {
+	if len(packedTrx) == 0 {
+		return nil, errors.New("transaction is empty")
+	}
+	digest := TransactionDigest(s.chainID, packedTrx, nil)
+	signed := &SignedPackedTransaction{
+		Signatures:  make([]string, len(s.keys)),
+		Compression: "none",
+		PackedTrx:   hex.EncodeToString(packedTrx),
+	}
+	for i, k := range s.keys {
+		sig, err := k.Sign(digest)
+		if err != nil {
+			return nil, err
+		}
+		signed.Signatures[i] = sig.String()
+	}
+	return signed, nil
+}
+
+// SignJSON signs a JSON transaction, action data must already be serialized as hex in "hex_data" or "data"
+func (s OfflineSigner) SignJSON(trx []byte) (*SignedPackedTransaction, error) {
+	packed, err := PackTransactionJSON(trx)
+	if err != nil {
+		return nil, err
+	}
+	return s.SignPacked(packed)
+}
+
+// TransactionDigest is the hash signed for a transaction: sha256(chain ID || packed transaction || sha256(context
+// free data)), where an empty context free data hash is 32 zero bytes.
+func TransactionDigest(chainID []byte, packedTrx []byte, contextFreeData []byte) []byte {
+	cfd := make([]byte, 32)
+	if len(contextFreeData) > 0 {
+		h := sha256.Sum256(contextFreeData)
+		cfd = h[:]
+	}
+	h := sha256.New()
+	_, _ = h.Write(chainID)
+	_, _ = h.Write(packedTrx)
+	_, _ = h.Write(cfd)
+	return h.Sum(nil)
+}
+
+// PermissionLevel is an actor and permission, such as an action authorization or an approval requested for a
+// multisig proposal
+type PermissionLevel struct {
+	Actor      string `json:"actor"`
+	Permission string `json:"permission"`
+}
+
+func (p PermissionLevel) String() string {
+	return p.Actor + "@" + p.Permission
+}
+
+type jsonAction struct {
+	Account       string            `json:"account"`
+	Name          string            `json:"name"`
+	Authorization []PermissionLevel `json:"authorization"`
+	Data          json.RawMessage   `json:"data"`
+	HexData       string            `json:"hex_data"`
+}
+
+type jsonTransaction struct {
+	Expiration         string       `json:"expiration"`
+	RefBlockNum        uint16       `json:"ref_block_num"`
+	RefBlockPrefix     uint32       `json:"ref_block_prefix"`
+	MaxNetUsageWords   uint32       `json:"max_net_usage_words"`
+	MaxCpuUsageMs      uint8        `json:"max_cpu_usage_ms"`
+	DelaySec           uint32       `json:"delay_sec"`
+	ContextFreeActions []jsonAction `json:"context_free_actions"`
+	Actions            []jsonAction `json:"actions"`
+}
+
+// PackTransactionJSON serializes a JSON transaction to the binary format that is signed. No ABI is available
+// offline, so each action's data must already be serialized as hex. Transaction extensions are not supported.
+func PackTransactionJSON(trx []byte) ([]byte, error) {
+	t := jsonTransaction{}
+	if err := json.Unmarshal(trx, &t); err != nil {
+		return nil, err
+	}
+	expiration, err := parseExpiration(t.Expiration)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Actions) == 0 {
+		return nil, errors.New("transaction has no actions")
+	}
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, uint32(expiration.Unix()))
+	_ = binary.Write(buf, binary.LittleEndian, t.RefBlockNum)
+	_ = binary.Write(buf, binary.LittleEndian, t.RefBlockPrefix)
+	writeVarUint(buf, uint64(t.MaxNetUsageWords))
+	buf.WriteByte(t.MaxCpuUsageMs)
+	writeVarUint(buf, uint64(t.DelaySec))
+	for _, actions := range [][]jsonAction{t.ContextFreeActions, t.Actions} {
+		writeVarUint(buf, uint64(len(actions)))
+		for i, a := range actions {
+			if err = packAction(buf, a); err != nil {
+				return nil, fmt.Errorf("action %d: %w", i, err)
+			}
+		}
+	}
+	// transaction_extensions
+	writeVarUint(buf, 0)
+	return buf.Bytes(), nil
+}
+
+func packAction(buf *bytes.Buffer, a jsonAction) error {
+	names := []string{a.Account, a.Name}
+	for _, p := range a.Authorization {
+		names = append(names, p.Actor, p.Permission)
+	}
+	encoded := make([]uint64, len(names))
+	for i, n := range names {
+		v, err := eos.StringToName(n)
+		if err != nil {
+			return err
+		}
+		encoded[i] = v
+	}
+	_ = binary.Write(buf, binary.LittleEndian, encoded[:2])
+	writeVarUint(buf, uint64(len(a.Authorization)))
+	_ = binary.Write(buf, binary.LittleEndian, encoded[2:])
+
+	hexData := a.HexData
+	if hexData == "" && len(a.Data) > 0 {
+		if err := json.Unmarshal(a.Data, &hexData); err != nil {
+			return errors.New("data must be serialized as hex, use hex_data when data is an object")
+		}
+	}
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return err
+	}
+	writeVarUint(buf, uint64(len(data)))
+	buf.Write(data)
+	return nil
+}
+
+// unpackTransaction reverses PackTransactionJSON, action data is left serialized in HexData
+func unpackTransaction(packed []byte) (*jsonTransaction, error) {
+	r := bytes.NewReader(packed)
+	header := struct {
+		Expiration     uint32
+		RefBlockNum    uint16
+		RefBlockPrefix uint32
+	}{}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	t := &jsonTransaction{
+		Expiration:     time.Unix(int64(header.Expiration), 0).UTC().Format("2006-01-02T15:04:05"),
+		RefBlockNum:    header.RefBlockNum,
+		RefBlockPrefix: header.RefBlockPrefix,
+	}
+	netWords, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if t.MaxCpuUsageMs, err = r.ReadByte(); err != nil {
+		return nil, err
+	}
+	delay, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	t.MaxNetUsageWords, t.DelaySec = uint32(netWords), uint32(delay)
+	for _, actions := range []*[]jsonAction{&t.ContextFreeActions, &t.Actions} {
+		count, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if count > uint64(r.Len()) {
+			return nil, errors.New("invalid action count")
+		}
+		*actions = make([]jsonAction, count)
+		for i := range *actions {
+			if (*actions)[i], err = unpackAction(r); err != nil {
+				return nil, fmt.Errorf("action %d: %w", i, err)
+			}
+		}
+	}
+	// transaction_extensions are not supported, as with PackTransactionJSON
+	if extensions, err := binary.ReadUvarint(r); err != nil || extensions != 0 || r.Len() != 0 {
+		return nil, errors.New("unexpected data after the actions")
+	}
+	return t, nil
+}
+
+func unpackAction(r *bytes.Reader) (jsonAction, error) {
+	a := jsonAction{}
+	names := make([]uint64, 2)
+	if err := binary.Read(r, binary.LittleEndian, names); err != nil {
+		return a, err
+	}
+	a.Account, a.Name = eos.NameToString(names[0]), eos.NameToString(names[1])
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return a, err
+	}
+	if count > uint64(r.Len()/16) {
+		return a, errors.New("invalid authorization count")
+	}
+	auths := make([]uint64, count*2)
+	if err = binary.Read(r, binary.LittleEndian, auths); err != nil {
+		return a, err
+	}
+	a.Authorization = make([]PermissionLevel, count)
+	for i := range a.Authorization {
+		a.Authorization[i] = PermissionLevel{Actor: eos.NameToString(auths[2*i]), Permission: eos.NameToString(auths[2*i+1])}
+	}
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return a, err
+	}
+	if l > uint64(r.Len()) {
+		return a, errors.New("invalid action data length")
+	}
+	data := make([]byte, l)
+	_, _ = r.Read(data)
+	a.HexData = hex.EncodeToString(data)
+	return a, nil
+}
+
+func parseExpiration(s string) (time.Time, error) {
+	s = strings.TrimSuffix(s, "Z")
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04:05.000"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid expiration %q", s)
+}
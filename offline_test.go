@@ -0,0 +1,76 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+func TestOfflineSigner(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	trx := []byte(`{
+		"expiration": "2020-10-01T00:00:00",
+		"ref_block_num": 1234,
+		"ref_block_prefix": 5678,
+		"max_net_usage_words": 0,
+		"max_cpu_usage_ms": 0,
+		"delay_sec": 0,
+		"context_free_actions": [],
+		"actions": [{
+			"account": "eosio",
+			"name": "regproducer",
+			"authorization": [{"actor": "eosio", "permission": "active"}],
+			"data": "0102ff"
+		}],
+		"transaction_extensions": []
+	}`)
+	packed, err := PackTransactionJSON(trx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	eosio, _ := hex.DecodeString("0000000000ea3055")
+	if !bytes.Equal(packed[15:23], eosio) {
+		t.Errorf("account was not encoded correctly: %x", packed)
+	}
+	if !bytes.HasSuffix(packed, []byte{3, 1, 2, 0xff, 0}) {
+		t.Errorf("action data was not encoded correctly: %x", packed)
+	}
+
+	signer, err := hd.OfflineSigner(FioTestnetChainID, 0, 1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	signed, err := signer.SignJSON(trx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if signed.PackedTrx != hex.EncodeToString(packed) || len(signed.Signatures) != 2 {
+		t.Error("unexpected signed transaction", signed)
+		return
+	}
+	chainID, _ := hex.DecodeString(FioTestnetChainID)
+	digest := TransactionDigest(chainID, packed, nil)
+	for i, s := range signed.Signatures {
+		sig, err := ecc.NewSignature(s)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		pub, _ := hd.PubKeyAt(i)
+		if !sig.Verify(digest, *pub) {
+			t.Error("signature did not verify for key", i)
+		}
+	}
+
+	if _, err = PackTransactionJSON([]byte(`{"expiration":"2020-10-01T00:00:00","actions":[{"account":"eosio","name":"x","data":{"a":1}}]}`)); err == nil {
+		t.Error("expected an error for unserialized action data")
+	}
+}
@@ -0,0 +1,328 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"sort"
+	"strings"
+)
+
+// Authority is what satisfies a permission, the weights of the keys, accounts, and waits that are satisfied must add
+// up to the threshold
+type Authority struct {
+	Threshold int                     `json:"threshold"`
+	Keys      []KeyWeight             `json:"keys"`
+	Accounts  []PermissionLevelWeight `json:"accounts"`
+	Waits     []WaitWeight            `json:"waits"`
+}
+
+// KeyWeight is a key in an Authority
+type KeyWeight struct {
+	Key    string `json:"key"`
+	Weight int    `json:"weight"`
+}
+
+// PermissionLevelWeight is another account's permission in an Authority
+type PermissionLevelWeight struct {
+	Permission PermissionLevel `json:"permission"`
+	Weight     int             `json:"weight"`
+}
+
+// WaitWeight is a delay in an Authority
+type WaitWeight struct {
+	WaitSec uint32 `json:"wait_sec"`
+	Weight  int    `json:"weight"`
+}
+
+// KeyAuthority is an N-of-M authority where each of pubs has a weight of one, KeyAuthority(1, pub) is the usual
+// single key permission
+func KeyAuthority(threshold int, pubs ...string) (Authority, error) {
+	auth := Authority{Threshold: threshold, Keys: make([]KeyWeight, len(pubs))}
+	for i := range pubs {
+		auth.Keys[i] = KeyWeight{Key: pubs[i], Weight: 1}
+	}
+	return auth.normalize()
+}
+
+// MultisigAuthority is a threshold-of-count authority using the keys starting at index start, with the keys
+// derived from a single mnemonic it is only useful when each key is held by a different device or person
+func (hd Hd) MultisigAuthority(threshold int, start int, count int) (Authority, error) {
+	pubs, err := hd.PubKeysRange(start, count)
+	if err != nil {
+		return Authority{}, err
+	}
+	keys := make([]string, len(pubs))
+	for i := range pubs {
+		keys[i] = pubs[i].String()
+	}
+	return KeyAuthority(threshold, keys...)
+}
+
+// normalize checks that the threshold can be reached and sorts the authority the way the system contract requires
+func (a Authority) normalize() (Authority, error) {
+	if a.Threshold < 1 {
+		return a, errors.New("threshold must be at least one")
+	}
+	keys := make([]ecc.PublicKey, len(a.Keys))
+	total := 0
+	for i, k := range a.Keys {
+		key, err := ecc.NewPublicKey(k.Key)
+		if err != nil {
+			return a, fmt.Errorf("invalid key %q: %w", k.Key, err)
+		}
+		keys[i] = key
+		total += k.Weight
+	}
+	sorted := Authority{Threshold: a.Threshold, Keys: make([]KeyWeight, len(a.Keys))}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		ki, kj := keys[order[i]], keys[order[j]]
+		if ki.Curve != kj.Curve {
+			return ki.Curve < kj.Curve
+		}
+		return bytes.Compare(ki.Content, kj.Content) < 0
+	})
+	for i, o := range order {
+		sorted.Keys[i] = a.Keys[o]
+		if i > 0 && samePublicKey(keys[order[i-1]], keys[o]) {
+			return a, fmt.Errorf("duplicate key %s", a.Keys[o].Key)
+		}
+	}
+	sorted.Accounts = append(sorted.Accounts, a.Accounts...)
+	names := make(map[string][2]uint64)
+	for _, p := range sorted.Accounts {
+		actor, err := eos.StringToName(p.Permission.Actor)
+		if err != nil {
+			return a, err
+		}
+		perm, err := eos.StringToName(p.Permission.Permission)
+		if err != nil {
+			return a, err
+		}
+		if _, dup := names[p.Permission.String()]; dup {
+			return a, fmt.Errorf("duplicate permission %s", p.Permission)
+		}
+		names[p.Permission.String()] = [2]uint64{actor, perm}
+		total += p.Weight
+	}
+	sort.Slice(sorted.Accounts, func(i, j int) bool {
+		ni, nj := names[sorted.Accounts[i].Permission.String()], names[sorted.Accounts[j].Permission.String()]
+		if ni[0] != nj[0] {
+			return ni[0] < nj[0]
+		}
+		return ni[1] < nj[1]
+	})
+	sorted.Waits = append(sorted.Waits, a.Waits...)
+	sort.Slice(sorted.Waits, func(i, j int) bool {
+		return sorted.Waits[i].WaitSec < sorted.Waits[j].WaitSec
+	})
+	for _, w := range sorted.Waits {
+		total += w.Weight
+	}
+	if total < a.Threshold {
+		return a, fmt.Errorf("weights add up to %d, the threshold of %d can never be reached", total, a.Threshold)
+	}
+	return sorted, nil
+}
+
+// pack uses the binary encoding of an eosio authority, it must be normalized first
+func (a Authority) pack(buf *bytes.Buffer) error {
+	_ = binary.Write(buf, binary.LittleEndian, uint32(a.Threshold))
+	writeVarUint(buf, uint64(len(a.Keys)))
+	for _, k := range a.Keys {
+		key, err := ecc.NewPublicKey(k.Key)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(byte(key.Curve))
+		buf.Write(key.Content)
+		_ = binary.Write(buf, binary.LittleEndian, uint16(k.Weight))
+	}
+	writeVarUint(buf, uint64(len(a.Accounts)))
+	for _, p := range a.Accounts {
+		if err := writeAbiNames(buf, p.Permission.Actor, p.Permission.Permission); err != nil {
+			return err
+		}
+		_ = binary.Write(buf, binary.LittleEndian, uint16(p.Weight))
+	}
+	writeVarUint(buf, uint64(len(a.Waits)))
+	for _, w := range a.Waits {
+		_ = binary.Write(buf, binary.LittleEndian, w.WaitSec)
+		_ = binary.Write(buf, binary.LittleEndian, uint16(w.Weight))
+	}
+	return nil
+}
+
+// AuthChange is a planned updateauth, print it to see the difference before signing. Current is nil when the
+// permission does not exist yet.
+type AuthChange struct {
+	Account    string     `json:"account"`
+	Permission string     `json:"permission"`
+	Parent     string     `json:"parent"`
+	Current    *Authority `json:"current,omitempty"`
+	Proposed   Authority  `json:"proposed"`
+}
+
+// String is a diff of the permission, removed entries are prefixed with - and added ones with +
+func (c AuthChange) String() string {
+	s := &strings.Builder{}
+	fmt.Fprintf(s, "%s@%s (parent %s)\n", c.Account, c.Permission, c.Parent)
+	current := c.Current
+	if current == nil {
+		s.WriteString("  new permission\n")
+		current = &Authority{}
+	}
+	if current.Threshold != c.Proposed.Threshold {
+		fmt.Fprintf(s, "  threshold %d -> %d\n", current.Threshold, c.Proposed.Threshold)
+	} else {
+		fmt.Fprintf(s, "  threshold %d\n", current.Threshold)
+	}
+	lines := func(a *Authority) (ids []string, text map[string]string) {
+		text = make(map[string]string)
+		add := func(id string, line string) {
+			ids = append(ids, id)
+			text[id] = line
+		}
+		for _, k := range a.Keys {
+			add("k"+keyContent(k.Key), fmt.Sprintf("key %s weight %d", k.Key, k.Weight))
+		}
+		for _, p := range a.Accounts {
+			add("p"+p.Permission.String(), fmt.Sprintf("account %s weight %d", p.Permission, p.Weight))
+		}
+		for _, w := range a.Waits {
+			add(fmt.Sprintf("w%d", w.WaitSec), fmt.Sprintf("wait %ds weight %d", w.WaitSec, w.Weight))
+		}
+		return
+	}
+	oldIds, oldText := lines(current)
+	newIds, newText := lines(&c.Proposed)
+	for _, id := range oldIds {
+		switch line, ok := newText[id]; {
+		case !ok:
+			fmt.Fprintf(s, "- %s\n", oldText[id])
+		case line != oldText[id]:
+			fmt.Fprintf(s, "- %s\n+ %s\n", oldText[id], line)
+		default:
+			fmt.Fprintf(s, "  %s\n", line)
+		}
+	}
+	for _, id := range newIds {
+		if _, ok := oldText[id]; !ok {
+			fmt.Fprintf(s, "+ %s\n", newText[id])
+		}
+	}
+	return s.String()
+}
+
+// Authorizer changes the permissions of the account belonging to one key of a Signer
+type Authorizer struct {
+	payer
+}
+
+// NewAuthorizer manages the account of the key pub held by signer, fees is optional and lets several helpers
+// share a FeeCache
+func NewAuthorizer(api *fio.API, signer Signer, pub string, fees *FeeCache) (*Authorizer, error) {
+	p, err := newPayer(api, signer, pub, fees)
+	if err != nil {
+		return nil, err
+	}
+	return &Authorizer{payer: p}, nil
+}
+
+// Authorizer creates an Authorizer for the key at index
+func (hd Hd) Authorizer(api *fio.API, index int) (*Authorizer, error) {
+	p, err := hdPayer(hd, api, index)
+	if err != nil {
+		return nil, err
+	}
+	return &Authorizer{payer: p}, nil
+}
+
+// Permissions fetches the account's current permissions
+func (a *Authorizer) Permissions(ctx context.Context) ([]AccountPermission, error) {
+	details, err := GetAccountDetails(ctx, a.api, string(a.actor))
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("account %s does not exist", a.actor)
+	}
+	return details.Permissions, nil
+}
+
+// PlanUpdateAuth is a dry run of setting permission to auth, nothing is signed. An empty parent keeps the
+// existing one, a new permission needs a parent. Pass the result to UpdateAuth to apply it.
+func (a *Authorizer) PlanUpdateAuth(ctx context.Context, permission string, parent string, auth Authority) (*AuthChange, error) {
+	proposed, err := auth.normalize()
+	if err != nil {
+		return nil, err
+	}
+	perms, err := a.Permissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	change := &AuthChange{Account: string(a.actor), Permission: permission, Parent: parent, Proposed: proposed}
+	for i := range perms {
+		if perms[i].PermName == permission {
+			change.Current = &perms[i].RequiredAuth
+			if change.Parent == "" {
+				change.Parent = perms[i].Parent
+			}
+		}
+	}
+	if change.Parent == "" && permission != "owner" {
+		return nil, fmt.Errorf("%s is a new permission and needs a parent", permission)
+	}
+	return change, nil
+}
+
+// UpdateAuth applies a planned change. Changing owner is authorized by owner, anything else by active, so the
+// Authorizer's key must satisfy that permission on its own.
+func (a *Authorizer) UpdateAuth(ctx context.Context, change *AuthChange) (*PushResult, error) {
+	if change == nil {
+		return nil, errors.New("plan the change with PlanUpdateAuth first")
+	}
+	if change.Account != string(a.actor) {
+		return nil, fmt.Errorf("change is for %s, not %s", change.Account, a.actor)
+	}
+	fee, err := a.fees.FeeFor(ctx, "auth_update", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, change.Account, change.Permission, change.Parent); err != nil {
+		return nil, err
+	}
+	if err = change.Proposed.pack(buf); err != nil {
+		return nil, err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	action := newAction("eosio", "updateauth", a.actor, buf.Bytes())
+	if change.Permission == "owner" {
+		action.Authorization[0].Permission = "owner"
+	}
+	return sendActions(ctx, a.api, a.signer, a.pub, action)
+}
+
+// LinkAuth lets requirement, a custom permission, authorize the code::action contract action
+func (a *Authorizer) LinkAuth(ctx context.Context, code string, action string, requirement string) (*PushResult, error) {
+	fee, err := a.fees.FeeFor(ctx, "auth_link", "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeAbiNames(buf, string(a.actor), code, action, requirement); err != nil {
+		return nil, err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	return sendActions(ctx, a.api, a.signer, a.pub, newAction("eosio", "linkauth", a.actor, buf.Bytes()))
+}
@@ -0,0 +1,131 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestAuthorizer(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := hd.PubKeysRange(0, 3)
+	single, _ := KeyAuthority(1, pubs[0].String())
+	nodeos := newTestNodeos(map[string]interface{}{
+		"/v1/chain/get_account": AccountDetails{Permissions: []AccountPermission{
+			{PermName: "active", Parent: "owner", RequiredAuth: single},
+			{PermName: "owner", RequiredAuth: single},
+		}},
+	})
+	defer nodeos.Close()
+	a, err := hd.Authorizer(nodeos.api(), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+
+	multisig, err := hd.MultisigAuthority(2, 0, 3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	change, err := a.PlanUpdateAuth(ctx, "active", "", multisig)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	diff := change.String()
+	for _, s := range []string{"(parent owner)", "threshold 1 -> 2", "  key " + pubs[0].String(), "+ key " + pubs[1].String(), "+ key " + pubs[2].String()} {
+		if !strings.Contains(diff, s) {
+			t.Errorf("diff is missing %q:\n%s", s, diff)
+		}
+	}
+	if len(nodeos.pushed) != 0 {
+		t.Error("planning should not push a transaction")
+	}
+	if _, err = a.PlanUpdateAuth(ctx, "transfers", "", single); err == nil {
+		t.Error("expected a new permission without a parent to be rejected")
+	}
+
+	if _, err = a.UpdateAuth(ctx, change); err != nil {
+		t.Error(err)
+		return
+	}
+	data := nodeos.checkPushed(t, 0, a.pub)
+	expect := &bytes.Buffer{}
+	_ = writeAbiNames(expect, string(a.actor), "active", "owner")
+	_ = binary.Write(expect, binary.LittleEndian, uint32(2))
+	writeVarUint(expect, 3)
+	for _, k := range multisig.Keys {
+		expect.WriteByte(0)
+		expect.WriteString(keyContent(k.Key))
+		_ = binary.Write(expect, binary.LittleEndian, uint16(1))
+	}
+	expect.Write([]byte{0, 0})
+	_ = binary.Write(expect, binary.LittleEndian, uint64(40_000_000_000))
+	if !bytes.Equal(data[0], expect.Bytes()) {
+		t.Errorf("unexpected updateauth data %x", data[0])
+	}
+
+	change, _ = a.PlanUpdateAuth(ctx, "owner", "", multisig)
+	if _, err = a.UpdateAuth(ctx, change); err != nil {
+		t.Error(err)
+		return
+	}
+	packed, _ := hex.DecodeString(nodeos.pushed[1].PackedTrx)
+	trx, err := unpackTransaction(packed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if trx.Actions[0].Authorization[0].Permission != "owner" {
+		t.Error("expected changing owner to be authorized by owner", trx.Actions[0].Authorization)
+	}
+}
+
+func TestKeyAuthority(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := hd.PubKeysRange(0, 4)
+	keys := make([]string, len(pubs))
+	for i := range pubs {
+		keys[i] = pubs[i].String()
+	}
+	auth, err := KeyAuthority(3, keys...)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for i := 1; i < len(auth.Keys); i++ {
+		if keyContent(auth.Keys[i-1].Key) >= keyContent(auth.Keys[i].Key) {
+			t.Error("keys are not sorted", auth.Keys)
+		}
+	}
+	if _, err = KeyAuthority(5, keys...); err == nil {
+		t.Error("expected an unreachable threshold to be rejected")
+	}
+	if _, err = KeyAuthority(1, keys[0], keys[1], keys[0]); err == nil {
+		t.Error("expected a duplicate key to be rejected")
+	}
+	if _, err = KeyAuthority(0, keys...); err == nil {
+		t.Error("expected a zero threshold to be rejected")
+	}
+	accounts, err := Authority{Threshold: 1, Accounts: []PermissionLevelWeight{
+		{Permission: PermissionLevel{Actor: "carol", Permission: "active"}, Weight: 1},
+		{Permission: PermissionLevel{Actor: "bob", Permission: "owner"}, Weight: 1},
+		{Permission: PermissionLevel{Actor: "bob", Permission: "active"}, Weight: 1},
+	}}.normalize()
+	if err != nil || accounts.Accounts[0].Permission.String() != "bob@active" || accounts.Accounts[2].Permission.String() != "carol@active" {
+		t.Error("accounts are not sorted", accounts.Accounts, err)
+	}
+}
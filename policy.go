@@ -0,0 +1,425 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPolicyDenied is returned when a PolicySigner refuses to sign
+var ErrPolicyDenied = errors.New("denied by the signing policy")
+
+// Policy is the set of rules a PolicySigner enforces. Anything the rules do not allow is passed to Approve, and
+// refused when there is no Approve.
+type Policy struct {
+	// Actions allowed as contract::action, contract::* allows every action of a contract. Empty allows none.
+	Actions []string
+	// DailyLimit is the most each key may spend in a UTC day, in SUFs, zero means there is no limit. With a limit,
+	// fio.token actions other than trnsfiopubky always need approval since what they spend is not measured.
+	DailyLimit uint64
+	// Payees are the public keys transfers may be sent to, empty allows any
+	Payees []string
+	// Approve is asked about anything else, usually by a human
+	Approve ApprovalFunc
+}
+
+// ApprovalFunc decides whether to sign a request the policy rules did not allow
+type ApprovalFunc func(ctx context.Context, req SignRequest) (bool, error)
+
+// SignRequest describes what a PolicySigner was asked to sign and why it needs approval
+type SignRequest struct {
+	PublicKeys []string
+	Digest     []byte
+	Actions    []PolicyAction // empty when only a digest was provided, it cannot be inspected
+	Reason     string
+}
+
+// PolicyAction is one action of a transaction as seen by the policy
+type PolicyAction struct {
+	Contract string
+	Action   string
+	Actors   []string
+	Payee    string // for transfers
+	Amount   uint64 // SUFs spent, the max fee plus the amount for transfers
+}
+
+// PolicySigner wraps a Signer, checking each request against a Policy before passing it on. It is meant for hot
+// wallets signing automatically, where a bug or a compromised caller should not be able to drain an account.
+type PolicySigner struct {
+	signer Signer
+	policy Policy
+	now    func() time.Time
+
+	mux   sync.Mutex
+	spent map[string]dailySpend
+}
+
+type dailySpend struct {
+	day    string
+	amount uint64
+}
+
+// NewPolicySigner enforces policy for signer
+func NewPolicySigner(signer Signer, policy Policy) (*PolicySigner, error) {
+	if signer == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	for _, a := range policy.Actions {
+		if !strings.Contains(a, "::") {
+			return nil, fmt.Errorf("invalid action %q, expected contract::action", a)
+		}
+	}
+	return &PolicySigner{signer: signer, policy: policy, now: time.Now, spent: make(map[string]dailySpend)}, nil
+}
+
+// PublicKeys lists the public keys of the wrapped signer
+func (ps *PolicySigner) PublicKeys(ctx context.Context) ([]string, error) {
+	return ps.signer.PublicKeys(ctx)
+}
+
+// Spent provides how much pub has spent today, in SUFs
+func (ps *PolicySigner) Spent(pub string) uint64 {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+	s := ps.spent[keyContent(pub)]
+	if s.day != ps.today() {
+		return 0
+	}
+	return s.amount
+}
+
+// SignDigest always needs approval since there is no way to tell what the digest is for
+func (ps *PolicySigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	req := SignRequest{PublicKeys: []string{pub}, Digest: digest, Reason: "a digest cannot be checked"}
+	if err := ps.approve(ctx, req); err != nil {
+		return ecc.Signature{}, err
+	}
+	return ps.signer.SignDigest(ctx, digest, pub)
+}
+
+// SignTransaction checks the transaction against the policy, asking for approval when it is not allowed, and signs
+// it with the wrapped signer. Spending is counted against each of pubkeys.
+func (ps *PolicySigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	actions, err := policyActions(tx)
+	if err != nil {
+		return nil, err
+	}
+	var amount uint64
+	for _, a := range actions {
+		if amount+a.Amount < amount {
+			return nil, fmt.Errorf("%w: the amount spent overflows", ErrPolicyDenied)
+		}
+		amount += a.Amount
+	}
+
+	ps.mux.Lock()
+	reason := ps.check(actions, pubkeys, amount)
+	if reason == "" {
+		err = ps.spend(pubkeys, amount)
+	}
+	ps.mux.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		if err = ps.approve(ctx, SignRequest{PublicKeys: pubkeys, Actions: actions, Reason: reason}); err != nil {
+			return nil, err
+		}
+		ps.mux.Lock()
+		err = ps.spend(pubkeys, amount)
+		ps.mux.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sigs, err := ps.signer.SignTransaction(ctx, tx, pubkeys, chainID)
+	if err != nil {
+		// nothing was spent after all
+		ps.mux.Lock()
+		ps.refund(pubkeys, amount)
+		ps.mux.Unlock()
+		return nil, err
+	}
+	return sigs, nil
+}
+
+// check provides the first rule broken by the request, or an empty string, ps.mux must be held
+func (ps *PolicySigner) check(actions []PolicyAction, pubkeys []string, amount uint64) string {
+	for _, a := range actions {
+		if !ps.actionAllowed(a) {
+			return fmt.Sprintf("%s::%s is not an allowed action", a.Contract, a.Action)
+		}
+		if a.Payee != "" && !ps.payeeAllowed(a.Payee) {
+			return fmt.Sprintf("%s is not an allowed payee", a.Payee)
+		}
+		if ps.policy.DailyLimit != 0 && a.Contract == "fio.token" && a.Action != "trnsfiopubky" {
+			return fmt.Sprintf("%s::%s moves tokens without being counted toward the daily limit", a.Contract, a.Action)
+		}
+	}
+	if ps.policy.DailyLimit == 0 || amount == 0 {
+		return ""
+	}
+	today := ps.today()
+	for _, pub := range pubkeys {
+		s := ps.spent[keyContent(pub)]
+		if s.day != today {
+			s.amount = 0
+		}
+		if amount > ps.policy.DailyLimit || s.amount > ps.policy.DailyLimit-amount {
+			return fmt.Sprintf("%s would exceed the daily limit of %d", pub, ps.policy.DailyLimit)
+		}
+	}
+	return ""
+}
+
+func (ps *PolicySigner) actionAllowed(a PolicyAction) bool {
+	for _, allowed := range ps.policy.Actions {
+		if allowed == a.Contract+"::"+a.Action || allowed == a.Contract+"::*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (ps *PolicySigner) payeeAllowed(payee string) bool {
+	if len(ps.policy.Payees) == 0 {
+		return true
+	}
+	for _, p := range ps.policy.Payees {
+		if keyContent(p) == keyContent(payee) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ps *PolicySigner) approve(ctx context.Context, req SignRequest) error {
+	if ps.policy.Approve == nil {
+		return fmt.Errorf("%w: %s", ErrPolicyDenied, req.Reason)
+	}
+	ok, err := ps.policy.Approve(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: not approved, %s", ErrPolicyDenied, req.Reason)
+	}
+	return nil
+}
+
+// spend and refund adjust today's total for each key, ps.mux must be held. Nothing is spent when a total would
+// overflow, the request is denied instead.
+func (ps *PolicySigner) spend(pubkeys []string, amount uint64) error {
+	today := ps.today()
+	totals := make(map[string]dailySpend)
+	for _, pub := range pubkeys {
+		s, ok := totals[keyContent(pub)]
+		if !ok {
+			s = ps.spent[keyContent(pub)]
+		}
+		if s.day != today {
+			s = dailySpend{day: today}
+		}
+		if s.amount+amount < s.amount {
+			return fmt.Errorf("%w: the amount spent today by %s overflows", ErrPolicyDenied, pub)
+		}
+		s.amount += amount
+		totals[keyContent(pub)] = s
+	}
+	for k, s := range totals {
+		ps.spent[k] = s
+	}
+	return nil
+}
+
+func (ps *PolicySigner) refund(pubkeys []string, amount uint64) {
+	today := ps.today()
+	for _, pub := range pubkeys {
+		s := ps.spent[keyContent(pub)]
+		if s.day != today || s.amount < amount {
+			continue
+		}
+		s.amount -= amount
+		ps.spent[keyContent(pub)] = s
+	}
+}
+
+func (ps *PolicySigner) today() string {
+	return ps.now().UTC().Format("2006-01-02")
+}
+
+// policyActions decodes the actions of a transaction in the same JSON form PackTransactionJSON accepts. Transfers
+// made with fio.token::trnsfiopubky are decoded so their payee and amount can be checked, and the max fee of the other
+// actions in feeLayouts is counted as spent.
+func policyActions(tx interface{}) ([]PolicyAction, error) {
+	if tx == nil {
+		return nil, errors.New("transaction cannot be nil")
+	}
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	t := jsonTransaction{}
+	if err = json.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	actions := make([]PolicyAction, 0, len(t.ContextFreeActions)+len(t.Actions))
+	for _, a := range append(t.ContextFreeActions, t.Actions...) {
+		// keosd signs data and ignores hex_data, so what is checked has to be what gets signed
+		if a.HexData != "" && len(a.Data) > 0 && string(a.Data) != "null" {
+			var data string
+			if json.Unmarshal(a.Data, &data) != nil || !strings.EqualFold(data, a.HexData) {
+				return nil, fmt.Errorf("%w: %s::%s has data and hex_data that differ", ErrPolicyDenied, a.Account, a.Name)
+			}
+		}
+		pa := PolicyAction{Contract: a.Account, Action: a.Name}
+		for _, p := range a.Authorization {
+			pa.Actors = append(pa.Actors, p.Actor)
+		}
+		if a.Account == "fio.token" && a.Name == "trnsfiopubky" {
+			if pa.Payee, pa.Amount, err = decodeTransfer(a); err != nil {
+				return nil, fmt.Errorf("could not decode transfer: %w", err)
+			}
+		} else if layout, ok := feeLayouts[a.Account+"::"+a.Name]; ok {
+			if pa.Amount, err = decodeMaxFee(a, layout); err != nil {
+				return nil, fmt.Errorf("could not decode the max fee of %s::%s: %w", a.Account, a.Name, err)
+			}
+		}
+		actions = append(actions, pa)
+	}
+	return actions, nil
+}
+
+// decodeTransfer reads the payee and amount plus max fee from trnsfiopubky action data
+func decodeTransfer(a jsonAction) (payee string, amount uint64, err error) {
+	data, err := hexActionData(a)
+	if err != nil {
+		return "", 0, err
+	}
+	r := bytes.NewReader(data)
+	s, err := readAbiStrings(r, 1)
+	if err != nil {
+		return "", 0, err
+	}
+	values := make([]int64, 2)
+	if err = binary.Read(r, binary.LittleEndian, values); err != nil {
+		return "", 0, err
+	}
+	if values[0] < 0 || values[1] < 0 {
+		return "", 0, errors.New("negative amount")
+	}
+	return s[0], uint64(values[0]) + uint64(values[1]), nil
+}
+
+// feeLayouts describes the fields before max_fee in the data of the FIO actions that pay a fee. Each letter is a field:
+// s is a string, n an 8 byte name or number, b and h 1 and 2 byte numbers, S a list of strings, A a list of public
+// addresses and L a list of permission levels.
+var feeLayouts = map[string]string{
+	"fio.address::addaddress":   "sA",
+	"fio.address::addbundles":   "sn",
+	"fio.address::regaddress":   "ss",
+	"fio.address::regdomain":    "ss",
+	"fio.address::remaddress":   "sA",
+	"fio.address::remalladdr":   "s",
+	"fio.address::renewaddress": "s",
+	"fio.address::renewdomain":  "s",
+	"fio.address::setdomainpub": "sb",
+	"fio.address::xferaddress":  "ss",
+	"fio.address::xferdomain":   "ss",
+	"fio.reqobt::cancelfndreq":  "s",
+	"fio.reqobt::newfundsreq":   "sss",
+	"fio.reqobt::recordobt":     "ssss",
+	"fio.reqobt::rejectfndreq":  "s",
+	"fio.staking::stakefio":     "sn",
+	"fio.staking::unstakefio":   "sn",
+	"eosio::regproducer":        "ssshn",
+	"eosio::regproxy":           "sn",
+	"eosio::unregprod":          "sn",
+	"eosio::unregproxy":         "sn",
+	"eosio::voteproducer":       "Ssn",
+	"eosio::voteproxy":          "ssn",
+	"eosio.msig::approve":       "nnnn",
+	"eosio.msig::cancel":        "nnn",
+	"eosio.msig::exec":          "nn",
+	"eosio.msig::propose":       "nnL",
+	"eosio.msig::unapprove":     "nnnn",
+}
+
+// decodeMaxFee skips the fields in layout to read the max_fee of an action
+func decodeMaxFee(a jsonAction, layout string) (uint64, error) {
+	data, err := hexActionData(a)
+	if err != nil {
+		return 0, err
+	}
+	r := bytes.NewReader(data)
+	for _, field := range layout {
+		switch field {
+		case 's':
+			_, err = readAbiStrings(r, 1)
+		case 'n':
+			err = skipBytes(r, 8)
+		case 'b':
+			err = skipBytes(r, 1)
+		case 'h':
+			err = skipBytes(r, 2)
+		case 'S', 'A', 'L':
+			var n uint64
+			if n, err = binary.ReadUvarint(r); err != nil {
+				break
+			}
+			if n > uint64(r.Len()) {
+				return 0, errors.New("invalid list length")
+			}
+			for i := uint64(0); i < n && err == nil; i++ {
+				switch field {
+				case 'S':
+					_, err = readAbiStrings(r, 1)
+				case 'A':
+					_, err = readAbiStrings(r, 3)
+				case 'L':
+					err = skipBytes(r, 16)
+				}
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	var fee int64
+	if err = binary.Read(r, binary.LittleEndian, &fee); err != nil {
+		return 0, err
+	}
+	if fee < 0 {
+		return 0, errors.New("negative max fee")
+	}
+	return uint64(fee), nil
+}
+
+// hexActionData provides the serialized data of an action, which must be hex
+func hexActionData(a jsonAction) ([]byte, error) {
+	hexData := a.HexData
+	if hexData == "" && len(a.Data) > 0 {
+		if err := json.Unmarshal(a.Data, &hexData); err != nil {
+			return nil, errors.New("data must be serialized as hex")
+		}
+	}
+	return hex.DecodeString(hexData)
+}
+
+func skipBytes(r *bytes.Reader, n int) error {
+	if r.Len() < n {
+		return io.ErrUnexpectedEOF
+	}
+	_, err := r.Seek(int64(n), io.SeekCurrent)
+	return err
+}
@@ -0,0 +1,142 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos"
+	"math"
+	"testing"
+	"time"
+)
+
+func transferTx(payee string, amount int64) map[string]interface{} {
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, payee)
+	_ = binary.Write(buf, binary.LittleEndian, []int64{amount, 2_000_000_000})
+	actor, _ := eos.StringToName("aftyershcu22")
+	_ = binary.Write(buf, binary.LittleEndian, actor)
+	writeAbiStrings(buf, "")
+	return map[string]interface{}{
+		"expiration": "2020-07-01T00:00:00",
+		"actions": []map[string]interface{}{{
+			"account":       "fio.token",
+			"name":          "trnsfiopubky",
+			"authorization": []map[string]string{{"actor": "aftyershcu22", "permission": "active"}},
+			"hex_data":      hex.EncodeToString(buf.Bytes()),
+		}},
+	}
+}
+
+func TestPolicySigner(t *testing.T) {
+	bag := eos.NewKeyBag()
+	if err := bag.Add("5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"); err != nil {
+		t.Error(err)
+		return
+	}
+	signer, err := NewKeyBagSigner(bag)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	pubs, _ := signer.PublicKeys(ctx)
+	payee := "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"
+
+	approvals := make([]SignRequest, 0)
+	approve := false
+	ps, err := NewPolicySigner(signer, Policy{
+		Actions:    []string{"fio.token::trnsfiopubky", "fio.address::*"},
+		DailyLimit: 10_000_000_000,
+		Payees:     []string{payee},
+		Approve: func(ctx context.Context, req SignRequest) (bool, error) {
+			approvals = append(approvals, req)
+			return approve, nil
+		},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	day := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	ps.now = func() time.Time { return day }
+
+	// 5 FIO plus a 2 FIO fee is within the limit
+	if _, err = ps.SignTransaction(ctx, transferTx(payee, 5_000_000_000), pubs, FioMainnetChainID); err != nil {
+		t.Error(err)
+		return
+	}
+	if ps.Spent(pubs[0]) != 7_000_000_000 || len(approvals) != 0 {
+		t.Error("unexpected spend", ps.Spent(pubs[0]), approvals)
+	}
+
+	// the second would exceed it
+	if _, err = ps.SignTransaction(ctx, transferTx(payee, 5_000_000_000), pubs, FioMainnetChainID); !errors.Is(err, ErrPolicyDenied) {
+		t.Error("expected the daily limit to be enforced", err)
+	}
+	if len(approvals) != 1 || len(approvals[0].Actions) != 1 || approvals[0].Actions[0].Payee != payee {
+		t.Error("expected an approval request for the transfer", approvals)
+	}
+
+	// other payees need approval
+	if _, err = ps.SignTransaction(ctx, transferTx(pubs[0], 1), pubs, FioMainnetChainID); !errors.Is(err, ErrPolicyDenied) {
+		t.Error("expected the payee to be refused", err)
+	}
+	approve = true
+	if _, err = ps.SignTransaction(ctx, transferTx(pubs[0], 1), pubs, FioMainnetChainID); err != nil {
+		t.Error(err)
+	}
+
+	// a new day resets the limit
+	day = day.Add(24 * time.Hour)
+	approve = false
+	if _, err = ps.SignTransaction(ctx, transferTx(payee, 5_000_000_000), pubs, FioMainnetChainID); err != nil {
+		t.Error(err)
+	}
+	if _, err = ps.SignDigest(ctx, make([]byte, 32), pubs[0]); !errors.Is(err, ErrPolicyDenied) {
+		t.Error("expected digests to need approval", err)
+	}
+
+	// the max fee of other actions counts toward the limit, 7 FIO was spent today so a 4 FIO fee is too much
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, "alice@fiotestnet", payee)
+	_ = binary.Write(buf, binary.LittleEndian, int64(4_000_000_000))
+	reg := map[string]interface{}{"actions": []map[string]interface{}{{
+		"account":       "fio.address",
+		"name":          "regaddress",
+		"authorization": []map[string]string{{"actor": "aftyershcu22", "permission": "active"}},
+		"hex_data":      hex.EncodeToString(buf.Bytes()),
+	}}}
+	if _, err = ps.SignTransaction(ctx, reg, pubs, FioMainnetChainID); !errors.Is(err, ErrPolicyDenied) {
+		t.Error("expected the max fee to count toward the daily limit", err)
+	}
+	if actions, err := policyActions(reg); err != nil || actions[0].Amount != 4_000_000_000 {
+		t.Error("unexpected actions", actions, err)
+	}
+
+	// data that does not match hex_data is refused, keosd would sign the data
+	mismatched := transferTx(payee, 1)
+	mismatched["actions"].([]map[string]interface{})[0]["data"] = transferTx(pubs[0], 1_000_000_000)["actions"].([]map[string]interface{})[0]["hex_data"]
+	if _, err = ps.SignTransaction(ctx, mismatched, pubs, FioMainnetChainID); !errors.Is(err, ErrPolicyDenied) {
+		t.Error("expected differing data and hex_data to be refused", err)
+	}
+
+	// amounts that overflow when added are refused without asking
+	huge := transferTx(payee, math.MaxInt64)
+	huge["actions"] = append(huge["actions"].([]map[string]interface{}), huge["actions"].([]map[string]interface{})...)
+	approve, approvals = true, approvals[:0]
+	if _, err = ps.SignTransaction(ctx, huge, pubs, FioMainnetChainID); !errors.Is(err, ErrPolicyDenied) || len(approvals) != 0 {
+		t.Error("expected an overflow to be denied", err, approvals)
+	}
+
+	// other token actions are not metered so they need approval when there is a limit
+	locked := transferTx(payee, 1)
+	locked["actions"].([]map[string]interface{})[0]["name"] = "trnslocktoks"
+	ps.policy.Actions = append(ps.policy.Actions, "fio.token::*")
+	approve = false
+	if _, err = ps.SignTransaction(ctx, locked, pubs, FioMainnetChainID); !errors.Is(err, ErrPolicyDenied) || len(approvals) != 1 {
+		t.Error("expected trnslocktoks to need approval", err, approvals)
+	}
+}
@@ -0,0 +1,149 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"io"
+	"strings"
+)
+
+// PrintTransaction fetches a transaction from the history plugin and writes a readable summary of its actions to w
+func PrintTransaction(ctx context.Context, api *fio.API, w io.Writer, id string) error {
+	resp := struct {
+		ID        string `json:"id"`
+		BlockNum  uint32 `json:"block_num"`
+		BlockTime string `json:"block_time"`
+		Trx       struct {
+			Receipt struct {
+				Status string `json:"status"`
+			} `json:"receipt"`
+			Trx struct {
+				Actions []DecodedAction `json:"actions"`
+			} `json:"trx"`
+		} `json:"trx"`
+	}{}
+	found, err := chainPost(ctx, api, "/v1/history/get_transaction", map[string]string{"id": id}, &resp)
+	if err != nil {
+		return err
+	}
+	if !found || resp.ID == "" {
+		return fmt.Errorf("transaction %s was not found", id)
+	}
+	fmt.Fprintf(w, "Transaction %s %s in block %d at %s\n", resp.ID, resp.Trx.Receipt.Status, resp.BlockNum, resp.BlockTime)
+	for _, a := range resp.Trx.Trx.Actions {
+		fmt.Fprintf(w, "  %s\n", DescribeAction(a))
+	}
+	return nil
+}
+
+// PrintBlock fetches a block by number or id and writes a readable summary of its transactions to w
+func PrintBlock(ctx context.Context, api *fio.API, w io.Writer, numOrID string) error {
+	resp := struct {
+		ID           string `json:"id"`
+		BlockNum     uint32 `json:"block_num"`
+		Timestamp    string `json:"timestamp"`
+		Producer     string `json:"producer"`
+		Transactions []struct {
+			Status string          `json:"status"`
+			Trx    json.RawMessage `json:"trx"`
+		} `json:"transactions"`
+	}{}
+	found, err := chainPost(ctx, api, "/v1/chain/get_block", map[string]string{"block_num_or_id": numOrID}, &resp)
+	if err != nil {
+		return err
+	}
+	if !found || resp.ID == "" {
+		return fmt.Errorf("block %s was not found", numOrID)
+	}
+	fmt.Fprintf(w, "Block %d %s produced by %s at %s, %d transactions\n", resp.BlockNum, resp.ID, resp.Producer, resp.Timestamp, len(resp.Transactions))
+	for _, t := range resp.Transactions {
+		// deferred transactions only have their id in the block
+		var deferred string
+		if json.Unmarshal(t.Trx, &deferred) == nil {
+			fmt.Fprintf(w, "  Transaction %s %s (deferred)\n", deferred, t.Status)
+			continue
+		}
+		trx := struct {
+			ID          string `json:"id"`
+			Transaction struct {
+				Actions []DecodedAction `json:"actions"`
+			} `json:"transaction"`
+		}{}
+		if err = json.Unmarshal(t.Trx, &trx); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  Transaction %s %s\n", trx.ID, t.Status)
+		for _, a := range trx.Transaction.Actions {
+			fmt.Fprintf(w, "    %s\n", DescribeAction(a))
+		}
+	}
+	return nil
+}
+
+// fioActionData holds the fields of the FIO actions DescribeAction knows about, amounts are sometimes strings
+type fioActionData struct {
+	Actor          string      `json:"actor"`
+	MaxFee         json.Number `json:"max_fee"`
+	PayeePublicKey string      `json:"payee_public_key"`
+	Amount         json.Number `json:"amount"`
+	FioAddress     string      `json:"fio_address"`
+	OwnerPublicKey string      `json:"owner_fio_public_key"`
+	PayerAddress   string      `json:"payer_fio_address"`
+	PayeeAddress   string      `json:"payee_fio_address"`
+	Content        string      `json:"content"`
+	FioRequestID   string      `json:"fio_request_id"`
+}
+
+// DescribeAction renders an action as a sentence for the common FIO actions, with amounts in FIO. Other actions
+// are shown with their data as is.
+func DescribeAction(a DecodedAction) string {
+	actors := make([]string, len(a.Authorization))
+	for i := range a.Authorization {
+		actors[i] = a.Authorization[i].String()
+	}
+	generic := fmt.Sprintf("%s::%s by %s", a.Account, a.Name, strings.Join(actors, ", "))
+	if len(a.Data) == 0 || a.Data[0] != '{' {
+		if a.HexData != "" {
+			return generic + " (hex " + a.HexData + ")"
+		}
+		return generic
+	}
+	d := fioActionData{}
+	if err := json.Unmarshal(a.Data, &d); err != nil {
+		return generic + " " + string(a.Data)
+	}
+	if d.Actor == "" && len(a.Authorization) > 0 {
+		d.Actor = a.Authorization[0].Actor
+	}
+	var s string
+	switch a.Account + "::" + a.Name {
+	case "fio.token::trnsfiopubky":
+		s = fmt.Sprintf("%s sent %s to %s", d.Actor, formatSUFs(d.Amount), d.PayeePublicKey)
+	case "fio.address::regaddress":
+		s = fmt.Sprintf("%s registered %s for %s", d.Actor, d.FioAddress, d.OwnerPublicKey)
+	case "fio.reqobt::newfundsreq":
+		s = fmt.Sprintf("%s requested funds from %s (%d bytes of encrypted content)", d.PayeeAddress, d.PayerAddress, len(d.Content))
+	case "fio.reqobt::recordobt":
+		s = fmt.Sprintf("%s recorded a payment to %s (%d bytes of encrypted content)", d.PayerAddress, d.PayeeAddress, len(d.Content))
+		if d.FioRequestID != "" {
+			s += " for request " + d.FioRequestID
+		}
+	default:
+		return generic + " " + string(a.Data)
+	}
+	if d.MaxFee != "" {
+		s += ", max fee " + formatSUFs(d.MaxFee)
+	}
+	return s
+}
+
+// formatSUFs shows an amount of SUFs in FIO, or as is if it is not a number
+func formatSUFs(sufs json.Number) string {
+	amount, err := sufs.Int64()
+	if err != nil {
+		return string(sufs) + " SUFs"
+	}
+	return FioAmount(amount).String()
+}
@@ -0,0 +1,87 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDescribeAction(t *testing.T) {
+	auth := []PermissionLevel{{Actor: "aftyershcu22", Permission: "active"}}
+	for _, test := range []struct {
+		account, name, data string
+		expect              string
+	}{
+		{"fio.token", "trnsfiopubky", `{"payee_public_key":"FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy","amount":"12345678901","max_fee":2000000000,"actor":"aftyershcu22","tpid":""}`,
+			"aftyershcu22 sent 12.345678901 FIO to FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy, max fee 2.000000000 FIO"},
+		{"fio.address", "regaddress", `{"fio_address":"alice@fiotestnet","owner_fio_public_key":"FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy","max_fee":40000000000,"actor":"aftyershcu22","tpid":""}`,
+			"aftyershcu22 registered alice@fiotestnet for FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy, max fee 40.000000000 FIO"},
+		{"fio.reqobt", "newfundsreq", `{"payer_fio_address":"bob@fiotestnet","payee_fio_address":"alice@fiotestnet","content":"abcd","max_fee":0,"actor":"aftyershcu22","tpid":""}`,
+			"alice@fiotestnet requested funds from bob@fiotestnet (4 bytes of encrypted content), max fee 0.000000000 FIO"},
+		{"fio.reqobt", "recordobt", `{"payer_fio_address":"bob@fiotestnet","payee_fio_address":"alice@fiotestnet","content":"ab","max_fee":0,"actor":"aftyershcu22","tpid":"","fio_request_id":"7"}`,
+			"bob@fiotestnet recorded a payment to alice@fiotestnet (2 bytes of encrypted content) for request 7, max fee 0.000000000 FIO"},
+		{"eosio", "voteproducer", `{"producers":["bp1"]}`, `eosio::voteproducer by aftyershcu22@active {"producers":["bp1"]}`},
+		{"eosio", "voteproducer", ``, `eosio::voteproducer by aftyershcu22@active (hex 00)`},
+	} {
+		a := DecodedAction{Account: test.account, Name: test.name, Authorization: auth, HexData: "00"}
+		if test.data != "" {
+			a.Data = json.RawMessage(test.data)
+		}
+		if s := DescribeAction(a); s != test.expect {
+			t.Errorf("unexpected description\n%s\n%s", s, test.expect)
+		}
+	}
+}
+
+func TestPrintBlock(t *testing.T) {
+	transfer := map[string]interface{}{
+		"account":       "fio.token",
+		"name":          "trnsfiopubky",
+		"authorization": []PermissionLevel{{Actor: "aftyershcu22", Permission: "active"}},
+		"data":          map[string]interface{}{"payee_public_key": "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy", "amount": 1_000_000_000, "max_fee": 2_000_000_000, "actor": "aftyershcu22"},
+	}
+	nodeos := newTestNodeos(map[string]interface{}{
+		"/v1/chain/get_block": map[string]interface{}{
+			"id": "0000302a", "block_num": 12330, "timestamp": "2020-07-01T00:00:00.000", "producer": "bp1",
+			"transactions": []interface{}{
+				map[string]interface{}{"status": "executed", "trx": map[string]interface{}{
+					"id": "d1e5c1b5", "transaction": map[string]interface{}{"actions": []interface{}{transfer}},
+				}},
+				map[string]interface{}{"status": "executed", "trx": "a1b2c3"},
+			},
+		},
+		"/v1/history/get_transaction": map[string]interface{}{
+			"id": "d1e5c1b5", "block_num": 12330, "block_time": "2020-07-01T00:00:00.000",
+			"trx": map[string]interface{}{"receipt": map[string]string{"status": "executed"}, "trx": map[string]interface{}{"actions": []interface{}{transfer}}},
+		},
+	})
+	defer nodeos.Close()
+	ctx := context.Background()
+
+	out := &bytes.Buffer{}
+	if err := PrintBlock(ctx, nodeos.api(), out, "12330"); err != nil {
+		t.Error(err)
+		return
+	}
+	for _, s := range []string{"Block 12330 0000302a produced by bp1", "  Transaction d1e5c1b5 executed\n    aftyershcu22 sent 1.000000000 FIO", "a1b2c3 executed (deferred)"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("block output is missing %q:\n%s", s, out)
+		}
+	}
+
+	out.Reset()
+	if err := PrintTransaction(ctx, nodeos.api(), out, "d1e5c1b5"); err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.HasPrefix(out.String(), "Transaction d1e5c1b5 executed in block 12330") || !strings.Contains(out.String(), "max fee 2.000000000 FIO") {
+		t.Error("unexpected transaction output", out)
+	}
+
+	delete(nodeos.responses, "/v1/chain/get_block")
+	if err := PrintBlock(ctx, nodeos.api(), out, "1"); err == nil {
+		t.Error("expected a missing block to fail")
+	}
+}
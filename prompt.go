@@ -0,0 +1,80 @@
+package fiox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PasswordProvider is asked for a wallet password when Unlock is called without one and no saved password was
+// found, for example by prompting on a terminal or showing a dialog
+type PasswordProvider interface {
+	WalletPassword(ctx context.Context, wallet string) (string, error)
+}
+
+// PasswordFunc allows a function to be used as a PasswordProvider
+type PasswordFunc func(ctx context.Context, wallet string) (string, error)
+
+// WalletPassword calls f
+func (f PasswordFunc) WalletPassword(ctx context.Context, wallet string) (string, error) {
+	return f(ctx, wallet)
+}
+
+// WithPasswordProvider asks provider for the password when Unlock is called without one
+func WithPasswordProvider(provider PasswordProvider) KeosOption {
+	return func(k *KeosClient) {
+		k.passwordProvider = provider
+	}
+}
+
+// PromptPassword provides a PasswordProvider that writes a prompt to out and reads a line from in, such as
+// os.Stderr and os.Stdin. When in is a terminal echo is turned off with stty while the password is typed, on
+// systems without stty the password is visible.
+func PromptPassword(out io.Writer, in io.Reader) PasswordProvider {
+	reader := bufio.NewReader(in)
+	return PasswordFunc(func(ctx context.Context, wallet string) (string, error) {
+		if _, err := fmt.Fprintf(out, "Password for wallet %s: ", wallet); err != nil {
+			return "", err
+		}
+		if f, ok := in.(*os.File); ok && isTerminal(f) {
+			stty := exec.Command("stty", "-echo")
+			stty.Stdin = f
+			if stty.Run() == nil {
+				defer func() {
+					cmd := exec.Command("stty", "echo")
+					cmd.Stdin = f
+					_ = cmd.Run()
+					_, _ = fmt.Fprintln(out)
+				}()
+			}
+		}
+		type result struct {
+			line string
+			err  error
+		}
+		read := make(chan result, 1)
+		go func() {
+			line, err := reader.ReadString('\n')
+			read <- result{line, err}
+		}()
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case r := <-read:
+			if r.err != nil && !(errors.Is(r.err, io.EOF) && r.line != "") {
+				return "", r.err
+			}
+			return strings.TrimRight(r.line, "\r\n"), nil
+		}
+	})
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
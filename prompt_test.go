@@ -0,0 +1,45 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPasswordProvider(t *testing.T) {
+	requests := make(map[string]string)
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/list_wallets": []string{"default"},
+		"/v1/wallet/unlock":       struct{}{},
+	}, requests)
+	defer server.Close()
+
+	out := &bytes.Buffer{}
+	k := NewKeosClient(server.URL, "", WithPasswordProvider(PromptPassword(out, strings.NewReader("secret\n"))))
+	ctx := context.Background()
+	if err := k.Unlock(ctx, "", "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	if out.String() != "Password for wallet default: " {
+		t.Errorf("unexpected prompt %q", out.String())
+	}
+	if requests["/v1/wallet/unlock"] != `["default","secret"]` {
+		t.Error("unlock did not use the prompted password", requests["/v1/wallet/unlock"])
+	}
+
+	// a saved password is used before prompting
+	cancelled := errors.New("cancelled")
+	k = NewKeosClient(server.URL, "", WithCredentials(memoryCredentials{"default": "saved"}),
+		WithPasswordProvider(PasswordFunc(func(ctx context.Context, wallet string) (string, error) {
+			return "", cancelled
+		})))
+	if err := k.Unlock(ctx, "", "default"); err != nil {
+		t.Error(err)
+	}
+	if err := k.Unlock(ctx, "", "other"); !errors.Is(err, cancelled) {
+		t.Error("expected the provider's error, got", err)
+	}
+}
@@ -0,0 +1,137 @@
+package fiox
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	hdwallet "github.com/blockpane/fio-extras/internal/go-ethereum-hdwallet"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"math/big"
+	"strings"
+)
+
+// R1Key is a secp256r1 (NIST P-256) key derived from the seed using SLIP-0010, use String and PublicKeyString for
+// the PVT_R1_ and PUB_R1_ formats.
+type R1Key struct {
+	*ecdsa.PrivateKey
+}
+
+// String provides the private key in the PVT_R1_ format
+func (k R1Key) String() string {
+	raw := padScalar(k.D)
+	defer wipe(raw)
+	return "PVT_R1_" + checksumEncode(raw, "R1")
+}
+
+// PublicKeyString provides the public key in the PUB_R1_ format
+func (k R1Key) PublicKeyString() string {
+	return "PUB_R1_" + checksumEncode(compressP256(k.X, k.Y), "R1")
+}
+
+// R1KeyAt derives a secp256r1 key at m/44'/235'/account'/change/index using SLIP-0010. The keys are unrelated to
+// the K1 keys at the same index. The seed is required, so this is not available for Hds created with
+// NewHdFromXpriv.
+func (hd Hd) R1KeyAt(index int) (*R1Key, error) {
+	if err := checkIndex(index); err != nil {
+		return nil, err
+	}
+	return hd.DeriveR1(hd.PathAt(index))
+}
+
+// DeriveR1 derives a secp256r1 key at an arbitrary path using SLIP-0010
+func (hd Hd) DeriveR1(path string) (*R1Key, error) {
+	if err := hd.canDerive(); err != nil {
+		return nil, err
+	}
+	if len(hd.seed) == 0 {
+		return nil, errors.New("Hd does not have a seed")
+	}
+	if !strings.HasPrefix(path, "m/") {
+		return nil, errors.New("derivation path must be absolute, starting with 'm/'")
+	}
+	segments, err := hdwallet.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	node := r1Master(hd.seed)
+	for _, i := range segments {
+		node = node.child(i)
+	}
+	defer wipe(node.chain)
+	curve := elliptic.P256()
+	priv := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(node.key)}
+	priv.Curve = curve
+	priv.X, priv.Y = curve.ScalarBaseMult(node.key)
+	wipe(node.key)
+	return &R1Key{priv}, nil
+}
+
+// r1Node is a SLIP-0010 extended private key on the nist256p1 curve
+type r1Node struct {
+	key   []byte
+	chain []byte
+}
+
+func r1Master(seed []byte) r1Node {
+	mac := hmac.New(sha512.New, []byte("Nist256p1 seed"))
+	_, _ = mac.Write(seed)
+	I := mac.Sum(nil)
+	n := elliptic.P256().Params().N
+	for {
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Sign() != 0 && il.Cmp(n) < 0 {
+			return r1Node{key: I[:32], chain: I[32:]}
+		}
+		mac = hmac.New(sha512.New, []byte("Nist256p1 seed"))
+		_, _ = mac.Write(I)
+		I = mac.Sum(nil)
+	}
+}
+
+func (node r1Node) child(i uint32) r1Node {
+	curve := elliptic.P256()
+	n := curve.Params().N
+	data := make([]byte, 0, 37)
+	if i >= hdkeychain.HardenedKeyStart {
+		data = append(append(data, 0), node.key...)
+	} else {
+		data = append(data, compressP256(curve.ScalarBaseMult(node.key))...)
+	}
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, i)
+	data = append(data, index...)
+	for {
+		mac := hmac.New(sha512.New, node.chain)
+		_, _ = mac.Write(data)
+		I := mac.Sum(nil)
+		il := new(big.Int).SetBytes(I[:32])
+		k := new(big.Int).Add(il, new(big.Int).SetBytes(node.key))
+		k.Mod(k, n)
+		if il.Cmp(n) < 0 && k.Sign() != 0 {
+			wipe(data)
+			return r1Node{key: padScalar(k), chain: I[32:]}
+		}
+		// invalid key, retry with 0x01 || IR || index
+		data = append(append([]byte{1}, I[32:]...), index...)
+	}
+}
+
+// compressP256 is the SEC1 compressed encoding of a point
+func compressP256(x *big.Int, y *big.Int) []byte {
+	out := make([]byte, 33)
+	out[0] = 2 + byte(y.Bit(0))
+	xb := x.Bytes()
+	copy(out[33-len(xb):], xb)
+	return out
+}
+
+// padScalar is the 32 byte big-endian encoding of a private scalar
+func padScalar(d *big.Int) []byte {
+	out := make([]byte, 32)
+	b := d.Bytes()
+	copy(out[32-len(b):], b)
+	return out
+}
@@ -0,0 +1,44 @@
+package fiox
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestHd_DeriveR1(t *testing.T) {
+	// SLIP-0010 test vector 1 for nist256p1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	node := r1Master(seed)
+	if hex.EncodeToString(node.key) != "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2" ||
+		hex.EncodeToString(node.chain) != "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea" {
+		t.Error("master node mismatch")
+	}
+	child := node.child(0x80000000)
+	if hex.EncodeToString(child.key) != "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c" ||
+		hex.EncodeToString(child.chain) != "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11" {
+		t.Error("m/0' mismatch")
+	}
+
+	hd, err := newHdFromSeed(seed)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := hd.DeriveR1("m/0'")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if hex.EncodeToString(compressP256(key.X, key.Y)) != "0384610f5ecffe8fda089363a41f56a5c7ffc1d81b59a612d0d649b2d22355590c" {
+		t.Error("m/0' public key mismatch")
+	}
+	if !strings.HasPrefix(key.String(), "PVT_R1_") || !strings.HasPrefix(key.PublicKeyString(), "PUB_R1_") {
+		t.Error("unexpected R1 string format")
+	}
+	a, _ := hd.R1KeyAt(1)
+	b, _ := hd.R1KeyAt(1)
+	if a.String() != b.String() || a.String() == key.String() {
+		t.Error("R1KeyAt is not deterministic")
+	}
+}
@@ -0,0 +1,137 @@
+package fiox
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"runtime"
+	"strings"
+)
+
+// maxRecoverUnknown limits the search to 2048^2 candidates
+const maxRecoverUnknown = 2
+
+// RecoverOptions controls Recover, all fields are optional
+type RecoverOptions struct {
+	Language   Language // detected from the known words if empty
+	Passphrase string   // BIP39 passphrase used when checking candidates with Used
+
+	// Used, if set, is called for the first Keys public keys (default 1) of each candidate, only candidates with
+	// a used key are returned. FioKeyUsed checks the chain for names and balances. Used is called concurrently.
+	Used KeyUsed
+	Keys int
+
+	Workers int // defaults to the number of CPUs
+}
+
+// Recover finds the possible mnemonics for a phrase with one or two missing or suspect words. A missing word is
+// written as "?", and a suspect word is followed by a question mark, for example "ankle?". Every word in the list
+// is tried at those positions and candidates with a valid checksum are returned in wordlist order. With a single
+// missing word many candidates will be valid, so providing opts.Used to check the chain is recommended.
+func Recover(ctx context.Context, mnemonic string, opts RecoverOptions) ([]string, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if _, err := entropyBits(len(words)); err != nil {
+		return nil, fmt.Errorf("%w, use ? for a missing word", mnemonicLengthError(len(words)))
+	}
+	unknown := make([]int, 0)
+	known := make([]string, 0)
+	for i, w := range words {
+		if strings.HasSuffix(w, "?") {
+			unknown = append(unknown, i)
+			continue
+		}
+		known = append(known, w)
+	}
+	if len(unknown) == 0 || len(unknown) > maxRecoverUnknown {
+		return nil, fmt.Errorf("between 1 and %d words must be marked with ?", maxRecoverUnknown)
+	}
+
+	language := opts.Language
+	var wl *wordList
+	var err error
+	if language == "" {
+		language, wl = bestWordList(known)
+		if language == "" {
+			language = LangEnglish
+		}
+	} else if wl, err = getWordList(language); err != nil {
+		return nil, err
+	}
+	for i, w := range words {
+		if _, ok := wl.index[w]; !ok && !strings.HasSuffix(w, "?") {
+			return nil, fmt.Errorf("%w: word %d %q, mark it with ? to recover it", ErrUnknownWord, i+1, w)
+		}
+	}
+	if opts.Workers < 1 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Keys < 1 {
+		opts.Keys = 1
+	}
+
+	// each value of the first unknown word is searched by one worker, results are kept in order
+	results := make([][]string, len(wl.words))
+	hd := Hd{workers: opts.Workers}
+	err = forRange(hd.workers, len(wl.words), func(first int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		candidate := make([]string, len(words))
+		copy(candidate, words)
+		candidate[unknown[0]] = wl.words[first]
+		seconds := []int{0}
+		if len(unknown) == 2 {
+			seconds = make([]int, len(wl.words))
+			for i := range seconds {
+				seconds[i] = i
+			}
+		}
+		for _, second := range seconds {
+			if len(unknown) == 2 {
+				candidate[unknown[1]] = wl.words[second]
+			}
+			if _, err := mnemonicToEntropy(candidate, wl); err != nil {
+				continue
+			}
+			if opts.Used != nil {
+				used, err := recoverCheck(candidate, opts)
+				if err != nil {
+					return err
+				}
+				if !used {
+					continue
+				}
+			}
+			results[first] = append(results[first], joinWords(candidate, language))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	found := make([]string, 0)
+	for _, r := range results {
+		found = append(found, r...)
+	}
+	return found, nil
+}
+
+// recoverCheck derives the first keys of a candidate and checks if any have been used
+func recoverCheck(words []string, opts RecoverOptions) (bool, error) {
+	hd, err := newHdFromSeed(mnemonicSeed(words, opts.Passphrase))
+	if err != nil {
+		return false, err
+	}
+	defer hd.Zeroize()
+	for i := 0; i < opts.Keys; i++ {
+		pub, err := hd.pubKeyAt(i)
+		if err != nil {
+			return false, err
+		}
+		used, err := opts.Used(pub)
+		if err != nil || used {
+			return used, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,43 @@
+package fiox
+
+import (
+	"context"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	const mnemonic = "crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage"
+	candidates, err := Recover(context.Background(), "crater husband angle bitter chair rally luggage ? ticket pig toe wear border aerobic wage", RecoverOptions{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	found := false
+	for _, c := range candidates {
+		if c == mnemonic {
+			found = true
+		}
+	}
+	if !found || len(candidates) > 64 {
+		t.Error("recovery did not find the mnemonic, candidates:", len(candidates))
+	}
+
+	hd, _ := NewHdFromString(mnemonic)
+	pub, _ := hd.PubKeyAt(0)
+	used := func(p *ecc.PublicKey) (bool, error) {
+		return p.String() == pub.String(), nil
+	}
+	candidates, err = Recover(context.Background(), "crater husband angle bitter chair rally luggage ? ticket pig toe wear border aerobic wage", RecoverOptions{Used: used, Workers: 8})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(candidates) != 1 || candidates[0] != mnemonic {
+		t.Error("expected only the original mnemonic, got", candidates)
+	}
+
+	if _, err = Recover(context.Background(), mnemonic, RecoverOptions{}); err == nil {
+		t.Error("allowed a mnemonic without unknown words")
+	}
+}
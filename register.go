@@ -0,0 +1,169 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"strings"
+)
+
+// payer is a key paying for FIO actions, it is shared by the helpers that send transactions
+type payer struct {
+	api    *fio.API
+	signer Signer
+	pub    string
+	actor  eos.AccountName
+	fees   *FeeCache
+
+	// TPID is the FIO address of the technology provider credited with the transactions, it may be empty
+	TPID string
+}
+
+func newPayer(api *fio.API, signer Signer, pub string, fees *FeeCache) (payer, error) {
+	if api == nil || signer == nil {
+		return payer{}, errors.New("api and signer are required")
+	}
+	actor, err := fio.ActorFromPub(pub)
+	if err != nil {
+		return payer{}, err
+	}
+	if fees == nil {
+		if fees, err = NewFeeCache(api, 0); err != nil {
+			return payer{}, err
+		}
+	}
+	return payer{api: api, signer: signer, pub: pub, actor: actor, fees: fees}, nil
+}
+
+// hdPayer pays with the key at index
+func hdPayer(hd Hd, api *fio.API, index int) (payer, error) {
+	key, err := hd.keyAt(index)
+	if err != nil {
+		return payer{}, err
+	}
+	bag := eos.NewKeyBag()
+	bag.Keys = append(bag.Keys, key)
+	signer, err := NewKeyBagSigner(bag)
+	if err != nil {
+		return payer{}, err
+	}
+	return newPayer(api, signer, key.PublicKey().String(), nil)
+}
+
+// Registrar registers and renews FIO addresses and domains, paying the fees with one key of a Signer. The fee is
+// looked up before each transaction and used as the max_fee.
+type Registrar struct {
+	payer
+}
+
+// Registration is the result of registering or renewing a FIO address or domain
+type Registration struct {
+	Name          string          `json:"name"`
+	Owner         string          `json:"owner_fio_public_key"`
+	Actor         eos.AccountName `json:"actor"`
+	Fee           uint64          `json:"fee"`
+	TransactionID string          `json:"transaction_id"`
+}
+
+// NewRegistrar pays with the key pub held by signer, fees is optional and lets several helpers share a FeeCache
+func NewRegistrar(api *fio.API, signer Signer, pub string, fees *FeeCache) (*Registrar, error) {
+	p, err := newPayer(api, signer, pub, fees)
+	if err != nil {
+		return nil, err
+	}
+	return &Registrar{payer: p}, nil
+}
+
+// Registrar creates a Registrar paying with the key at index, which is a common way to bootstrap accounts from a
+// new mnemonic
+func (hd Hd) Registrar(api *fio.API, index int) (*Registrar, error) {
+	p, err := hdPayer(hd, api, index)
+	if err != nil {
+		return nil, err
+	}
+	return &Registrar{payer: p}, nil
+}
+
+// RegisterAddress registers a FIO address such as "alice@fiotestnet" owned by the paying key
+func (r *Registrar) RegisterAddress(ctx context.Context, fioAddress string) (*Registration, error) {
+	return r.register(ctx, "regaddress", "register_fio_address", fioAddress, r.pub)
+}
+
+// RegisterAddressFor registers a FIO address for another public key, paid for by this Registrar
+func (r *Registrar) RegisterAddressFor(ctx context.Context, fioAddress string, owner string) (*Registration, error) {
+	return r.register(ctx, "regaddress", "register_fio_address", fioAddress, owner)
+}
+
+// RegisterDomain registers a FIO domain owned by the paying key
+func (r *Registrar) RegisterDomain(ctx context.Context, domain string) (*Registration, error) {
+	return r.register(ctx, "regdomain", "register_fio_domain", domain, r.pub)
+}
+
+// RenewAddress renews a FIO address for another year, anyone may pay to renew an address
+func (r *Registrar) RenewAddress(ctx context.Context, fioAddress string) (*Registration, error) {
+	return r.renew(ctx, "renewaddress", "renew_fio_address", fioAddress)
+}
+
+// RenewDomain renews a FIO domain for another year
+func (r *Registrar) RenewDomain(ctx context.Context, domain string) (*Registration, error) {
+	return r.renew(ctx, "renewdomain", "renew_fio_domain", domain)
+}
+
+func (r *Registrar) register(ctx context.Context, action string, endpoint string, name string, owner string) (*Registration, error) {
+	if owner == "" {
+		return nil, errors.New("owner is required")
+	}
+	if err := validRegistrationName(action, name); err != nil {
+		return nil, err
+	}
+	fee, err := r.fees.FeeFor(ctx, endpoint, "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, name, owner)
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	if err = writeAbiName(buf, string(r.actor)); err != nil {
+		return nil, err
+	}
+	writeAbiStrings(buf, r.TPID)
+	return r.send(ctx, action, buf.Bytes(), name, owner, fee)
+}
+
+func (r *Registrar) renew(ctx context.Context, action string, endpoint string, name string) (*Registration, error) {
+	if err := validRegistrationName(action, name); err != nil {
+		return nil, err
+	}
+	fee, err := r.fees.FeeFor(ctx, endpoint, "")
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, name)
+	_ = binary.Write(buf, binary.LittleEndian, fee)
+	writeAbiStrings(buf, r.TPID)
+	if err = writeAbiName(buf, string(r.actor)); err != nil {
+		return nil, err
+	}
+	return r.send(ctx, action, buf.Bytes(), name, "", fee)
+}
+
+func (r *Registrar) send(ctx context.Context, action string, data []byte, name string, owner string, fee uint64) (*Registration, error) {
+	result, err := sendActions(ctx, r.api, r.signer, r.pub, newAction("fio.address", action, r.actor, data))
+	if err != nil {
+		return nil, err
+	}
+	return &Registration{Name: name, Owner: owner, Actor: r.actor, Fee: fee, TransactionID: result.TransactionID}, nil
+}
+
+// validRegistrationName checks the name before a fee is spent on a transaction that would fail
+func validRegistrationName(action string, name string) error {
+	if strings.HasSuffix(action, "domain") {
+		return validFioLabel(name, MaxFioDomainLength)
+	}
+	_, _, err := SplitAddress(name)
+	return err
+}
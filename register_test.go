@@ -0,0 +1,64 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestRegistrar(t *testing.T) {
+	nodeos := newTestNodeos(nil)
+	defer nodeos.Close()
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r, err := hd.Registrar(nodeos.api(), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r.TPID = "tpid@fiotestnet"
+	pub := r.pub
+	ctx := context.Background()
+
+	reg, err := r.RegisterAddress(ctx, "alice@fiotestnet")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if reg.Name != "alice@fiotestnet" || reg.Owner != pub || reg.Fee != 40_000_000_000 || reg.TransactionID == "" {
+		t.Error("unexpected registration", reg)
+	}
+	data := nodeos.checkPushed(t, 0, pub)
+	expect := &bytes.Buffer{}
+	writeAbiStrings(expect, "alice@fiotestnet", pub)
+	_ = binary.Write(expect, binary.LittleEndian, uint64(40_000_000_000))
+	_ = writeAbiName(expect, string(reg.Actor))
+	writeAbiStrings(expect, "tpid@fiotestnet")
+	if !bytes.Equal(data[0], expect.Bytes()) {
+		t.Errorf("unexpected regaddress data %x", data[0])
+	}
+
+	if _, err = r.RegisterDomain(ctx, "alice"); err != nil {
+		t.Error(err)
+	}
+	if _, err = r.RenewAddress(ctx, "alice@fiotestnet"); err != nil {
+		t.Error(err)
+	}
+	data = nodeos.checkPushed(t, 2, pub)
+	expect.Reset()
+	writeAbiStrings(expect, "alice@fiotestnet")
+	_ = binary.Write(expect, binary.LittleEndian, uint64(40_000_000_000))
+	writeAbiStrings(expect, "tpid@fiotestnet")
+	_ = writeAbiName(expect, string(reg.Actor))
+	if !bytes.Equal(data[0], expect.Bytes()) {
+		t.Errorf("unexpected renewaddress data %x", data[0])
+	}
+	if _, err = r.RegisterAddress(ctx, "alice--@fiotestnet"); !errors.Is(err, ErrInvalidFioName) {
+		t.Error("expected ErrInvalidFioName, got", err)
+	}
+}
@@ -0,0 +1,112 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+)
+
+// ErrRotationNotVerified is returned when the updateauth transactions were accepted but get_account does not show
+// the new key
+var ErrRotationNotVerified = errors.New("account permissions do not match the new key")
+
+// RotateOptions changes what Rotate does, the zero value rotates only the active permission to the first key
+type RotateOptions struct {
+	// Index is the key of the new Hd to rotate to
+	Index int
+	// Owner also rotates the owner permission, without it the old key can still take the account back
+	Owner bool
+	// FioAddresses are re-mapped so FIO/FIO points at the new key. Tokens sent to the new key land in the account
+	// belonging to that key, not the rotated account.
+	FioAddresses []string
+}
+
+// RotateResult is what Rotate changed
+type RotateResult struct {
+	Account        string        `json:"account"`
+	PublicKey      string        `json:"public_key"`
+	Changes        []*AuthChange `json:"changes"`
+	TransactionIDs []string      `json:"transaction_ids"`
+}
+
+// Rotate moves the account of oldPub to a key derived from newHd, which is how an account is recovered after its
+// mnemonic is exposed. The FIO addresses are re-mapped first while the old key still works, then active and
+// optionally owner are changed to the new key, and get_account is checked to confirm it.
+func Rotate(ctx context.Context, api *fio.API, old Signer, oldPub string, newHd *Hd, opts RotateOptions) (*RotateResult, error) {
+	if newHd == nil {
+		return nil, errors.New("new hd cannot be nil")
+	}
+	pubs, err := newHd.PubKeysRange(opts.Index, 1)
+	if err != nil {
+		return nil, err
+	}
+	newPub := pubs[0].String()
+	if keyContent(newPub) == keyContent(oldPub) {
+		return nil, errors.New("the new key is the same as the old one")
+	}
+	fees, err := NewFeeCache(api, 0)
+	if err != nil {
+		return nil, err
+	}
+	a, err := NewAuthorizer(api, old, oldPub, fees)
+	if err != nil {
+		return nil, err
+	}
+	result := &RotateResult{Account: string(a.actor), PublicKey: newPub}
+
+	if len(opts.FioAddresses) > 0 {
+		r, err := NewRegistrar(api, old, oldPub, fees)
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range opts.FioAddresses {
+			pushed, err := r.MapAddresses(ctx, address, ChainAddress{TokenCode: "FIO", ChainCode: "FIO", PublicAddress: newPub})
+			if err != nil {
+				return result, fmt.Errorf("could not re-map %s: %w", address, err)
+			}
+			for _, p := range pushed {
+				result.TransactionIDs = append(result.TransactionIDs, p.TransactionID)
+			}
+		}
+	}
+
+	auth, err := KeyAuthority(1, newPub)
+	if err != nil {
+		return nil, err
+	}
+	permissions := []string{"active"}
+	if opts.Owner {
+		permissions = append(permissions, "owner")
+	}
+	for _, permission := range permissions {
+		change, err := a.PlanUpdateAuth(ctx, permission, "", auth)
+		if err != nil {
+			return result, err
+		}
+		pushed, err := a.UpdateAuth(ctx, change)
+		if err != nil {
+			return result, fmt.Errorf("could not update %s: %w", permission, err)
+		}
+		result.Changes = append(result.Changes, change)
+		result.TransactionIDs = append(result.TransactionIDs, pushed.TransactionID)
+	}
+
+	current, err := a.Permissions(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, permission := range permissions {
+		rotated := false
+		for _, p := range current {
+			if p.PermName == permission {
+				rotated = len(p.RequiredAuth.Keys) == 1 && len(p.RequiredAuth.Accounts) == 0 &&
+					keyContent(p.RequiredAuth.Keys[0].Key) == keyContent(newPub)
+			}
+		}
+		if !rotated {
+			return result, fmt.Errorf("%s: %w", permission, ErrRotationNotVerified)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos"
+	"testing"
+)
+
+// jsonFunc is a test response that is built when it is requested
+type jsonFunc func() interface{}
+
+func (f jsonFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f())
+}
+
+func TestRotate(t *testing.T) {
+	oldHd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newHd, err := NewHdFromString("legal winner thank year wave sausage worth useful legal winner thank yellow")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := oldHd.keyAt(0)
+	bag := eos.NewKeyBag()
+	bag.Keys = append(bag.Keys, key)
+	old, _ := NewKeyBagSigner(bag)
+	oldPub := key.PublicKey().String()
+	newPubs, _ := newHd.PubKeysRange(2, 1)
+	oldAuth, _ := KeyAuthority(1, oldPub)
+	newAuth, _ := KeyAuthority(1, newPubs[0].String())
+
+	var nodeos *testNodeos
+	nodeos = newTestNodeos(map[string]interface{}{
+		"/v1/chain/get_pub_address": map[string]string{"public_address": oldPub},
+		"/v1/chain/get_fio_names": map[string]interface{}{"fio_addresses": []map[string]interface{}{
+			{"fio_address": "alice@fiotestnet", "remaining_bundled_tx": 3},
+		}},
+		// the account shows the new key once both updateauth transactions are pushed
+		"/v1/chain/get_account": jsonFunc(func() interface{} {
+			auth := oldAuth
+			if len(nodeos.pushed) == 3 {
+				auth = newAuth
+			}
+			return AccountDetails{Permissions: []AccountPermission{
+				{PermName: "active", Parent: "owner", RequiredAuth: auth},
+				{PermName: "owner", RequiredAuth: auth},
+			}}
+		}),
+	})
+	defer nodeos.Close()
+
+	ctx := context.Background()
+	result, err := Rotate(ctx, nodeos.api(), old, oldPub, newHd, RotateOptions{Index: 2, Owner: true, FioAddresses: []string{"alice@fiotestnet"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result.PublicKey != newPubs[0].String() || len(result.Changes) != 2 || len(result.TransactionIDs) != 3 {
+		t.Error("unexpected result", result)
+	}
+	for i := range nodeos.pushed {
+		nodeos.checkPushed(t, i, oldPub)
+	}
+
+	// without owner only two transactions are pushed, so get_account still has the old key
+	nodeos.pushed = nil
+	if _, err = Rotate(ctx, nodeos.api(), old, oldPub, newHd, RotateOptions{Index: 2}); !errors.Is(err, ErrRotationNotVerified) {
+		t.Error("expected the rotation not to verify", err)
+	}
+	if _, err = Rotate(ctx, nodeos.api(), old, oldPub, oldHd, RotateOptions{}); err == nil {
+		t.Error("expected rotating to the same key to fail")
+	}
+}
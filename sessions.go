@@ -0,0 +1,202 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"sort"
+	"sync"
+)
+
+// walletSession is a wallet unlocked by this client, the password is kept so it can be unlocked again and its
+// keys listed
+type walletSession struct {
+	password string
+	keys     *keyMap
+	pubs     []string // cached by WalletPublicKeys, nil until listed
+}
+
+// sessionMap holds the wallets unlocked by a client by name, it is safe for concurrent use
+type sessionMap struct {
+	sync.RWMutex
+	byWallet map[string]*walletSession
+}
+
+func newSessionMap() *sessionMap {
+	return &sessionMap{byWallet: make(map[string]*walletSession)}
+}
+
+func (m *sessionMap) get(wallet string) (*walletSession, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.RLock()
+	defer m.RUnlock()
+	s, ok := m.byWallet[wallet]
+	return s, ok
+}
+
+// set records the password for a wallet, keeping any keys already loaded if the password is unchanged
+func (m *sessionMap) set(wallet string, password string) {
+	m.Lock()
+	defer m.Unlock()
+	if s, ok := m.byWallet[wallet]; ok && s.password == password {
+		return
+	}
+	m.byWallet[wallet] = &walletSession{password: password, keys: newKeyMap()}
+}
+
+// publicKeys provides a copy of the cached public keys of a wallet, ok is false when they have not been listed
+func (m *sessionMap) publicKeys(wallet string) (pubs []string, ok bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.RLock()
+	defer m.RUnlock()
+	s, ok := m.byWallet[wallet]
+	if !ok || s.pubs == nil {
+		return nil, false
+	}
+	return append([]string{}, s.pubs...), true
+}
+
+// setPublicKeys caches the public keys of a wallet, nil forgets them so they are listed again
+func (m *sessionMap) setPublicKeys(wallet string, pubs []string) {
+	if m == nil {
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	if s, ok := m.byWallet[wallet]; ok {
+		s.pubs = pubs
+	}
+}
+
+func (m *sessionMap) remove(wallet string) {
+	if m == nil {
+		return
+	}
+	m.Lock()
+	delete(m.byWallet, wallet)
+	m.Unlock()
+}
+
+func (m *sessionMap) clear() {
+	if m == nil {
+		return
+	}
+	m.Lock()
+	m.byWallet = make(map[string]*walletSession)
+	m.Unlock()
+}
+
+// passwords provides a copy of the wallet names and passwords
+func (m *sessionMap) passwords() map[string]string {
+	out := make(map[string]string)
+	if m == nil {
+		return out
+	}
+	m.RLock()
+	defer m.RUnlock()
+	for name, s := range m.byWallet {
+		out[name] = s.password
+	}
+	return out
+}
+
+// Sessions lists the wallets unlocked with this client, sorted by name. Lock and LockAll end the sessions.
+func (k *KeosClient) Sessions() []string {
+	names := make([]string, 0)
+	for name := range k.sessions.passwords() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetWalletKeys loads the keys of one wallet, which should have been unlocked by this client. The keys are available
+// from WalletKeyList and are also merged into the keys for KeyByActor and the other accessors.
+func (k *KeosClient) GetWalletKeys(ctx context.Context, wallet string, nodeosApi *fio.API) error {
+	// without a session keosd will reject the empty password, which is the clearest error
+	session, ok := k.sessions.get(wallet)
+	password := ""
+	if ok {
+		password = session.password
+	}
+	pubKeys := make([][]string, 0)
+	err := k.call(ctx, "/v1/wallet/list_keys", []string{wallet, password}, &pubKeys)
+	if err != nil {
+		if errors.As(err, new(*KeosError)) || ctx.Err() != nil {
+			return err
+		}
+		return errors.New("could not connect to keosd, is the wallet unlocked?\n" + err.Error())
+	}
+	keys, err := k.populate(ctx, pubKeys, nodeosApi)
+	if err != nil {
+		return err
+	}
+	if k.keys == nil {
+		k.keys = newKeyMap()
+	}
+	for _, key := range keys {
+		if session != nil {
+			session.keys.set(key)
+		}
+		k.keys.set(key)
+	}
+	return nil
+}
+
+// WalletKeyList provides the keys loaded from one wallet by GetWalletKeys or GetKeys, without the private keys
+func (k *KeosClient) WalletKeyList(wallet string) []KeosKeys {
+	session, ok := k.sessions.get(wallet)
+	if !ok {
+		return nil
+	}
+	keys := session.keys.list()
+	for i := range keys {
+		keys[i].PrivateKey = ""
+	}
+	return keys
+}
+
+// WalletPublicKeys asks keosd for the public keys in one wallet, unlike GetPublicKeys which covers every unlocked
+// wallet. The wallet must have been unlocked by this client. keosd can only list one wallet's keys along with the
+// private keys, so the public keys are cached for the session and only listed again after this client changes the
+// wallet's keys.
+func (k *KeosClient) WalletPublicKeys(ctx context.Context, wallet string) ([]string, error) {
+	session, ok := k.sessions.get(wallet)
+	if !ok {
+		return nil, fmt.Errorf("wallet %s has not been unlocked by this client", wallet)
+	}
+	if pubs, ok := k.sessions.publicKeys(wallet); ok {
+		return pubs, nil
+	}
+	pubKeys := make([][]string, 0)
+	if err := k.call(ctx, "/v1/wallet/list_keys", []string{wallet, session.password}, &pubKeys); err != nil {
+		return nil, err
+	}
+	pubs := make([]string, len(pubKeys))
+	for i := range pubKeys {
+		pubs[i] = pubKeys[i][0]
+	}
+	k.sessions.setPublicKeys(wallet, pubs)
+	return append([]string{}, pubs...), nil
+}
+
+// reunlock unlocks every session wallet that keosd reports as locked
+func (k *KeosClient) reunlock(ctx context.Context) error {
+	sessions := k.sessions.passwords()
+	if len(sessions) == 0 {
+		return errors.New("no wallets have been unlocked by this client")
+	}
+	var err error
+	for wallet, password := range sessions {
+		e := k.post(ctx, "/v1/wallet/unlock", []string{wallet, password}, nil)
+		if e != nil && !errors.Is(e, ErrWalletUnlocked) {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package fiox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+)
+
+// Signer is implemented by every signing backend: KeosClient, LocalWallet, and KeyBagSigner, so code that signs
+// does not need to know where the keys are held
+type Signer interface {
+	PublicKeys(ctx context.Context) ([]string, error)
+	SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error)
+	SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error)
+}
+
+// PublicKeys lists the public keys in the unlocked wallets, it is the same as GetPublicKeys
+func (k *KeosClient) PublicKeys(ctx context.Context) ([]string, error) {
+	return k.GetPublicKeys(ctx)
+}
+
+// PublicKeys lists the public keys in the unlocked wallets, it is the same as GetPublicKeys
+func (lw *LocalWallet) PublicKeys(ctx context.Context) ([]string, error) {
+	return lw.GetPublicKeys(ctx)
+}
+
+// KeyBagSigner is a Signer using keys held in memory, such as those derived from an Hd
+type KeyBagSigner struct {
+	bag *eos.KeyBag
+}
+
+// NewKeyBagSigner creates a Signer from the keys in bag
+func NewKeyBagSigner(bag *eos.KeyBag) (*KeyBagSigner, error) {
+	if bag == nil || len(bag.Keys) == 0 {
+		return nil, errors.New("the key bag has no keys")
+	}
+	return &KeyBagSigner{bag: bag}, nil
+}
+
+// Signer creates a KeyBagSigner holding the first count keys
+func (hd Hd) Signer(count int) (*KeyBagSigner, error) {
+	bag, err := hd.Keys(count)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyBagSigner(bag)
+}
+
+// PublicKeys lists the public keys of the signer
+func (s *KeyBagSigner) PublicKeys(ctx context.Context) ([]string, error) {
+	pubs := make([]string, len(s.bag.Keys))
+	for i, k := range s.bag.Keys {
+		pubs[i] = k.PublicKey().String()
+	}
+	return pubs, nil
+}
+
+// SignDigest signs a 32 byte digest with the key for pub
+func (s *KeyBagSigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	if len(digest) != 32 {
+		return ecc.Signature{}, errors.New("digest must be 32 bytes")
+	}
+	target, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	for _, k := range s.bag.Keys {
+		if samePublicKey(k.PublicKey(), target) {
+			return k.Sign(digest)
+		}
+	}
+	return ecc.Signature{}, ErrWalletMissingKey
+}
+
+// SignTransaction signs a transaction with each of pubkeys, accepting the same transactions as
+// KeosClient.SignTransaction
+func (s *KeyBagSigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	return signTransaction(ctx, s, tx, pubkeys, chainID)
+}
+
+// signTransaction packs a JSON transaction and signs its digest with each key, for signers that hold the keys
+// themselves rather than passing the transaction to keosd
+func signTransaction(ctx context.Context, s Signer, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	if tx == nil {
+		return nil, errors.New("transaction cannot be nil")
+	}
+	if len(pubkeys) == 0 {
+		return nil, errors.New("at least one public key is required")
+	}
+	id, err := hex.DecodeString(chainID)
+	if err != nil || len(id) != 32 {
+		return nil, errors.New("chain ID must be 32 bytes of hex")
+	}
+	trx, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := PackTransactionJSON(trx)
+	if err != nil {
+		return nil, err
+	}
+	digest := TransactionDigest(id, packed, nil)
+	sigs := make([]ecc.Signature, len(pubkeys))
+	for i, pub := range pubkeys {
+		if sigs[i], err = s.SignDigest(ctx, digest, pub); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
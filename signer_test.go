@@ -0,0 +1,54 @@
+package fiox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+var (
+	_ Signer = &KeosClient{}
+	_ Signer = &LocalWallet{}
+	_ Signer = &KeyBagSigner{}
+)
+
+func TestKeyBagSigner(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var s Signer
+	if s, err = hd.Signer(2); err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	pubs, err := s.PublicKeys(ctx)
+	if err != nil || len(pubs) != 2 {
+		t.Error("expected 2 public keys", pubs, err)
+		return
+	}
+	trx := json.RawMessage(`{"expiration":"2020-10-01T00:00:00","ref_block_num":1,"ref_block_prefix":2,"actions":[{"account":"eosio","name":"regproducer","authorization":[],"data":"0102ff"}]}`)
+	sigs, err := s.SignTransaction(ctx, trx, pubs, FioTestnetChainID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	packed, _ := PackTransactionJSON(trx)
+	chainID, _ := hex.DecodeString(FioTestnetChainID)
+	digest := TransactionDigest(chainID, packed, nil)
+	for i := range pubs {
+		pub, _ := ecc.NewPublicKey(pubs[i])
+		if !sigs[i].Verify(digest, pub) {
+			t.Error("signature did not verify for", pubs[i])
+		}
+	}
+	other, _ := ecc.NewRandomPrivateKey()
+	if _, err = s.SignDigest(ctx, digest, other.PublicKey().String()); !errors.Is(err, ErrWalletMissingKey) {
+		t.Error("expected ErrWalletMissingKey, got", err)
+	}
+}
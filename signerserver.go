@@ -0,0 +1,362 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SignerService is the gRPC service name served by SignerServer
+const SignerService = "fiox.Signer"
+
+// MaxDeriveKeys limits how many keys one DeriveKeys call can derive
+const MaxDeriveKeys = 1000
+
+// SignerServer exposes a Signer as the fiox.Signer gRPC service, so several services can share one process holding
+// the keys. With an Hd it also derives public keys. It is an http.Handler and needs an HTTP/2 TLS server, use
+// TLSConfig.ServerConfig with a CA file to require client certificates.
+type SignerServer struct {
+	signer Signer
+	hd     *Hd
+}
+
+// NewSignerServer serves signer, hd is optional and enables DeriveKeys
+func NewSignerServer(signer Signer, hd *Hd) (*SignerServer, error) {
+	if signer == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	return &SignerServer{signer: signer, hd: hd}, nil
+}
+
+// ServeHTTP handles a unary gRPC call
+func (s *SignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "expected a grpc request", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	resp, code, err := s.handle(r)
+	w.WriteHeader(http.StatusOK)
+	if err == nil {
+		_, _ = w.Write(grpcFrame(resp))
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if err != nil {
+		w.Header().Set("Grpc-Message", url.PathEscape(err.Error()))
+	}
+}
+
+func (s *SignerServer) handle(r *http.Request) ([]byte, int, error) {
+	body, err := readLimited(r.Body, DefaultMaxResponseSize)
+	if err != nil {
+		return nil, grpcInvalidArgument, err
+	}
+	msg, err := grpcUnframe(body)
+	if err != nil {
+		return nil, grpcInvalidArgument, err
+	}
+	ctx := r.Context()
+	resp := &protoMessage{}
+	switch r.URL.Path {
+	case "/" + SignerService + "/PublicKeys":
+		pubs, err := s.signer.PublicKeys(ctx)
+		if err != nil {
+			return nil, grpcStatusCode(err), err
+		}
+		resp.strings(1, pubs)
+	case "/" + SignerService + "/DeriveKeys":
+		return s.deriveKeys(msg)
+	case "/" + SignerService + "/SignDigest":
+		var digest []byte
+		var pub string
+		err = protoFields(msg, func(field int, v uint64, data []byte) error {
+			switch field {
+			case 1:
+				digest = data
+			case 2:
+				pub = string(data)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, grpcInvalidArgument, err
+		}
+		sig, err := s.signer.SignDigest(ctx, digest, pub)
+		if err != nil {
+			return nil, grpcStatusCode(err), err
+		}
+		resp.strings(1, []string{sig.String()})
+	case "/" + SignerService + "/SignTransaction":
+		var trx json.RawMessage
+		var pubs []string
+		var chainID string
+		err = protoFields(msg, func(field int, v uint64, data []byte) error {
+			switch field {
+			case 1:
+				trx = data
+			case 2:
+				pubs = append(pubs, string(data))
+			case 3:
+				chainID = string(data)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, grpcInvalidArgument, err
+		}
+		if !json.Valid(trx) {
+			return nil, grpcInvalidArgument, errors.New("transaction must be JSON")
+		}
+		sigs, err := s.signer.SignTransaction(ctx, trx, pubs, chainID)
+		if err != nil {
+			return nil, grpcStatusCode(err), err
+		}
+		for _, sig := range sigs {
+			resp.strings(1, []string{sig.String()})
+		}
+	default:
+		return nil, grpcUnimplemented, fmt.Errorf("unknown method %s", r.URL.Path)
+	}
+	return resp.Bytes(), grpcOK, nil
+}
+
+func (s *SignerServer) deriveKeys(msg []byte) ([]byte, int, error) {
+	if s.hd == nil {
+		return nil, grpcFailedCondition, errors.New("key derivation is not enabled")
+	}
+	var start, count uint64
+	err := protoFields(msg, func(field int, v uint64, data []byte) error {
+		switch field {
+		case 1:
+			start = v
+		case 2:
+			count = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, grpcInvalidArgument, err
+	}
+	keys, err := derivePublicKeys(s.hd, start, count)
+	if err != nil {
+		return nil, grpcInvalidArgument, err
+	}
+	resp := &protoMessage{}
+	for _, k := range keys {
+		key := &protoMessage{}
+		key.uint(1, uint64(k.Index))
+		key.string(2, k.Path)
+		key.string(3, string(k.Actor))
+		key.string(4, k.PublicKey.String())
+		resp.key(1, protoBytes)
+		resp.varint(uint64(key.Len()))
+		resp.Write(key.Bytes())
+	}
+	return resp.Bytes(), grpcOK, nil
+}
+
+// derivePublicKeys derives a range of keys for a signing service, leaving out the private keys
+func derivePublicKeys(hd *Hd, start uint64, count uint64) ([]DerivedKey, error) {
+	if count == 0 || count > MaxDeriveKeys {
+		return nil, fmt.Errorf("count must be between 1 and %d", MaxDeriveKeys)
+	}
+	if start >= 1<<31 || start+count > 1<<31 {
+		return nil, errors.New("keys must be below index 2^31, higher indexes are hardened")
+	}
+	ks, err := hd.KeySet(int(start), int(count))
+	if err != nil {
+		return nil, err
+	}
+	for i := range ks.Keys {
+		ks.Keys[i].PrivateKey = nil
+	}
+	return ks.Keys, nil
+}
+
+// grpcStatusCode maps signer errors to a status code
+func grpcStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrWalletMissingKey):
+		return grpcNotFound
+	case errors.Is(err, ErrPolicyDenied):
+		return grpcPermissionDenied
+	}
+	return grpcInternal
+}
+
+// RemoteSigner is a Signer using the fiox.Signer gRPC service of a SignerServer, such as cmd/fioxd
+type RemoteSigner struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemoteSigner connects to the signing service at address (host:port), tlsConfig usually holds a client
+// certificate and the CA of the server, see TLSConfig.ClientConfig
+func NewRemoteSigner(address string, tlsConfig *tls.Config) (*RemoteSigner, error) {
+	if address == "" {
+		return nil, errors.New("address is required")
+	}
+	if tlsConfig == nil {
+		return nil, errors.New("the signing service requires tls")
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConfig.Clone(), ForceAttemptHTTP2: true}
+	return &RemoteSigner{
+		url:    "https://" + strings.TrimSuffix(strings.TrimPrefix(address, "https://"), "/"),
+		client: &http.Client{Transport: transport},
+	}, nil
+}
+
+// PublicKeys lists the public keys the service can sign with
+func (rs *RemoteSigner) PublicKeys(ctx context.Context) ([]string, error) {
+	msg, err := rs.invoke(ctx, "PublicKeys", &protoMessage{})
+	if err != nil {
+		return nil, err
+	}
+	return protoStrings(msg)
+}
+
+// DeriveKeys derives count public keys starting at index start, the service must have been given an Hd
+func (rs *RemoteSigner) DeriveKeys(ctx context.Context, start int, count int) ([]DerivedKey, error) {
+	if start < 0 || count < 1 {
+		return nil, errors.New("invalid key range")
+	}
+	req := &protoMessage{}
+	req.uint(1, uint64(start))
+	req.uint(2, uint64(count))
+	msg, err := rs.invoke(ctx, "DeriveKeys", req)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]DerivedKey, 0, count)
+	err = protoFields(msg, func(field int, v uint64, data []byte) error {
+		if field != 1 {
+			return nil
+		}
+		dk := DerivedKey{}
+		var pub string
+		err := protoFields(data, func(field int, v uint64, data []byte) error {
+			switch field {
+			case 1:
+				dk.Index = int(v)
+			case 2:
+				dk.Path = string(data)
+			case 3:
+				dk.Actor = eos.AccountName(data)
+			case 4:
+				pub = string(data)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		key, err := ecc.NewPublicKey(pub)
+		if err != nil {
+			return err
+		}
+		dk.PublicKey = &key
+		keys = append(keys, dk)
+		return nil
+	})
+	return keys, err
+}
+
+// SignDigest has the service sign a 32 byte digest with the key for pub
+func (rs *RemoteSigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	req := &protoMessage{}
+	req.bytes(1, digest)
+	req.string(2, pub)
+	sigs, err := rs.signatures(ctx, "SignDigest", req, 1)
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	return sigs[0], nil
+}
+
+// SignTransaction has the service sign a transaction with each of pubkeys, accepting the same transactions as
+// KeosClient.SignTransaction
+func (rs *RemoteSigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	if tx == nil {
+		return nil, errors.New("transaction cannot be nil")
+	}
+	trx, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	req := &protoMessage{}
+	req.bytes(1, trx)
+	req.strings(2, pubkeys)
+	req.string(3, chainID)
+	return rs.signatures(ctx, "SignTransaction", req, len(pubkeys))
+}
+
+func (rs *RemoteSigner) signatures(ctx context.Context, method string, req *protoMessage, expect int) ([]ecc.Signature, error) {
+	msg, err := rs.invoke(ctx, method, req)
+	if err != nil {
+		return nil, err
+	}
+	values, err := protoStrings(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != expect {
+		return nil, fmt.Errorf("expected %d signatures, got %d", expect, len(values))
+	}
+	sigs := make([]ecc.Signature, len(values))
+	for i, v := range values {
+		if sigs[i], err = ecc.NewSignature(v); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
+
+// invoke makes a unary call and provides the response message
+func (rs *RemoteSigner) invoke(ctx context.Context, method string, req *protoMessage) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, rs.url+"/"+SignerService+"/"+method,
+		bytes.NewReader(grpcFrame(req.Bytes())))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("Te", "trailers")
+	resp, err := rs.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := readLimited(resp.Body, DefaultMaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signing service returned %d", resp.StatusCode)
+	}
+	if err = grpcStatus(resp.Trailer, resp.Header); err != nil {
+		return nil, err
+	}
+	return grpcUnframe(body)
+}
+
+// protoStrings reads a message holding only repeated field 1 strings
+func protoStrings(msg []byte) ([]string, error) {
+	values := make([]string, 0)
+	err := protoFields(msg, func(field int, v uint64, data []byte) error {
+		if field == 1 {
+			values = append(values, string(data))
+		}
+		return nil
+	})
+	return values, err
+}
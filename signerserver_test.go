@@ -0,0 +1,133 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testCertificate creates a certificate signed by parent, or a self signed CA when parent is nil
+func testCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	issuer, signer := template, interface{}(key)
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestSignerServer(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	signer, err := hd.Signer(2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	handler, err := NewSignerServer(signer, hd)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ca := testCertificate(t, "ca", nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, "server", &ca)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	client := testCertificate(t, "client", &ca)
+	rs, err := NewRemoteSigner(server.Listener.Addr().String(), &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{client}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ctx := context.Background()
+	pubs, err := rs.PublicKeys(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	local, _ := signer.PublicKeys(ctx)
+	if len(pubs) != 2 || pubs[0] != local[0] || pubs[1] != local[1] {
+		t.Error("unexpected public keys", pubs)
+	}
+
+	derived, err := rs.DeriveKeys(ctx, 1, 3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(derived) != 3 || derived[0].Index != 1 || derived[0].PublicKey.String() != local[1] || derived[0].PrivateKey != nil {
+		t.Error("unexpected derived keys", derived)
+	}
+	if _, err = rs.DeriveKeys(ctx, 1<<31-1, 2); err == nil {
+		t.Error("expected a range reaching hardened indexes to be refused")
+	}
+
+	digest := bytes.Repeat([]byte{3}, 32)
+	sig, err := rs.SignDigest(ctx, digest, pubs[1])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !sig.Verify(digest, *derived[0].PublicKey) {
+		t.Error("signature did not verify")
+	}
+	sigs, err := rs.SignTransaction(ctx, transferTx(pubs[1], 1), pubs, FioMainnetChainID)
+	if err != nil || len(sigs) != 2 {
+		t.Error("expected two signatures", sigs, err)
+	}
+	if _, err = rs.SignDigest(ctx, digest, "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"); !errors.Is(err, ErrWalletMissingKey) {
+		t.Error("expected ErrWalletMissingKey", err)
+	}
+
+	// without a client certificate the connection is refused
+	rs, _ = NewRemoteSigner(server.Listener.Addr().String(), &tls.Config{RootCAs: pool})
+	if _, err = rs.PublicKeys(ctx); err == nil {
+		t.Error("expected the server to require a client certificate")
+	}
+}
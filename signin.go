@@ -0,0 +1,118 @@
+package fiox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrChallengeExpired is returned when a sign-in response arrives after the challenge expired
+	ErrChallengeExpired = errors.New("sign-in challenge has expired")
+	// ErrHandleNotBound is returned when the signing key does not own the FIO handle
+	ErrHandleNotBound = errors.New("public key is not bound to the FIO handle")
+)
+
+// Challenge is a sign-in-with-FIO request issued by a site. The site keeps the challenge (or at least the nonce) and
+// verifies the wallet's signature against it, so a response can only be used once.
+type Challenge struct {
+	Domain     string    `json:"domain"`
+	FioAddress string    `json:"fio_address"`
+	Nonce      string    `json:"nonce"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewChallenge creates a challenge for a FIO handle with a random nonce, valid for ttl
+func NewChallenge(domain string, fioAddress string, ttl time.Duration) (*Challenge, error) {
+	if domain == "" || !strings.Contains(fioAddress, "@") {
+		return nil, errors.New("a domain and a FIO handle are required")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	return &Challenge{
+		Domain:     domain,
+		FioAddress: fioAddress,
+		Nonce:      hex.EncodeToString(nonce),
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(ttl),
+	}, nil
+}
+
+// Message is the human readable text that is signed, wallets should show it to the user before signing
+func (c Challenge) Message() []byte {
+	return []byte(fmt.Sprintf("%s wants you to sign in with your FIO handle:\n%s\n\nNonce: %s\nIssued At: %s\nExpiration Time: %s",
+		c.Domain, c.FioAddress, c.Nonce, c.IssuedAt.UTC().Format(time.RFC3339), c.ExpiresAt.UTC().Format(time.RFC3339)))
+}
+
+// Sign signs the challenge message, returning a SIG_K1_ signature
+func (c Challenge) Sign(key *ecc.PrivateKey) (string, error) {
+	return SignMessage(key, c.Message())
+}
+
+// SignChallenge signs a challenge with the key at index
+func (hd Hd) SignChallenge(index int, c Challenge) (string, error) {
+	return hd.SignMessageAt(index, c.Message())
+}
+
+// SignChallenge signs a challenge with a key held by keosd
+func (k *KeosClient) SignChallenge(ctx context.Context, pub string, c Challenge) (string, error) {
+	return k.SignMessage(ctx, pub, c.Message())
+}
+
+// HandleBound reports whether a public key owns a FIO handle, see FioHandleBound
+type HandleBound func(fioAddress string, pub *ecc.PublicKey) (bool, error)
+
+// FioHandleBound provides a HandleBound that checks the FIO names registered to the key on chain
+func FioHandleBound(api *fio.API) HandleBound {
+	return func(fioAddress string, pub *ecc.PublicKey) (bool, error) {
+		names, found, err := api.GetFioNames(pub.String())
+		if err != nil || !found {
+			return false, err
+		}
+		for _, name := range names.FioAddresses {
+			if strings.EqualFold(name.FioAddress, fioAddress) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Verify checks a sign-in response: the challenge has not expired, the signature was made by pub, and bound
+// confirms pub owns the handle. It returns the public key on success.
+func (c Challenge) Verify(bound HandleBound, pub string, signature string) (*ecc.PublicKey, error) {
+	if bound == nil {
+		return nil, errors.New("bound cannot be nil")
+	}
+	if time.Now().After(c.ExpiresAt) {
+		return nil, ErrChallengeExpired
+	}
+	key, err := ecc.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if err = VerifyMessage(&key, c.Message(), signature); err != nil {
+		return nil, err
+	}
+	ok, err := bound(c.FioAddress, &key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrHandleNotBound
+	}
+	return &key, nil
+}
@@ -0,0 +1,52 @@
+package fiox
+
+import (
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+	"time"
+)
+
+func TestChallenge(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pub, _ := hd.PubKeyAt(0)
+	bound := func(fioAddress string, key *ecc.PublicKey) (bool, error) {
+		return fioAddress == "alice@fiotestnet" && key.String() == pub.String(), nil
+	}
+
+	c, err := NewChallenge("example.com", "alice@fiotestnet", time.Minute)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sig, err := hd.SignChallenge(0, *c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = c.Verify(bound, pub.String(), sig); err != nil {
+		t.Error(err)
+	}
+
+	other, _ := hd.PubKeyAt(1)
+	otherSig, _ := hd.SignChallenge(1, *c)
+	if _, err = c.Verify(bound, other.String(), otherSig); err != ErrHandleNotBound {
+		t.Error("expected the handle not to be bound, got", err)
+	}
+	if _, err = c.Verify(bound, other.String(), sig); err != ErrBadSignature {
+		t.Error("expected a bad signature, got", err)
+	}
+
+	replay, _ := NewChallenge("example.com", "alice@fiotestnet", time.Minute)
+	if _, err = replay.Verify(bound, pub.String(), sig); err != ErrBadSignature {
+		t.Error("expected a signature for another nonce to fail, got", err)
+	}
+
+	c.ExpiresAt = time.Now().Add(-time.Second)
+	if _, err = c.Verify(bound, pub.String(), sig); err != ErrChallengeExpired {
+		t.Error("expected an expired challenge, got", err)
+	}
+}
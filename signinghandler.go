@@ -0,0 +1,190 @@
+package fiox
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// SigningHandler is a JSON over HTTP alternative to SignerServer, meant to sit behind existing HTTP infrastructure.
+// Every request needs an "Authorization: Bearer <token>" header with one of the handler's tokens. The endpoints are:
+//
+//	GET  /v1/public_keys       {"public_keys": [...]}
+//	POST /v1/derive            {"start": 0, "count": 10} => {"keys": [...]}, needs an Hd
+//	POST /v1/sign_digest       {"digest": "<hex>", "public_key": "FIO..."} => {"signature": "SIG_K1_..."}
+//	POST /v1/sign_transaction  {"transaction": {...}, "public_keys": [...], "chain_id": "<hex>"} => {"signatures": [...]}
+//
+// Errors are returned as {"error": "..."}.
+type SigningHandler struct {
+	signer Signer
+	hd     *Hd
+	tokens [][sha256.Size]byte
+	mux    *http.ServeMux
+}
+
+// NewSigningHandler serves signer to callers holding one of tokens, hd is optional and enables /v1/derive
+func NewSigningHandler(signer Signer, hd *Hd, tokens ...string) (*SigningHandler, error) {
+	if signer == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("at least one token is required")
+	}
+	h := &SigningHandler{signer: signer, hd: hd, mux: http.NewServeMux()}
+	for _, t := range tokens {
+		if len(t) < 16 {
+			return nil, errors.New("tokens must be at least 16 characters")
+		}
+		// hashed so the comparison takes the same time whatever the length
+		h.tokens = append(h.tokens, sha256.Sum256([]byte(t)))
+	}
+	h.mux.HandleFunc("/v1/public_keys", h.publicKeys)
+	h.mux.HandleFunc("/v1/derive", h.derive)
+	h.mux.HandleFunc("/v1/sign_digest", h.signDigest)
+	h.mux.HandleFunc("/v1/sign_transaction", h.signTransaction)
+	return h, nil
+}
+
+// ServeHTTP checks the token and routes the request
+func (h *SigningHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSONError(w, http.StatusUnauthorized, errors.New("a valid bearer token is required"))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *SigningHandler) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	presented := sha256.Sum256([]byte(strings.TrimPrefix(auth, "Bearer ")))
+	ok := 0
+	for _, t := range h.tokens {
+		ok |= subtle.ConstantTimeCompare(presented[:], t[:])
+	}
+	return ok == 1
+}
+
+func (h *SigningHandler) publicKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	pubs, err := h.signer.PublicKeys(r.Context())
+	if err != nil {
+		writeJSONError(w, signerHTTPStatus(err), err)
+		return
+	}
+	writeJSON(w, map[string][]string{"public_keys": pubs})
+}
+
+func (h *SigningHandler) derive(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Start uint64 `json:"start"`
+		Count uint64 `json:"count"`
+	}{}
+	if !readJSONRequest(w, r, &req) {
+		return
+	}
+	if h.hd == nil {
+		writeJSONError(w, http.StatusNotImplemented, errors.New("key derivation is not enabled"))
+		return
+	}
+	keys, err := derivePublicKeys(h.hd, req.Start, req.Count)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, KeySet{Keys: keys})
+}
+
+func (h *SigningHandler) signDigest(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Digest    string `json:"digest"`
+		PublicKey string `json:"public_key"`
+	}{}
+	if !readJSONRequest(w, r, &req) {
+		return
+	}
+	digest, err := hex.DecodeString(req.Digest)
+	if err != nil || len(digest) != 32 {
+		writeJSONError(w, http.StatusBadRequest, errors.New("digest must be 32 bytes of hex"))
+		return
+	}
+	sig, err := h.signer.SignDigest(r.Context(), digest, req.PublicKey)
+	if err != nil {
+		writeJSONError(w, signerHTTPStatus(err), err)
+		return
+	}
+	writeJSON(w, map[string]string{"signature": sig.String()})
+}
+
+func (h *SigningHandler) signTransaction(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Transaction json.RawMessage `json:"transaction"`
+		PublicKeys  []string        `json:"public_keys"`
+		ChainID     string          `json:"chain_id"`
+	}{}
+	if !readJSONRequest(w, r, &req) {
+		return
+	}
+	if len(req.Transaction) == 0 {
+		writeJSONError(w, http.StatusBadRequest, errors.New("transaction is required"))
+		return
+	}
+	sigs, err := h.signer.SignTransaction(r.Context(), req.Transaction, req.PublicKeys, req.ChainID)
+	if err != nil {
+		writeJSONError(w, signerHTTPStatus(err), err)
+		return
+	}
+	values := make([]string, len(sigs))
+	for i, sig := range sigs {
+		values[i] = sig.String()
+	}
+	writeJSON(w, map[string][]string{"signatures": values})
+}
+
+// signerHTTPStatus maps signer errors to a status code, the same way grpcStatusCode does for SignerServer
+func signerHTTPStatus(err error) int {
+	switch grpcStatusCode(err) {
+	case grpcNotFound:
+		return http.StatusNotFound
+	case grpcPermissionDenied:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func readJSONRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return false
+	}
+	body, err := readLimited(r.Body, DefaultMaxResponseSize)
+	if err == nil {
+		err = json.Unmarshal(body, v)
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
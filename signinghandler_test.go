@@ -0,0 +1,97 @@
+package fiox
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSigningHandler(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	signer, _ := hd.Signer(1)
+	if _, err = NewSigningHandler(signer, hd); err == nil {
+		t.Error("expected a token to be required")
+	}
+	token := "0123456789abcdef0123"
+	handler, err := NewSigningHandler(signer, hd, token)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	call := func(method string, path string, auth string, body interface{}, result interface{}) int {
+		b, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(b))
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if result != nil {
+			_ = json.NewDecoder(resp.Body).Decode(result)
+		}
+		return resp.StatusCode
+	}
+
+	if status := call(http.MethodGet, "/v1/public_keys", "", nil, nil); status != http.StatusUnauthorized {
+		t.Error("expected 401 without a token, got", status)
+	}
+	if status := call(http.MethodGet, "/v1/public_keys", "not the token at all", nil, nil); status != http.StatusUnauthorized {
+		t.Error("expected 401 with the wrong token, got", status)
+	}
+	pubs := struct {
+		PublicKeys []string `json:"public_keys"`
+	}{}
+	if status := call(http.MethodGet, "/v1/public_keys", token, nil, &pubs); status != http.StatusOK || len(pubs.PublicKeys) != 1 {
+		t.Error("unexpected public keys", status, pubs)
+		return
+	}
+
+	derived := KeySet{}
+	if status := call(http.MethodPost, "/v1/derive", token, map[string]int{"start": 0, "count": 2}, &derived); status != http.StatusOK {
+		t.Error("derive failed", status)
+	}
+	if len(derived.Keys) != 2 || derived.Keys[0].PublicKey.String() != pubs.PublicKeys[0] || derived.Keys[0].PrivateKey != nil {
+		t.Error("unexpected derived keys", derived)
+	}
+
+	digest := bytes.Repeat([]byte{5}, 32)
+	signed := struct {
+		Signature string `json:"signature"`
+	}{}
+	body := map[string]string{"digest": hex.EncodeToString(digest), "public_key": pubs.PublicKeys[0]}
+	if status := call(http.MethodPost, "/v1/sign_digest", token, body, &signed); status != http.StatusOK {
+		t.Error("sign_digest failed", status)
+	}
+	if signed.Signature == "" {
+		t.Error("missing signature")
+	}
+	body["public_key"] = "FIO6cDpi7vPnvRwMEdXtLnAmFwygaQ8CzD7vqKLBJ2GfgtHBQ4PPy"
+	if status := call(http.MethodPost, "/v1/sign_digest", token, body, nil); status != http.StatusNotFound {
+		t.Error("expected 404 for a missing key, got", status)
+	}
+
+	sigs := struct {
+		Signatures []string `json:"signatures"`
+	}{}
+	txBody := map[string]interface{}{
+		"transaction": transferTx(pubs.PublicKeys[0], 1),
+		"public_keys": pubs.PublicKeys,
+		"chain_id":    FioMainnetChainID,
+	}
+	if status := call(http.MethodPost, "/v1/sign_transaction", token, txBody, &sigs); status != http.StatusOK || len(sigs.Signatures) != 1 {
+		t.Error("sign_transaction failed", status, sigs)
+	}
+}
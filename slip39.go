@@ -0,0 +1,486 @@
+package fiox
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/pbkdf2"
+	"math/big"
+	"strings"
+)
+
+// Slip39Group describes a group of SLIP-0039 shares, MemberThreshold of the MemberCount shares are needed to
+// recover the group.
+type Slip39Group struct {
+	MemberThreshold int
+	MemberCount     int
+}
+
+const (
+	slip39RadixBits      = 10
+	slip39MetadataWords  = 7 // two for the identifier/exponent, two for the share parameters, and three checksum
+	slip39MinWords       = 20
+	slip39MaxShares      = 16
+	slip39DigestIndex    = 254
+	slip39SecretIndex    = 255
+	slip39DigestLength   = 4
+	slip39BaseIterations = 10000
+	slip39Rounds         = 4
+	slip39IterationExp   = 1
+)
+
+// slip39Share is a single decoded share
+type slip39Share struct {
+	identifier        uint16
+	extendable        bool
+	iterationExponent uint8
+	groupIndex        int
+	groupThreshold    int
+	groupCount        int
+	memberIndex       int
+	memberThreshold   int
+	value             []byte
+}
+
+// rawShare is a point used for interpolation
+type rawShare struct {
+	x     byte
+	value []byte
+}
+
+// Slip39 splits the seed into SLIP-0039 shares using a single group: threshold of the count shares are needed to
+// recover it using NewHdFromSlip39. The passphrase may be empty, if set it will be needed for recovery.
+func (hd Hd) Slip39(threshold int, count int, passphrase string) ([]string, error) {
+	groups, err := hd.Slip39Groups(1, []Slip39Group{{MemberThreshold: threshold, MemberCount: count}}, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return groups[0], nil
+}
+
+// Slip39Groups splits the seed into SLIP-0039 shares organized in groups, shares from groupThreshold groups are
+// needed for recovery. The result holds the shares for each group in the same order as groups.
+func (hd Hd) Slip39Groups(groupThreshold int, groups []Slip39Group, passphrase string) ([][]string, error) {
+	if hd.zeroized() {
+		return nil, ErrZeroized
+	}
+	if len(hd.seed) == 0 {
+		return nil, errors.New("Hd does not have a seed to split")
+	}
+	if err := slip39CheckPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) || len(groups) > slip39MaxShares {
+		return nil, errors.New("group threshold must be between 1 and the number of groups, with at most 16 groups")
+	}
+	for _, g := range groups {
+		if g.MemberThreshold < 1 || g.MemberThreshold > g.MemberCount || g.MemberCount > slip39MaxShares {
+			return nil, errors.New("member threshold must be between 1 and the member count, with at most 16 members")
+		}
+		if g.MemberThreshold == 1 && g.MemberCount > 1 {
+			return nil, errors.New("a member threshold of 1 requires a member count of 1, use more groups instead")
+		}
+	}
+
+	id := make([]byte, 2)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	identifier := binary.BigEndian.Uint16(id) & 0x7fff
+	encrypted := slip39Encrypt(hd.seed, []byte(passphrase), slip39IterationExp, identifier, false)
+
+	groupShares, err := slip39SplitSecret(groupThreshold, len(groups), encrypted)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]string, len(groups))
+	for i, g := range groups {
+		memberShares, err := slip39SplitSecret(g.MemberThreshold, g.MemberCount, groupShares[i].value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = make([]string, len(memberShares))
+		for j, m := range memberShares {
+			result[i][j] = slip39Share{
+				identifier:        identifier,
+				iterationExponent: slip39IterationExp,
+				groupIndex:        i,
+				groupThreshold:    groupThreshold,
+				groupCount:        len(groups),
+				memberIndex:       int(m.x),
+				memberThreshold:   g.MemberThreshold,
+				value:             m.value,
+			}.mnemonic()
+		}
+	}
+	return result, nil
+}
+
+// NewHdFromSlip39 recovers a Hd from a quorum of SLIP-0039 shares, the recovered master secret is used as the bip32
+// seed. Because the seed is not derived from a BIP39 mnemonic, String will be empty and Quiz is not available.
+func NewHdFromSlip39(shares []string, passphrase string) (*Hd, error) {
+	if err := slip39CheckPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	secret, err := slip39Combine(shares, []byte(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	return newHdFromSeed(secret)
+}
+
+func slip39CheckPassphrase(passphrase string) error {
+	for _, c := range passphrase {
+		if c < 32 || c > 126 {
+			return errors.New("SLIP-0039 passphrase must only contain printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+func slip39Combine(mnemonics []string, passphrase []byte) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, errors.New("no shares were provided")
+	}
+	groups := make(map[int][]slip39Share)
+	var first *slip39Share
+	for _, m := range mnemonics {
+		share, err := parseSlip39Share(m)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = share
+		}
+		if share.identifier != first.identifier || share.extendable != first.extendable ||
+			share.iterationExponent != first.iterationExponent || share.groupThreshold != first.groupThreshold ||
+			share.groupCount != first.groupCount {
+			return nil, errors.New("shares do not belong to the same secret")
+		}
+		for _, s := range groups[share.groupIndex] {
+			if s.memberThreshold != share.memberThreshold {
+				return nil, fmt.Errorf("shares in group %d have different member thresholds", share.groupIndex+1)
+			}
+			if s.memberIndex == share.memberIndex {
+				if string(s.value) != string(share.value) {
+					return nil, fmt.Errorf("conflicting shares for member %d of group %d", share.memberIndex+1, share.groupIndex+1)
+				}
+				share = nil
+				break
+			}
+		}
+		if share != nil {
+			groups[share.groupIndex] = append(groups[share.groupIndex], *share)
+		}
+	}
+
+	groupShares := make([]rawShare, 0)
+	for i := 0; i < first.groupCount && len(groupShares) < first.groupThreshold; i++ {
+		members := groups[i]
+		if len(members) == 0 || len(members) < members[0].memberThreshold {
+			continue
+		}
+		raw := make([]rawShare, members[0].memberThreshold)
+		for j := range raw {
+			raw[j] = rawShare{x: byte(members[j].memberIndex), value: members[j].value}
+		}
+		secret, err := slip39RecoverSecret(members[0].memberThreshold, raw)
+		if err != nil {
+			return nil, err
+		}
+		groupShares = append(groupShares, rawShare{x: byte(i), value: secret})
+	}
+	if len(groupShares) < first.groupThreshold {
+		return nil, fmt.Errorf("insufficient shares, need %d complete groups but only have %d", first.groupThreshold, len(groupShares))
+	}
+	encrypted, err := slip39RecoverSecret(first.groupThreshold, groupShares)
+	if err != nil {
+		return nil, err
+	}
+	return slip39Decrypt(encrypted, passphrase, first.iterationExponent, first.identifier, first.extendable), nil
+}
+
+// mnemonic encodes the share as words, including the checksum
+func (s slip39Share) mnemonic() string {
+	var ext uint64
+	if s.extendable {
+		ext = 1
+	}
+	header := uint64(s.identifier)<<25 | ext<<24 | uint64(s.iterationExponent)<<20 |
+		uint64(s.groupIndex)<<16 | uint64(s.groupThreshold-1)<<12 | uint64(s.groupCount-1)<<8 |
+		uint64(s.memberIndex)<<4 | uint64(s.memberThreshold-1)
+	data := make([]int, 4)
+	for i := range data {
+		data[i] = int(header>>(slip39RadixBits*(3-uint(i)))) & 1023
+	}
+	valueWords := (len(s.value)*8 + slip39RadixBits - 1) / slip39RadixBits
+	value := new(big.Int).SetBytes(s.value)
+	mask := big.NewInt(1023)
+	valueData := make([]int, valueWords)
+	for i := valueWords - 1; i >= 0; i-- {
+		valueData[i] = int(new(big.Int).And(value, mask).Int64())
+		value.Rsh(value, slip39RadixBits)
+	}
+	data = append(data, valueData...)
+	data = append(data, rs1024CreateChecksum(slip39Customization(s.extendable), data)...)
+	words := make([]string, len(data))
+	for i := range data {
+		words[i] = slip39Words[data[i]]
+	}
+	return strings.Join(words, " ")
+}
+
+func parseSlip39Share(mnemonic string) (*slip39Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < slip39MinWords {
+		return nil, fmt.Errorf("share must have at least %d words", slip39MinWords)
+	}
+	data := make([]int, len(words))
+	for i, w := range words {
+		idx, ok := slip39Index(w)
+		if !ok {
+			return nil, fmt.Errorf("word %q is not in the SLIP-0039 wordlist", w)
+		}
+		data[i] = idx
+	}
+	padding := (slip39RadixBits * (len(data) - slip39MetadataWords)) % 16
+	if padding > 8 {
+		return nil, errors.New("invalid share length")
+	}
+	idExp := data[0]<<10 | data[1]
+	s := &slip39Share{
+		identifier:        uint16(idExp >> 5),
+		extendable:        (idExp>>4)&1 == 1,
+		iterationExponent: uint8(idExp & 15),
+	}
+	if !rs1024VerifyChecksum(slip39Customization(s.extendable), data) {
+		return nil, errors.New("invalid share checksum")
+	}
+	params := data[2]<<10 | data[3]
+	s.groupIndex = params >> 16
+	s.groupThreshold = (params>>12)&15 + 1
+	s.groupCount = (params>>8)&15 + 1
+	s.memberIndex = (params >> 4) & 15
+	s.memberThreshold = params&15 + 1
+	if s.groupThreshold > s.groupCount {
+		return nil, errors.New("invalid share, group threshold exceeds group count")
+	}
+	value := new(big.Int)
+	for _, v := range data[4 : len(data)-3] {
+		value.Lsh(value, slip39RadixBits)
+		value.Or(value, big.NewInt(int64(v)))
+	}
+	length := (slip39RadixBits*(len(data)-slip39MetadataWords) - padding) / 8
+	raw := value.Bytes()
+	if len(raw) > length {
+		return nil, errors.New("invalid share padding")
+	}
+	s.value = make([]byte, length)
+	copy(s.value[length-len(raw):], raw)
+	return s, nil
+}
+
+// slip39Index finds a word, the unique four letter prefix is also accepted
+func slip39Index(word string) (int, bool) {
+	for i, w := range slip39Words {
+		if w == word || (len(word) >= 4 && strings.HasPrefix(w, word)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func slip39Customization(extendable bool) []byte {
+	if extendable {
+		return []byte("shamir_extendable")
+	}
+	return []byte("shamir")
+}
+
+var rs1024Gen = [10]uint32{0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009, 0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120}
+
+func rs1024Polymod(values []int) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ uint32(v)
+		for i := uint(0); i < 10; i++ {
+			if (b>>i)&1 == 1 {
+				chk ^= rs1024Gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func rs1024Values(customization []byte, data []int) []int {
+	values := make([]int, 0, len(customization)+len(data)+3)
+	for _, c := range customization {
+		values = append(values, int(c))
+	}
+	return append(values, data...)
+}
+
+func rs1024CreateChecksum(customization []byte, data []int) []int {
+	polymod := rs1024Polymod(append(rs1024Values(customization, data), 0, 0, 0)) ^ 1
+	return []int{int(polymod>>20) & 1023, int(polymod>>10) & 1023, int(polymod) & 1023}
+}
+
+func rs1024VerifyChecksum(customization []byte, data []int) bool {
+	return rs1024Polymod(rs1024Values(customization, data)) == 1
+}
+
+func slip39Salt(identifier uint16, extendable bool) []byte {
+	if extendable {
+		return []byte{}
+	}
+	return append([]byte("shamir"), byte(identifier>>8), byte(identifier))
+}
+
+func slip39Round(i int, passphrase []byte, e uint8, salt []byte, r []byte) []byte {
+	pass := append([]byte{byte(i)}, passphrase...)
+	s := append(append([]byte{}, salt...), r...)
+	return pbkdf2.Key(pass, s, (slip39BaseIterations<<e)/slip39Rounds, len(r), sha256.New)
+}
+
+// slip39Feistel runs the four round Feistel network used to encrypt the master secret, decryption runs the
+// rounds in reverse.
+func slip39Feistel(in []byte, passphrase []byte, e uint8, identifier uint16, extendable bool, reverse bool) []byte {
+	half := len(in) / 2
+	l := append([]byte{}, in[:half]...)
+	r := append([]byte{}, in[half:]...)
+	salt := slip39Salt(identifier, extendable)
+	for n := 0; n < slip39Rounds; n++ {
+		i := n
+		if reverse {
+			i = slip39Rounds - 1 - n
+		}
+		f := slip39Round(i, passphrase, e, salt, r)
+		for j := range l {
+			l[j] ^= f[j]
+		}
+		l, r = r, l
+	}
+	return append(r, l...)
+}
+
+func slip39Encrypt(secret []byte, passphrase []byte, e uint8, identifier uint16, extendable bool) []byte {
+	return slip39Feistel(secret, passphrase, e, identifier, extendable, false)
+}
+
+func slip39Decrypt(encrypted []byte, passphrase []byte, e uint8, identifier uint16, extendable bool) []byte {
+	return slip39Feistel(encrypted, passphrase, e, identifier, extendable, true)
+}
+
+// gf256Exp and gf256Log are lookup tables for GF(256) using the Rijndael polynomial
+var gf256Exp, gf256Log = func() (exp [255]byte, log [256]byte) {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(poly)
+		log[poly] = byte(i)
+		poly = (poly << 1) ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11b
+		}
+	}
+	return
+}()
+
+func slip39Interpolate(shares []rawShare, x byte) ([]byte, error) {
+	seen := make(map[byte]bool)
+	for _, s := range shares {
+		if seen[s.x] {
+			return nil, errors.New("share indexes must be unique")
+		}
+		seen[s.x] = true
+		if len(s.value) != len(shares[0].value) {
+			return nil, errors.New("all shares must have the same length")
+		}
+	}
+	for _, s := range shares {
+		if s.x == x {
+			return append([]byte{}, s.value...), nil
+		}
+	}
+	logProd := 0
+	for _, s := range shares {
+		logProd += int(gf256Log[s.x^x])
+	}
+	result := make([]byte, len(shares[0].value))
+	for _, s := range shares {
+		sum := 0
+		for _, o := range shares {
+			sum += int(gf256Log[s.x^o.x])
+		}
+		logBasis := ((logProd-int(gf256Log[s.x^x])-sum)%255 + 255) % 255
+		for i, v := range s.value {
+			if v != 0 {
+				result[i] ^= gf256Exp[(int(gf256Log[v])+logBasis)%255]
+			}
+		}
+	}
+	return result, nil
+}
+
+func slip39Digest(random []byte, secret []byte) []byte {
+	mac := hmac.New(sha256.New, random)
+	_, _ = mac.Write(secret)
+	return mac.Sum(nil)[:slip39DigestLength]
+}
+
+func slip39SplitSecret(threshold int, count int, secret []byte) ([]rawShare, error) {
+	if len(secret) < 16 || len(secret)%2 != 0 {
+		return nil, errors.New("secret must be at least 128 bits, and an even number of bytes")
+	}
+	shares := make([]rawShare, 0, count)
+	if threshold == 1 {
+		for i := 0; i < count; i++ {
+			shares = append(shares, rawShare{x: byte(i), value: append([]byte{}, secret...)})
+		}
+		return shares, nil
+	}
+	for i := 0; i < threshold-2; i++ {
+		v := make([]byte, len(secret))
+		if _, err := rand.Read(v); err != nil {
+			return nil, err
+		}
+		shares = append(shares, rawShare{x: byte(i), value: v})
+	}
+	random := make([]byte, len(secret)-slip39DigestLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	base := append(append([]rawShare{}, shares...),
+		rawShare{x: slip39DigestIndex, value: append(slip39Digest(random, secret), random...)},
+		rawShare{x: slip39SecretIndex, value: secret},
+	)
+	for i := threshold - 2; i < count; i++ {
+		v, err := slip39Interpolate(base, byte(i))
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, rawShare{x: byte(i), value: v})
+	}
+	return shares, nil
+}
+
+func slip39RecoverSecret(threshold int, shares []rawShare) ([]byte, error) {
+	if threshold == 1 {
+		return shares[0].value, nil
+	}
+	secret, err := slip39Interpolate(shares, slip39SecretIndex)
+	if err != nil {
+		return nil, err
+	}
+	digestShare, err := slip39Interpolate(shares, slip39DigestIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(digestShare[:slip39DigestLength], slip39Digest(digestShare[slip39DigestLength:], secret)) {
+		return nil, errors.New("invalid digest of the shared secret, the shares may be incorrect")
+	}
+	return secret, nil
+}
@@ -0,0 +1,101 @@
+package fiox
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewHdFromSlip39(t *testing.T) {
+	// SLIP-0039 reference test vectors, all use the passphrase "TREZOR"
+	vectors := []struct {
+		shares []string
+		secret string
+	}{
+		{
+			shares: []string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+			secret: "bb54aac4b89dc868ba37d9cc21b2cece",
+		},
+		{
+			shares: []string{
+				"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+				"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+			},
+			secret: "b43ceb7e57a0ea8766221624d01b0864",
+		},
+	}
+	for i, v := range vectors {
+		secret, err := slip39Combine(v.shares, []byte("TREZOR"))
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		if hex.EncodeToString(secret) != v.secret {
+			t.Error("vector", i, "secret mismatch, got", hex.EncodeToString(secret))
+		}
+	}
+	if _, err := slip39Combine([]string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision kidney"}, []byte("TREZOR")); err == nil {
+		t.Error("accepted share with an invalid checksum")
+	}
+	if _, err := slip39Combine(vectors[1].shares[:1], []byte("TREZOR")); err == nil {
+		t.Error("recovered a secret without enough shares")
+	}
+}
+
+func TestHd_Slip39(t *testing.T) {
+	hd, err := NewHdFromString("crater husband angle bitter chair rally luggage identify ticket pig toe wear border aerobic wage")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	shares, err := hd.Slip39(2, 3, "secret")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(shares) != 3 {
+		t.Error("expected 3 shares, got", len(shares))
+		return
+	}
+	restored, err := NewHdFromSlip39([]string{shares[2], shares[0]}, "secret")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, err := restored.KeyAt(15)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if key.Keys[0].String() != "5J6NKGL4cqbZXfi3fTbXZtPqtDL2wHeoLdmkLg2bnHQF2KSHijs" {
+		t.Error("key 15 mismatch after recovery")
+	}
+	if _, err = NewHdFromSlip39(shares[1:2], "secret"); err == nil {
+		t.Error("recovered with a single share")
+	}
+	if wrong, err := NewHdFromSlip39(shares[:2], "wrong"); err == nil {
+		k, _ := wrong.KeyAt(15)
+		if k.Keys[0].String() == key.Keys[0].String() {
+			t.Error("wrong passphrase recovered the same keys")
+		}
+	}
+
+	groups, err := hd.Slip39Groups(2, []Slip39Group{{1, 1}, {2, 3}, {3, 5}}, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	restored, err = NewHdFromSlip39([]string{groups[2][4], groups[0][0], groups[2][1], groups[2][0]}, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if k, _ := restored.KeyAt(15); k.Keys[0].String() != key.Keys[0].String() {
+		t.Error("key 15 mismatch after group recovery")
+	}
+	if _, err = hd.Slip39(1, 3, ""); err == nil {
+		t.Error("allowed member threshold of 1 with multiple shares")
+	}
+	if _, err = hd.Slip39(4, 3, ""); err == nil {
+		t.Error("allowed threshold larger than count")
+	}
+}
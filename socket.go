@@ -0,0 +1,120 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultKeosdURL is the loopback address keosd listens on when http-server-address is enabled, it is used instead
+// of a unix socket on Windows when no socket is given
+const DefaultKeosdURL = "http://127.0.0.1:8900"
+
+// DiscoverSocket finds a running keosd by checking the usual socket locations and returns the first that accepts a
+// connection. EOSIO_HOME is checked first when it is set, followed by ~/eosio-wallet, ~/fio-wallet, and ~/.keosd.
+func DiscoverSocket() (string, error) {
+	candidates := socketCandidates()
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		conn, err := dialKeosd(ctx, path)
+		cancel()
+		if err == nil {
+			_ = conn.Close()
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no running keosd found, tried sockets:\n\t%s", strings.Join(candidates, "\n\t"))
+}
+
+// socketCandidates lists where keosd's socket is usually found, in the order they are checked
+func socketCandidates() []string {
+	candidates := make([]string, 0)
+	if eosioHome := os.Getenv("EOSIO_HOME"); eosioHome != "" {
+		candidates = append(candidates,
+			filepath.Join(eosioHome, "keosd.sock"),
+			filepath.Join(eosioHome, "eosio-wallet", "keosd.sock"),
+		)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return candidates
+	}
+	return append(candidates,
+		filepath.Join(home, "eosio-wallet", "keosd.sock"),
+		filepath.Join(home, "fio-wallet", "keosd.sock"),
+		filepath.Join(home, ".keosd", "keosd.sock"),
+		filepath.Join(home, ".keosd"),
+	)
+}
+
+// dialKeosd connects to keosd on a unix socket, or a named pipe (\\.\pipe\name) on Windows
+func dialKeosd(ctx context.Context, path string) (net.Conn, error) {
+	if isNamedPipe(path) {
+		return dialPipe(ctx, path)
+	}
+	// Windows 10 and later support unix sockets too
+	return (&net.Dialer{}).DialContext(ctx, "unix", path)
+}
+
+func isNamedPipe(path string) bool {
+	return strings.HasPrefix(path, `\\.\pipe\`) || strings.HasPrefix(path, `//./pipe/`)
+}
+
+// pipeAddr is the net.Addr of a named pipe connection
+type pipeAddr string
+
+func (a pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (a pipeAddr) String() string {
+	return string(a)
+}
+
+// findExecutable looks for the first of the named programs in the PATH, next to the running program, and then in
+// the directories FIO releases install to. On Windows the .exe extension is added.
+func findExecutable(names ...string) (string, error) {
+	dirs := make([]string, 0)
+	if self, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(self))
+	}
+	if runtime.GOOS == "windows" {
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)"} {
+			if p := os.Getenv(env); p != "" {
+				dirs = append(dirs, filepath.Join(p, "fio", "bin"), filepath.Join(p, "eosio", "bin"))
+			}
+		}
+	} else {
+		versioned, _ := filepath.Glob("/usr/opt/fio/*/bin")
+		dirs = append(dirs, "/usr/local/bin", "/usr/opt/fio/bin")
+		dirs = append(dirs, versioned...)
+	}
+	for _, name := range names {
+		if p, err := exec.LookPath(name); err == nil {
+			return p, nil
+		}
+		if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+			name += ".exe"
+		}
+		for _, dir := range dirs {
+			p := filepath.Join(dir, name)
+			if info, err := os.Stat(p); err == nil && !info.IsDir() {
+				return p, nil
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "", errors.New("no executable name given")
+	}
+	return "", fmt.Errorf("could not find %s in the PATH or %s", strings.Join(names, " or "), strings.Join(dirs, ", "))
+}
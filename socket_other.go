@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package fiox
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// dialPipe is only supported on Windows
+func dialPipe(ctx context.Context, path string) (net.Conn, error) {
+	return nil, errors.New("named pipes are only supported on windows")
+}
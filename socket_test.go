@@ -0,0 +1,112 @@
+package fiox
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir, err := ioutil.TempDir("", "bin")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "fio-wallet"), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Error(err)
+		return
+	}
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	_ = os.Setenv("PATH", dir)
+
+	found, err := findExecutable("keosd-does-not-exist", "fio-wallet")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if found != filepath.Join(dir, "fio-wallet") {
+		t.Error("found the wrong program", found)
+	}
+	if _, err = findExecutable("keosd-does-not-exist"); err == nil {
+		t.Error("expected an error for a missing program")
+	}
+}
+
+func TestDialKeosdPipe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("named pipes are supported")
+	}
+	if _, err := dialKeosd(context.Background(), `\\.\pipe\keosd`); err == nil {
+		t.Error("expected named pipes to be unsupported")
+	}
+}
+
+// emptyHome points HOME at a new directory and clears EOSIO_HOME so DiscoverSocket only sees what the test creates
+func emptyHome(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, eosioHome := os.Getenv("HOME"), os.Getenv("EOSIO_HOME")
+	_ = os.Setenv("HOME", dir)
+	_ = os.Unsetenv("EOSIO_HOME")
+	return dir, func() {
+		_ = os.Setenv("HOME", home)
+		_ = os.Setenv("EOSIO_HOME", eosioHome)
+	}
+}
+
+func TestDiscoverSocket(t *testing.T) {
+	home, restore := emptyHome(t)
+	defer restore()
+	defer os.RemoveAll(home)
+
+	_, err := DiscoverSocket()
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(home, "eosio-wallet", "keosd.sock")) {
+		t.Error("expected an error listing the paths tried, got", err)
+	}
+
+	if err = os.MkdirAll(filepath.Join(home, "fio-wallet"), 0700); err != nil {
+		t.Error(err)
+		return
+	}
+	socket := filepath.Join(home, "fio-wallet", "keosd.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := &httptest.Server{Listener: l, Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`["default *"]`))
+	})}}
+	server.Start()
+	defer server.Close()
+
+	found, err := DiscoverSocket()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if found != socket {
+		t.Error("expected", socket, "got", found)
+	}
+	k := NewKeosClient("", "")
+	if k.Socket != socket {
+		t.Error("expected NewKeosClient to use the discovered socket, got", k.Socket)
+	}
+	if err = k.Ping(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package fiox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// errorPipeBusy is ERROR_PIPE_BUSY, returned when every instance of the pipe is in use
+const errorPipeBusy = syscall.Errno(231)
+
+// dialPipe opens a named pipe, retrying while the pipe is busy
+func dialPipe(ctx context.Context, path string) (net.Conn, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err == nil {
+			return pipeConn{File: f, addr: pipeAddr(path)}, nil
+		}
+		if !errors.Is(err, errorPipeBusy) {
+			return nil, err
+		}
+		if err = sleepContext(ctx, 10*time.Millisecond); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// pipeConn adapts a named pipe opened as a file to a net.Conn, deadlines are not supported on synchronous pipes so
+// the http client's timeouts are relied on instead
+type pipeConn struct {
+	*os.File
+	addr pipeAddr
+}
+
+func (c pipeConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+func (c pipeConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func (c pipeConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c pipeConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c pipeConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
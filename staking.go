@@ -0,0 +1,110 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/fioprotocol/fio-go"
+	"math"
+	"math/big"
+)
+
+// Staker stakes and unstakes FIO tokens with one key of a Signer. Staking with a FIO address uses its bundled
+// transactions when any are left, otherwise the fee is looked up and used as the max_fee.
+type Staker struct {
+	payer
+}
+
+// StakeResult is the result of a stake or unstake transaction, amounts are in SUFs
+type StakeResult struct {
+	Amount        uint64 `json:"amount"`
+	Fee           uint64 `json:"fee"`
+	Bundled       bool   `json:"bundled"`
+	TransactionID string `json:"transaction_id"`
+}
+
+// NewStaker pays with the key pub held by signer, fees is optional and lets several helpers share a FeeCache
+func NewStaker(api *fio.API, signer Signer, pub string, fees *FeeCache) (*Staker, error) {
+	p, err := newPayer(api, signer, pub, fees)
+	if err != nil {
+		return nil, err
+	}
+	return &Staker{payer: p}, nil
+}
+
+// Staker creates a Staker for the key at index
+func (hd Hd) Staker(api *fio.API, index int) (*Staker, error) {
+	p, err := hdPayer(hd, api, index)
+	if err != nil {
+		return nil, err
+	}
+	return &Staker{payer: p}, nil
+}
+
+// Stake stakes amount SUFs, fioAddress is the staker's address and may be empty, though without one the fee is
+// always paid
+func (s *Staker) Stake(ctx context.Context, fioAddress string, amount uint64) (*StakeResult, error) {
+	return s.stake(ctx, "stakefio", "stake_fio_tokens", fioAddress, amount)
+}
+
+// Unstake unstakes amount SUFs, which are then locked for the unstaking period. Use FioBalance.StakedValue to see
+// what the staked tokens are worth with their rewards.
+func (s *Staker) Unstake(ctx context.Context, fioAddress string, amount uint64) (*StakeResult, error) {
+	return s.stake(ctx, "unstakefio", "unstake_fio_tokens", fioAddress, amount)
+}
+
+func (s *Staker) stake(ctx context.Context, action string, endpoint string, fioAddress string, amount uint64) (*StakeResult, error) {
+	if amount == 0 || amount > math.MaxInt64 {
+		return nil, errors.New("invalid amount")
+	}
+	result := &StakeResult{Amount: amount}
+	if fioAddress == "" {
+		fee, err := s.fees.FeeFor(ctx, endpoint, "")
+		if err != nil {
+			return nil, err
+		}
+		result.Fee = fee
+	} else {
+		bd, err := s.fees.BundledFee(ctx, endpoint, fioAddress)
+		if err != nil {
+			return nil, err
+		}
+		result.Fee, result.Bundled = bd.MaxFee, bd.Bundled
+	}
+	buf := &bytes.Buffer{}
+	writeAbiStrings(buf, fioAddress)
+	_ = binary.Write(buf, binary.LittleEndian, []uint64{amount, result.Fee})
+	writeAbiStrings(buf, s.TPID)
+	if err := writeAbiName(buf, string(s.actor)); err != nil {
+		return nil, err
+	}
+	pushed, err := sendActions(ctx, s.api, s.signer, s.pub, newAction("fio.staking", action, s.actor, buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	result.TransactionID = pushed.TransactionID
+	return result, nil
+}
+
+// StakedValue estimates what the staking reward points are worth in SUFs at the current rate of exchange, this is
+// the staked tokens plus their rewards
+func (b FioBalance) StakedValue() (uint64, error) {
+	return SRPsToSUFs(b.SRPs, b.ROE)
+}
+
+// SRPsToSUFs converts staking reward points to SUFs using a rate of exchange such as the roe from
+// get_fio_balance, rounding down as the staking contract does
+func SRPsToSUFs(srps uint64, roe string) (uint64, error) {
+	rate, ok := new(big.Rat).SetString(roe)
+	if !ok || rate.Sign() < 0 {
+		return 0, fmt.Errorf("invalid rate of exchange %q", roe)
+	}
+	value := new(big.Rat).Mul(new(big.Rat).SetInt(new(big.Int).SetUint64(srps)), rate)
+	sufs := new(big.Int).Quo(value.Num(), value.Denom())
+	if !sufs.IsUint64() {
+		return 0, errors.New("value is too large")
+	}
+	return sufs.Uint64(), nil
+}
@@ -0,0 +1,82 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+)
+
+func TestStaker(t *testing.T) {
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pub, _ := hd.KeySet(0, 1)
+	nodeos := newTestNodeos(map[string]interface{}{
+		"/v1/chain/get_pub_address": map[string]string{"public_address": pub.Keys[0].PublicKey.String()},
+		"/v1/chain/get_fio_names": map[string]interface{}{"fio_addresses": []map[string]interface{}{
+			{"fio_address": "alice@fiotestnet", "remaining_bundled_tx": 3},
+		}},
+	})
+	defer nodeos.Close()
+	s, err := hd.Staker(nodeos.api(), 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s.TPID = "tpid@fiotestnet"
+	ctx := context.Background()
+
+	result, err := s.Stake(ctx, "alice@fiotestnet", 100_000_000_000)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !result.Bundled || result.Fee != 0 || result.TransactionID == "" {
+		t.Error("expected a bundled stake", result)
+	}
+	data := nodeos.checkPushed(t, 0, s.pub)
+	expect := &bytes.Buffer{}
+	writeAbiStrings(expect, "alice@fiotestnet")
+	_ = binary.Write(expect, binary.LittleEndian, []uint64{100_000_000_000, 0})
+	writeAbiStrings(expect, "tpid@fiotestnet")
+	_ = writeAbiName(expect, string(s.actor))
+	if !bytes.Equal(data[0], expect.Bytes()) {
+		t.Errorf("unexpected stakefio data %x", data[0])
+	}
+
+	// without an address the fee is paid
+	if result, err = s.Unstake(ctx, "", 1_000_000_000); err != nil || result.Bundled || result.Fee != 40_000_000_000 {
+		t.Error("expected unstaking to pay the fee", result, err)
+	}
+	if _, err = s.Stake(ctx, "", 0); err == nil {
+		t.Error("expected a zero amount to be rejected")
+	}
+}
+
+func TestSRPsToSUFs(t *testing.T) {
+	for _, test := range []struct {
+		srps   uint64
+		roe    string
+		expect uint64
+	}{
+		{1_000_000_000, "1.000000000000000", 1_000_000_000},
+		{3_000_000_000, "0.500000000000000", 1_500_000_000},
+		{10, "0.333333333333333", 3},
+		{0, "1.25", 0},
+	} {
+		sufs, err := SRPsToSUFs(test.srps, test.roe)
+		if err != nil || sufs != test.expect {
+			t.Error("unexpected value", test, sufs, err)
+		}
+	}
+	if _, err := SRPsToSUFs(1, ""); err == nil {
+		t.Error("expected an invalid roe to be rejected")
+	}
+	value, err := FioBalance{SRPs: 2_000_000_000, ROE: "0.75"}.StakedValue()
+	if err != nil || value != 1_500_000_000 {
+		t.Error("unexpected staked value", value, err)
+	}
+}
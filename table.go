@@ -0,0 +1,137 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"io"
+	"time"
+)
+
+// DefaultTablePageSize is how many rows a TableScanner requests at once when TableQuery.Limit is not set
+const DefaultTablePageSize = 100
+
+// TableQuery selects the rows of a contract table, Index, KeyType, and the bounds are passed to get_table_rows as is.
+// For example the domains table is Code "fio.address", Scope "fio.address", Table "domains".
+type TableQuery struct {
+	Code       string `json:"code"`
+	Scope      string `json:"scope"`
+	Table      string `json:"table"`
+	Index      string `json:"index_position,omitempty"`
+	KeyType    string `json:"key_type,omitempty"`
+	LowerBound string `json:"lower_bound,omitempty"`
+	UpperBound string `json:"upper_bound,omitempty"`
+	Limit      int    `json:"limit"`
+	Reverse    bool   `json:"reverse,omitempty"`
+}
+
+// TableScanner pages through a table with get_table_rows. There are no generics in the Go versions this package
+// supports, so like a json.Decoder, rows are decoded into whatever the caller passes to Next or All. A TableScanner
+// is not safe for concurrent use.
+type TableScanner struct {
+	api      *fio.API
+	query    TableQuery
+	interval time.Duration
+	last     time.Time
+
+	rows []json.RawMessage
+	done bool
+}
+
+// NewTableScanner creates a TableScanner, no request is made until Next or All is called
+func NewTableScanner(api *fio.API, query TableQuery) (*TableScanner, error) {
+	if api == nil {
+		return nil, errors.New("api cannot be nil")
+	}
+	if query.Code == "" || query.Table == "" {
+		return nil, errors.New("code and table are required")
+	}
+	if query.Scope == "" {
+		query.Scope = query.Code
+	}
+	if query.Limit < 1 {
+		query.Limit = DefaultTablePageSize
+	}
+	return &TableScanner{api: api, query: query}, nil
+}
+
+// WithInterval rate limits the scanner to one request per interval, public API nodes will throttle a long scan
+func (ts *TableScanner) WithInterval(interval time.Duration) *TableScanner {
+	ts.interval = interval
+	return ts
+}
+
+// Next decodes the next row into v, which should be a pointer. io.EOF is returned once every row has been read.
+func (ts *TableScanner) Next(ctx context.Context, v interface{}) error {
+	for len(ts.rows) == 0 {
+		if ts.done {
+			return io.EOF
+		}
+		if err := ts.fetch(ctx); err != nil {
+			return err
+		}
+	}
+	row := ts.rows[0]
+	ts.rows = ts.rows[1:]
+	return json.Unmarshal(row, v)
+}
+
+// All decodes the remaining rows into slice, which should be a pointer to a slice
+func (ts *TableScanner) All(ctx context.Context, slice interface{}) error {
+	all := make([]json.RawMessage, 0)
+	for {
+		var row json.RawMessage
+		err := ts.Next(ctx, &row)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		all = append(all, row)
+	}
+	b, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, slice)
+}
+
+func (ts *TableScanner) fetch(ctx context.Context) error {
+	if wait := ts.interval - time.Since(ts.last); ts.interval > 0 && wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	ts.last = time.Now()
+	page := struct {
+		Rows    []json.RawMessage `json:"rows"`
+		More    bool              `json:"more"`
+		NextKey string            `json:"next_key"`
+	}{}
+	query := struct {
+		TableQuery
+		JSON bool `json:"json"`
+	}{TableQuery: ts.query, JSON: true}
+	if _, err := chainPost(ctx, ts.api, "/v1/chain/get_table_rows", query, &page); err != nil {
+		return err
+	}
+	ts.rows = page.Rows
+	switch {
+	case !page.More:
+		ts.done = true
+	case page.NextKey == "":
+		ts.done = true
+		return errors.New("nodeos did not provide next_key, it is too old to page through tables")
+	case ts.query.Reverse:
+		ts.query.UpperBound = page.NextKey
+	default:
+		ts.query.LowerBound = page.NextKey
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package fiox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTableScanner(t *testing.T) {
+	type domain struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	requests := 0
+	nodeos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		if query["code"] != "fio.address" || query["table"] != "domains" || query["json"] != true {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		start, _ := strconv.Atoi(query["lower_bound"].(string))
+		limit := int(query["limit"].(float64))
+		page := map[string]interface{}{"rows": []domain{}}
+		for id := start; id < 5 && id < start+limit; id++ {
+			page["rows"] = append(page["rows"].([]domain), domain{ID: id, Name: "domain" + strconv.Itoa(id)})
+		}
+		if start+limit < 5 {
+			page["more"], page["next_key"] = true, strconv.Itoa(start+limit)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer nodeos.Close()
+	api := &fio.API{API: eos.API{BaseURL: nodeos.URL}}
+	ctx := context.Background()
+
+	ts, err := NewTableScanner(api, TableQuery{Code: "fio.address", Table: "domains", LowerBound: "0", Limit: 2})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ts.WithInterval(20 * time.Millisecond)
+	started := time.Now()
+	for i := 0; ; i++ {
+		d := domain{}
+		err = ts.Next(ctx, &d)
+		if errors.Is(err, io.EOF) {
+			if i != 5 {
+				t.Error("expected 5 rows, got", i)
+			}
+			break
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if d.ID != i || d.Name != "domain"+strconv.Itoa(i) {
+			t.Error("unexpected row", i, d)
+		}
+	}
+	if requests != 3 || time.Since(started) < 40*time.Millisecond {
+		t.Error("expected 3 rate limited requests", requests, time.Since(started))
+	}
+
+	ts, _ = NewTableScanner(api, TableQuery{Code: "fio.address", Table: "domains", LowerBound: "1", Limit: 3})
+	domains := make([]domain, 0)
+	if err = ts.All(ctx, &domains); err != nil || len(domains) != 4 || domains[3].ID != 4 {
+		t.Error("unexpected rows", domains, err)
+	}
+
+	ts, _ = NewTableScanner(api, TableQuery{Code: "fio.address", Table: "domains", LowerBound: "0", Limit: 1})
+	ts.WithInterval(time.Minute)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_ = ts.Next(ctx, &domain{})
+	if err = ts.Next(cancelled, &domain{}); !errors.Is(err, context.Canceled) {
+		t.Error("expected the rate limit wait to be cancelled", err)
+	}
+}
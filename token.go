@@ -0,0 +1,138 @@
+package fiox
+
+import (
+	"context"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"math/big"
+)
+
+// Token is a PKCS#11 token or HSM holding secp256k1 keys, reached through a small adapter around a PKCS#11 library
+// (such as miekg/pkcs11) so this package does not need cgo. The adapter owns the session and the PIN login. Keys are
+// found by whatever the adapter uses as an id, usually the CKA_LABEL or CKA_ID.
+type Token interface {
+	// PublicKey provides the CKA_EC_PARAMS and CKA_EC_POINT attributes of the key
+	PublicKey(ctx context.Context, keyID string) (params []byte, point []byte, err error)
+	// Sign signs a 32 byte digest with CKM_ECDSA, providing the 64 byte r || s. A DER encoded signature, as returned
+	// by PIV libraries, is also accepted.
+	Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+}
+
+// ErrUnsupportedCurve is returned for token keys that are not secp256k1. This includes every YubiKey PIV slot, PIV
+// only offers P-256 and P-384, while FIO accounts need K1 signatures; a YubiHSM 2 does support secp256k1.
+var ErrUnsupportedCurve = errors.New("key is not secp256k1 and cannot sign FIO transactions")
+
+// TokenSignAttempts is how many signatures are requested from a token before giving up on a canonical one
+var TokenSignAttempts = 25
+
+var oidP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+// TokenSigner is a Signer for keys held on a PKCS#11 token, the private keys never leave the device
+type TokenSigner struct {
+	token Token
+	keys  remoteKeys
+}
+
+// NewTokenSigner creates a Signer for the token keys, reading each public key up front
+func NewTokenSigner(ctx context.Context, token Token, keyIDs ...string) (*TokenSigner, error) {
+	if token == nil {
+		return nil, errors.New("token cannot be nil")
+	}
+	if len(keyIDs) == 0 {
+		return nil, errors.New("at least one key id is required")
+	}
+	s := &TokenSigner{token: token}
+	for _, id := range keyIDs {
+		params, point, err := token.PublicKey(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the public key for %s: %w", id, err)
+		}
+		pub, err := parseTokenPublicKey(params, point)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		s.keys = append(s.keys, remoteKey{id: id, pub: pub})
+	}
+	return s, nil
+}
+
+// PublicKeys lists the FIO public keys of the token keys
+func (s *TokenSigner) PublicKeys(ctx context.Context) ([]string, error) {
+	return s.keys.publicKeys(), nil
+}
+
+// KeyID provides the token key id holding pub
+func (s *TokenSigner) KeyID(pub string) (string, error) {
+	k, err := s.keys.key(pub)
+	if err != nil {
+		return "", err
+	}
+	return k.id, nil
+}
+
+// SignDigest has the token sign a 32 byte digest with the key for pub, converting the result to a canonical EOS
+// signature
+func (s *TokenSigner) SignDigest(ctx context.Context, digest []byte, pub string) (ecc.Signature, error) {
+	if len(digest) != 32 {
+		return ecc.Signature{}, errors.New("digest must be 32 bytes")
+	}
+	k, err := s.keys.key(pub)
+	if err != nil {
+		return ecc.Signature{}, err
+	}
+	return signCanonical(digest, k.pub, TokenSignAttempts, func() (*big.Int, *big.Int, error) {
+		sig, err := s.token.Sign(ctx, k.id, digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(sig) == 64 {
+			return new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]), nil
+		}
+		der, err := btcec.ParseDERSignature(sig, btcec.S256())
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid signature from token: %w", err)
+		}
+		return der.R, der.S, nil
+	})
+}
+
+// SignTransaction signs a transaction with each of pubkeys, accepting the same transactions as
+// KeosClient.SignTransaction
+func (s *TokenSigner) SignTransaction(ctx context.Context, tx interface{}, pubkeys []string, chainID string) ([]ecc.Signature, error) {
+	return signTransaction(ctx, s, tx, pubkeys, chainID)
+}
+
+// parseTokenPublicKey decodes PKCS#11 EC key attributes. CKA_EC_POINT should be a DER octet string, but some tokens
+// provide the bare 33 or 65 byte point so both are accepted.
+func parseTokenPublicKey(params []byte, point []byte) (ecc.PublicKey, error) {
+	curve := asn1.ObjectIdentifier{}
+	if _, err := asn1.Unmarshal(params, &curve); err != nil {
+		return ecc.PublicKey{}, fmt.Errorf("invalid CKA_EC_PARAMS: %w", err)
+	}
+	switch {
+	case curve.Equal(oidSecp256k1):
+	case curve.Equal(oidP256):
+		return ecc.PublicKey{}, fmt.Errorf("%w: the key is P-256", ErrUnsupportedCurve)
+	default:
+		return ecc.PublicKey{}, fmt.Errorf("%w: curve %s", ErrUnsupportedCurve, curve)
+	}
+	if len(point) != 33 && len(point) != 65 {
+		octets := make([]byte, 0)
+		if _, err := asn1.Unmarshal(point, &octets); err != nil {
+			return ecc.PublicKey{}, fmt.Errorf("invalid CKA_EC_POINT: %w", err)
+		}
+		point = octets
+	}
+	key, err := btcec.ParsePubKey(point, btcec.S256())
+	if err != nil {
+		return ecc.PublicKey{}, err
+	}
+	pub, err := fioPubKey(key)
+	if err != nil {
+		return ecc.PublicKey{}, err
+	}
+	return *pub, nil
+}
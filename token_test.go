@@ -0,0 +1,83 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"testing"
+)
+
+// fakeToken behaves like a PKCS#11 token, returning DER wrapped points and r || s signatures
+type fakeToken struct {
+	key *btcec.PrivateKey
+}
+
+func (f fakeToken) PublicKey(ctx context.Context, keyID string) ([]byte, []byte, error) {
+	switch keyID {
+	case "fio":
+		params, _ := asn1.Marshal(oidSecp256k1)
+		point, _ := asn1.Marshal(f.key.PubKey().SerializeUncompressed())
+		return params, point, nil
+	case "piv":
+		p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		params, _ := asn1.Marshal(oidP256)
+		return params, elliptic.Marshal(elliptic.P256(), p256.X, p256.Y), nil
+	}
+	return nil, nil, errors.New("CKR_KEY_HANDLE_INVALID")
+}
+
+func (f fakeToken) Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(rand.Reader, f.key.ToECDSA(), digest)
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	copy(sig[32-len(r.Bytes()):32], r.Bytes())
+	copy(sig[64-len(s.Bytes()):], s.Bytes())
+	return sig, nil
+}
+
+func TestTokenSigner(t *testing.T) {
+	priv, err := ecc.NewPrivateKey("5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	raw, err := rawPrivateKey(priv)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), raw)
+	token := fakeToken{key: key}
+
+	ctx := context.Background()
+	if _, err = NewTokenSigner(ctx, token, "piv"); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Error("expected ErrUnsupportedCurve for a P-256 key", err)
+	}
+	s, err := NewTokenSigner(ctx, token, "fio")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pubs, _ := s.PublicKeys(ctx)
+	if len(pubs) != 1 || pubs[0] != priv.PublicKey().String() {
+		t.Error("unexpected public keys", pubs)
+		return
+	}
+	digest := bytes.Repeat([]byte{9}, 32)
+	sig, err := s.SignDigest(ctx, digest, pubs[0])
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !sig.Verify(digest, priv.PublicKey()) || !isCanonical(sig.Content) {
+		t.Error("signature did not verify or is not canonical", sig.String())
+	}
+}
@@ -0,0 +1,128 @@
+package fiox
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// TraceEvent describes a single HTTP request, the bodies are only included when tracing was enabled with bodies and
+// have passwords and private keys redacted
+type TraceEvent struct {
+	Method       string
+	Path         string
+	StatusCode   int // zero if the request failed
+	Duration     time.Duration
+	Err          error
+	RequestBody  []byte
+	ResponseBody []byte
+}
+
+// Tracer receives a TraceEvent after each request completes
+type Tracer interface {
+	Trace(event TraceEvent)
+}
+
+// TracerFunc allows a function to be used as a Tracer
+type TracerFunc func(event TraceEvent)
+
+// Trace calls f
+func (f TracerFunc) Trace(event TraceEvent) {
+	f(event)
+}
+
+// WithTracer sends a TraceEvent to tracer for each request to keosd, bodies includes the redacted request and
+// response bodies
+func WithTracer(tracer Tracer, bodies bool) KeosOption {
+	return func(k *KeosClient) {
+		k.tracer = tracer
+		k.traceBodies = bodies
+	}
+}
+
+// TraceTransport wraps an http.RoundTripper so requests made with it are traced, it can be used for the nodeos
+// client: api.HttpClient.Transport = TraceTransport(api.HttpClient.Transport, tracer, false). A nil next uses
+// http.DefaultTransport.
+func TraceTransport(next http.RoundTripper, tracer Tracer, bodies bool) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &traceTransport{next: next, tracer: tracer, bodies: bodies}
+}
+
+type traceTransport struct {
+	next   http.RoundTripper
+	tracer Tracer
+	bodies bool
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	event := TraceEvent{Method: req.Method, Path: req.URL.Path}
+	if t.bodies && req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			b, _ := ioutil.ReadAll(body)
+			_ = body.Close()
+			event.RequestBody = redactRequest(event.Path, b)
+		}
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	event.Duration = time.Since(start)
+	if err != nil {
+		event.Err = err
+		t.tracer.Trace(event)
+		return nil, err
+	}
+	event.StatusCode = resp.StatusCode
+	if t.bodies {
+		b, err := readLimited(resp.Body, 0)
+		_ = resp.Body.Close()
+		if err != nil {
+			event.Err = err
+			t.tracer.Trace(event)
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+		event.ResponseBody = redactResponse(event.Path, b)
+	}
+	t.tracer.Trace(event)
+	return resp, nil
+}
+
+func (k *KeosClient) trace(event TraceEvent, request []byte, response []byte) {
+	if k.tracer == nil {
+		return
+	}
+	if k.traceBodies {
+		event.RequestBody = redactRequest(event.Path, request)
+		event.ResponseBody = redactResponse(event.Path, response)
+	}
+	k.tracer.Trace(event)
+}
+
+var (
+	// secretPattern matches WIF and PVT_ private keys, and keosd wallet passwords
+	secretPattern = regexp.MustCompile(`\b(5[HJK][1-9A-HJ-NP-Za-km-z]{49}|PVT_[KR]1_[1-9A-HJ-NP-Za-km-z]+|PW5[1-9A-HJ-NP-Za-km-z]{49})\b`)
+
+	// keosd endpoints that have a password in the request, or keys and passwords in the response
+	secretRequests  = map[string]bool{"/v1/wallet/unlock": true, "/v1/wallet/list_keys": true, "/v1/wallet/remove_key": true, "/v1/wallet/import_key": true}
+	secretResponses = map[string]bool{"/v1/wallet/list_keys": true, "/v1/wallet/create": true}
+)
+
+var redacted = []byte(`"[redacted]"`)
+
+func redactRequest(path string, body []byte) []byte {
+	if len(body) > 0 && secretRequests[path] {
+		return redacted
+	}
+	return secretPattern.ReplaceAll(body, []byte("[redacted]"))
+}
+
+func redactResponse(path string, body []byte) []byte {
+	if len(body) > 0 && secretResponses[path] {
+		return redacted
+	}
+	return secretPattern.ReplaceAll(body, []byte("[redacted]"))
+}
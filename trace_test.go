@@ -0,0 +1,78 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestKeosTracer(t *testing.T) {
+	server := keosdServer(map[string]interface{}{
+		"/v1/wallet/unlock":          struct{}{},
+		"/v1/wallet/list_wallets":    []string{"default"},
+		"/v1/wallet/get_public_keys": []string{"PUB_K1_6LgWGNcU7sUrB8NkJvvRBgcTSBCo7uH8pWs3mvcUPmE8hU6mFN"},
+	}, nil)
+	defer server.Close()
+
+	events := make([]TraceEvent, 0)
+	k := NewKeosClient(server.URL, "", WithTracer(TracerFunc(func(event TraceEvent) {
+		events = append(events, event)
+	}), true))
+	ctx := context.Background()
+	if err := k.Unlock(ctx, "PW5KFWYKqvt63d4iNvedfDEPVZL227D3RQ1zpVFzuUwhMAJmRAYyX", "default"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := k.GetPublicKeys(ctx); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(events) != 3 {
+		t.Error("expected 3 events, got", len(events))
+		return
+	}
+	for _, event := range events {
+		if event.StatusCode != http.StatusOK || event.Method != http.MethodPost || event.Duration <= 0 {
+			t.Errorf("unexpected event %+v", event)
+		}
+		if bytes.Contains(event.RequestBody, []byte("PW5")) {
+			t.Error("password was not redacted in", event.Path)
+		}
+	}
+	if events[1].Path != "/v1/wallet/unlock" || string(events[1].RequestBody) != `"[redacted]"` {
+		t.Errorf("expected the unlock request to be redacted, got %s %s", events[1].Path, events[1].RequestBody)
+	}
+	if !bytes.Contains(events[2].ResponseBody, []byte("PUB_K1_")) {
+		t.Error("public keys should not be redacted")
+	}
+}
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"key":"5KQwrPbwdL6PhXujxW37FSSQZ1JiwsST4cqQzDeyXtP79zkvFD3"}`))
+	}))
+	defer server.Close()
+
+	var event TraceEvent
+	client := &http.Client{Transport: TraceTransport(nil, TracerFunc(func(e TraceEvent) {
+		event = e
+	}), true)}
+	resp, err := client.Post(server.URL+"/v1/chain/get_info", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	buf := &bytes.Buffer{}
+	_, _ = buf.ReadFrom(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(buf.String(), "5KQwrP") {
+		t.Error("the caller should receive the unredacted body")
+	}
+	if event.Path != "/v1/chain/get_info" || string(event.RequestBody) != `{}` ||
+		string(event.ResponseBody) != `{"key":"[redacted]"}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
@@ -0,0 +1,102 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"time"
+)
+
+// TransactionExpiration is how long transactions built by this package are valid after they are signed
+var TransactionExpiration = 2 * time.Minute
+
+// PushResult is the response from push_transaction, Processed holds the action traces
+type PushResult struct {
+	TransactionID string          `json:"transaction_id"`
+	Processed     json.RawMessage `json:"processed"`
+}
+
+// newAction creates an action authorized by actor@active with data already serialized
+func newAction(contract string, action string, actor eos.AccountName, data []byte) jsonAction {
+	hexData := hex.EncodeToString(data)
+	return jsonAction{
+		Account:       contract,
+		Name:          action,
+		Authorization: []PermissionLevel{{Actor: string(actor), Permission: "active"}},
+		// keosd reads data, PackTransactionJSON prefers hex_data
+		Data:    json.RawMessage(`"` + hexData + `"`),
+		HexData: hexData,
+	}
+}
+
+// writeAbiName uses the eosio binary encoding for names, a uint64
+func writeAbiName(buf *bytes.Buffer, name string) error {
+	v, err := eos.StringToName(name)
+	if err != nil {
+		return err
+	}
+	_ = binary.Write(buf, binary.LittleEndian, v)
+	return nil
+}
+
+// sendActions builds a transaction holding actions, has signer sign it with pub, and pushes it to nodeos
+func sendActions(ctx context.Context, api *fio.API, signer Signer, pub string, actions ...jsonAction) (*PushResult, error) {
+	if api == nil || signer == nil {
+		return nil, errors.New("api and signer are required")
+	}
+	if len(actions) == 0 {
+		return nil, errors.New("at least one action is required")
+	}
+	info := struct {
+		ChainID                 string `json:"chain_id"`
+		LastIrreversibleBlockID string `json:"last_irreversible_block_id"`
+	}{}
+	if _, err := chainPost(ctx, api, "/v1/chain/get_info", struct{}{}, &info); err != nil {
+		return nil, err
+	}
+	blockID, err := hex.DecodeString(info.LastIrreversibleBlockID)
+	if err != nil || len(blockID) != 32 {
+		return nil, errors.New("get_info returned an invalid block id")
+	}
+	tx := jsonTransaction{
+		Expiration: time.Now().UTC().Add(TransactionExpiration).Format("2006-01-02T15:04:05"),
+		// TAPOS: the low 16 bits of the block number and 4 bytes of the block id
+		RefBlockNum:        uint16(binary.BigEndian.Uint32(blockID[:4])),
+		RefBlockPrefix:     binary.LittleEndian.Uint32(blockID[8:12]),
+		ContextFreeActions: []jsonAction{},
+		Actions:            actions,
+	}
+	sigs, err := signer.SignTransaction(ctx, tx, []string{pub}, info.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	trx, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := PackTransactionJSON(trx)
+	if err != nil {
+		return nil, err
+	}
+	signed := &SignedPackedTransaction{
+		Signatures:  make([]string, len(sigs)),
+		Compression: "none",
+		PackedTrx:   hex.EncodeToString(packed),
+	}
+	for i := range sigs {
+		signed.Signatures[i] = sigs[i].String()
+	}
+	result := &PushResult{}
+	if _, err = chainPost(ctx, api, "/v1/chain/push_transaction", signed, result); err != nil {
+		return nil, err
+	}
+	if result.TransactionID == "" {
+		return nil, errors.New("push_transaction did not return a transaction id")
+	}
+	return result, nil
+}
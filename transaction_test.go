@@ -0,0 +1,133 @@
+package fiox
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testNodeos answers get_info, get_fee, and push_transaction, recording pushed transactions. Other endpoints are
+// answered from responses by path.
+type testNodeos struct {
+	*httptest.Server
+	pushed    []SignedPackedTransaction
+	responses map[string]interface{}
+}
+
+func newTestNodeos(responses map[string]interface{}) *testNodeos {
+	n := &testNodeos{responses: responses}
+	if n.responses == nil {
+		n.responses = make(map[string]interface{})
+	}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chain/get_info":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"chain_id":                   FioTestnetChainID,
+				"last_irreversible_block_id": "0000302a" + "6a2b5f1c" + "12345678" + hex.EncodeToString(make([]byte, 20)),
+			})
+		case "/v1/chain/push_transaction":
+			signed := SignedPackedTransaction{}
+			if err := json.NewDecoder(r.Body).Decode(&signed); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			n.pushed = append(n.pushed, signed)
+			_, _ = w.Write([]byte(`{"transaction_id":"d1e5c1b5","processed":{}}`))
+		default:
+			resp, ok := n.responses[r.URL.Path]
+			if !ok && r.URL.Path == "/v1/chain/get_fee" {
+				resp, ok = map[string]uint64{"fee": 40_000_000_000}, true
+			}
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	}))
+	return n
+}
+
+func (n *testNodeos) api() *fio.API {
+	return &fio.API{API: eos.API{BaseURL: n.URL}}
+}
+
+// checkPushed verifies the signature of a pushed transaction and provides the data of its actions
+func (n *testNodeos) checkPushed(t *testing.T, i int, pub string) [][]byte {
+	if len(n.pushed) <= i {
+		t.Fatal("transaction was not pushed")
+	}
+	packed, _ := hex.DecodeString(n.pushed[i].PackedTrx)
+	chainID, _ := hex.DecodeString(FioTestnetChainID)
+	digest := TransactionDigest(chainID, packed, nil)
+	sig, err := ecc.NewSignature(n.pushed[i].Signatures[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := ecc.NewPublicKey(pub)
+	if !sig.Verify(digest, key) {
+		t.Error("signature does not verify")
+	}
+	// the TAPOS fields come from the block id
+	if !bytes.Equal(packed[4:6], []byte{0x2a, 0x30}) || !bytes.Equal(packed[6:10], []byte{0x12, 0x34, 0x56, 0x78}) {
+		t.Errorf("unexpected tapos %x", packed[4:10])
+	}
+	return actionData(t, packed)
+}
+
+// actionData extracts the data of each action from a packed transaction without context free actions
+func actionData(t *testing.T, packed []byte) [][]byte {
+	r := bytes.NewReader(packed[13:])
+	if b, _ := r.ReadByte(); b != 0 {
+		t.Fatal("unexpected context free actions")
+	}
+	count, _ := r.ReadByte()
+	data := make([][]byte, count)
+	for i := range data {
+		_, _ = r.Seek(16, io.SeekCurrent)
+		auths, _ := r.ReadByte()
+		_, _ = r.Seek(int64(auths)*16, io.SeekCurrent)
+		l, _ := binary.ReadUvarint(r)
+		data[i] = make([]byte, l)
+		_, _ = r.Read(data[i])
+	}
+	return data
+}
+
+func TestSendActions(t *testing.T) {
+	nodeos := newTestNodeos(nil)
+	defer nodeos.Close()
+	hd, err := NewHdFromString("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	signer, _ := hd.Signer(1)
+	pubs, _ := signer.PublicKeys(context.Background())
+	result, err := sendActions(context.Background(), nodeos.api(), signer, pubs[0],
+		newAction("fio.address", "regaddress", "aftyershcu22", []byte{1, 2, 3}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result.TransactionID != "d1e5c1b5" {
+		t.Error("unexpected transaction id", result.TransactionID)
+	}
+	data := nodeos.checkPushed(t, 0, pubs[0])
+	if len(data) != 1 || !bytes.Equal(data[0], []byte{1, 2, 3}) {
+		t.Error("unexpected action data", data)
+	}
+	if _, err = sendActions(context.Background(), nodeos.api(), signer, pubs[0]); err == nil {
+		t.Error("expected an error without actions")
+	}
+}
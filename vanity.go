@@ -0,0 +1,138 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"github.com/fioprotocol/fio-go"
+	"github.com/fioprotocol/fio-go/eos"
+	"github.com/fioprotocol/fio-go/eos/ecc"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ErrVanityNotFound is returned when no index up to VanityOptions.Max matched
+var ErrVanityNotFound = errors.New("no matching key was found")
+
+// VanityMatch decides if a derived key is acceptable
+type VanityMatch func(actor eos.AccountName, pub *ecc.PublicKey) bool
+
+// VanityOptions controls VanitySearch, only Match is required
+type VanityOptions struct {
+	Match   VanityMatch
+	Start   int // first index to check
+	Max     int // last index to check, defaults to the highest non-hardened index
+	Workers int // defaults to the number of CPUs
+
+	// Progress, if set, is called with the number of keys checked every ProgressInterval (default 1000) keys.
+	// Calls are not concurrent.
+	Progress         func(checked int)
+	ProgressInterval int
+}
+
+// VanityResult is a key found by VanitySearch
+type VanityResult struct {
+	Index  int
+	Actor  eos.AccountName
+	PubKey *ecc.PublicKey
+}
+
+// ActorMatch provides a VanityMatch for actor names matching a regular expression, for example "^fio"
+func ActorMatch(re *regexp.Regexp) VanityMatch {
+	return func(actor eos.AccountName, _ *ecc.PublicKey) bool {
+		return re.MatchString(string(actor))
+	}
+}
+
+// KeySuffixMatch provides a VanityMatch for public keys ending in suffix, base58 is case sensitive
+func KeySuffixMatch(suffix string) VanityMatch {
+	return func(_ eos.AccountName, pub *ecc.PublicKey) bool {
+		return strings.HasSuffix(pub.String(), suffix)
+	}
+}
+
+// VanitySearch scans derivation indexes in parallel for the lowest index with a key accepted by opts.Match. It stops
+// when ctx is cancelled, returning the context's error.
+func (hd Hd) VanitySearch(ctx context.Context, opts VanityOptions) (*VanityResult, error) {
+	if opts.Match == nil {
+		return nil, errors.New("a match function is required")
+	}
+	if err := checkIndex(opts.Start); err != nil {
+		return nil, err
+	}
+	if opts.Max <= 0 || opts.Max > 1<<31-1 {
+		opts.Max = 1<<31 - 1
+	}
+	if opts.Workers < 1 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.ProgressInterval < 1 {
+		opts.ProgressInterval = 1000
+	}
+	if _, err := hd.pubKeyAt(opts.Start); err != nil {
+		return nil, err
+	}
+
+	var (
+		mux     sync.Mutex
+		next    = opts.Start
+		checked int
+		found   *VanityResult
+		failed  error
+	)
+	// take hands out indexes in order, and stops once an index would be higher than a match
+	take := func() (int, bool) {
+		mux.Lock()
+		defer mux.Unlock()
+		if failed != nil || next > opts.Max || (found != nil && next > found.Index) || ctx.Err() != nil {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(opts.Workers)
+	for w := 0; w < opts.Workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i, ok := take()
+				if !ok {
+					return
+				}
+				pub, err := hd.pubKeyAt(i)
+				var actor eos.AccountName
+				if err == nil {
+					actor, err = fio.ActorFromPub(pub.String())
+				}
+				matched := err == nil && opts.Match(actor, pub)
+
+				mux.Lock()
+				switch {
+				case err != nil:
+					failed = err
+				case matched && (found == nil || i < found.Index):
+					found = &VanityResult{Index: i, Actor: actor, PubKey: pub}
+				}
+				checked++
+				if opts.Progress != nil && checked%opts.ProgressInterval == 0 {
+					opts.Progress(checked)
+				}
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	switch {
+	case failed != nil:
+		return nil, failed
+	case found != nil:
+		return found, nil
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	}
+	return nil, ErrVanityNotFound
+}
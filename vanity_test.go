@@ -0,0 +1,43 @@
+package fiox
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHd_VanitySearch(t *testing.T) {
+	hd, err := NewHdFromString("earth dust patient fashion begin behave two brisk solar fetch flash impulse paper around endless")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	progress := 0
+	result, err := hd.VanitySearch(context.Background(), VanityOptions{
+		Match:            KeySuffixMatch("49xjbdDWew"),
+		Max:              40,
+		Workers:          4,
+		Progress:         func(checked int) { progress = checked },
+		ProgressInterval: 5,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if result.Index != 17 || result.PubKey.String() != "FIO79wTtYceEozALgxmxQBieRRiK2AiiHL66ssEcNKF49xjbdDWew" {
+		t.Error("unexpected vanity result", result.Index)
+	}
+	if progress < 15 {
+		t.Error("progress was not reported")
+	}
+
+	_, err = hd.VanitySearch(context.Background(), VanityOptions{Match: KeySuffixMatch("49xjbdDWew"), Max: 10})
+	if !errors.Is(err, ErrVanityNotFound) {
+		t.Error("expected ErrVanityNotFound, got", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = hd.VanitySearch(ctx, VanityOptions{Match: KeySuffixMatch("nope")}); !errors.Is(err, context.Canceled) {
+		t.Error("expected context.Canceled, got", err)
+	}
+}